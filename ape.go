@@ -0,0 +1,313 @@
+package tag
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotAPE is an error which is returned when no APEv2 tag is found.
+var ErrNotAPE = errors.New("invalid APE tag footer")
+
+const (
+	apeTagPreamble    = "APETAGEX"
+	apeTagFooterSize  = 32
+	apeItemTypeBinary = 1
+)
+
+// apeTagFooter is a type which represents an APE tag header or footer (both have
+// the same layout).
+type apeTagFooter struct {
+	Version   uint32
+	Size      uint32 // size of the items and footer, excluding the header
+	ItemCount uint32
+	Flags     uint32
+}
+
+func (f apeTagFooter) hasHeader() bool { return f.Flags&(1<<31) != 0 }
+
+// readAPETagFooter reads an APE tag footer from the current position of r.
+func readAPETagFooter(r io.Reader) (*apeTagFooter, error) {
+	b, err := readBytes(r, apeTagFooterSize)
+	if err != nil {
+		return nil, err
+	}
+	if string(b[:8]) != apeTagPreamble {
+		return nil, ErrNotAPE
+	}
+
+	f := &apeTagFooter{
+		Version:   binary.LittleEndian.Uint32(b[8:12]),
+		Size:      binary.LittleEndian.Uint32(b[12:16]),
+		ItemCount: binary.LittleEndian.Uint32(b[16:20]),
+		Flags:     binary.LittleEndian.Uint32(b[20:24]),
+	}
+	if f.Size < apeTagFooterSize {
+		return nil, fmt.Errorf("%w: invalid APE tag size: %d", ErrMalformed, f.Size)
+	}
+	return f, nil
+}
+
+// findAPETag looks for an APE tag ending at end (an offset from the start of the file),
+// returning the offset of the tag (including its header, if any) and its total size.
+func findAPETag(r io.ReadSeeker, end int64) (f *apeTagFooter, offset, size int64, err error) {
+	if end < apeTagFooterSize {
+		return nil, 0, 0, ErrNotAPE
+	}
+	if _, err = r.Seek(end-apeTagFooterSize, io.SeekStart); err != nil {
+		return nil, 0, 0, err
+	}
+	f, err = readAPETagFooter(r)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	size = int64(f.Size)
+	if f.hasHeader() {
+		size += apeTagFooterSize
+	}
+	offset = end - size
+	if offset < 0 {
+		return nil, 0, 0, fmt.Errorf("%w: APE tag size %d exceeds file size", ErrMalformed, size)
+	}
+	return f, offset, size, nil
+}
+
+// ReadAPETags reads an APEv2 tag from the end of the io.ReadSeeker (before an ID3v1
+// tag, if present).  Returns ErrNotAPE if there is no APE tag, otherwise non-nil
+// error if there was a problem.
+func ReadAPETags(r io.ReadSeeker, opts ...ReadOption) (Metadata, error) {
+	end, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ReadID3v1Tags(r); err == nil {
+		end -= 128
+	}
+
+	m, _, _, err := readAPETagAt(r, end, newReadOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// readAPETagAt reads the APE tag which ends at end, returning the metadata and the
+// location of the tag.
+func readAPETagAt(r io.ReadSeeker, end int64, o *readOptions) (m *metadataAPE, offset, size int64, err error) {
+	f, offset, size, err := findAPETag(r, end)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	// Items are located between the header (if any) and the footer.
+	itemsLen := int64(f.Size) - apeTagFooterSize
+	if _, err = r.Seek(end-int64(f.Size), io.SeekStart); err != nil {
+		return nil, 0, 0, err
+	}
+	b, err := readBytes(r, uint(itemsLen))
+	if err != nil {
+		return nil, 0, 0, newParseError(string(APEv2), offset, "", err)
+	}
+
+	m = &metadataAPE{items: make(map[string]interface{})}
+	for i := uint32(0); i < f.ItemCount && len(b) > 0; i++ {
+		itemOffset := end - int64(f.Size) + itemsLen - int64(len(b))
+		b, err = m.readAPEItem(b)
+		if err != nil {
+			// The remaining items cannot be located after a bad item.
+			if err := o.warn(newParseError(string(APEv2), itemOffset, "", err)); err != nil {
+				return nil, 0, 0, err
+			}
+			break
+		}
+	}
+	return m, offset, size, nil
+}
+
+// APEv2 item
+// -- readAPEItem
+// Value size    <uint32 little endian>
+// Item flags    <uint32 little endian>
+// Key           <ASCII string> $00
+// Value         <value size bytes>
+func (m *metadataAPE) readAPEItem(b []byte) ([]byte, error) {
+	if len(b) < 8 {
+		return nil, fmt.Errorf("%w: APE item: expected at least %d bytes, got %d", ErrTruncated, 8, len(b))
+	}
+	valueLen := binary.LittleEndian.Uint32(b[0:4])
+	flags := binary.LittleEndian.Uint32(b[4:8])
+	b = b[8:]
+
+	i := bytes.IndexByte(b, 0)
+	if i < 0 {
+		return nil, fmt.Errorf("%w: APE item key is not terminated", ErrMalformed)
+	}
+	key := string(b[:i])
+	b = b[i+1:]
+
+	if uint64(valueLen) > uint64(len(b)) {
+		return nil, fmt.Errorf("%w: APE item %q: value size %d exceeds tag size", ErrTruncated, key, valueLen)
+	}
+	value := b[:valueLen]
+
+	if (flags>>1)&0x3 == apeItemTypeBinary {
+		if strings.HasPrefix(strings.ToLower(key), "cover art") {
+			m.items[strings.ToLower(key)] = readAPEPicture(key, value)
+		} else {
+			m.items[strings.ToLower(key)] = value
+		}
+	} else {
+		// Text items can hold a list of values separated by $00.
+		values := strings.Split(string(value), "\x00")
+		if len(values) > 1 {
+			if m.multi == nil {
+				m.multi = make(map[string][]string)
+			}
+			m.multi[strings.ToLower(key)] = values
+		}
+		m.items[strings.ToLower(key)] = strings.Join(values, ";")
+	}
+	return b[valueLen:], nil
+}
+
+// APEv2 binary cover art items are a file name followed by the image data.
+// -- readAPEPicture
+// File name      <UTF-8 string> $00
+// Picture data   <binary data>
+func readAPEPicture(key string, b []byte) *Picture {
+	var desc string
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		desc = string(b[:i])
+		b = b[i+1:]
+	}
+
+	p := &Picture{
+		Description: desc,
+		Data:        b,
+	}
+	switch {
+	case bytes.HasPrefix(b, pngHeader):
+		p.Ext, p.MIMEType = "png", "image/png"
+	case bytes.HasPrefix(b, []byte{0xff, 0xd8}):
+		p.Ext, p.MIMEType = "jpg", "image/jpeg"
+	}
+
+	switch strings.ToLower(key) {
+	case "cover art (front)":
+		p.Type = pictureTypes[0x03]
+	case "cover art (back)":
+		p.Type = pictureTypes[0x04]
+	default:
+		p.Type = pictureTypes[0x00]
+	}
+	return p
+}
+
+// metadataAPE is the implementation of Metadata used for APEv2 tags.
+type metadataAPE struct {
+	items    map[string]interface{} // keys are lower-cased
+	multi    map[string][]string    // values of items holding a list
+	fileType FileType
+}
+
+// rawValues returns each value of the item k, if it holds a list.
+func (m *metadataAPE) rawValues(k string) []string { return m.multi[k] }
+
+func (m *metadataAPE) getString(k string) string {
+	s, _ := m.items[k].(string)
+	return s
+}
+
+func (m *metadataAPE) Format() Format              { return APEv2 }
+func (m *metadataAPE) FileType() FileType          { return m.fileType }
+func (m *metadataAPE) Raw() map[string]interface{} { return m.items }
+
+func (m *metadataAPE) Title() string           { return m.getString("title") }
+func (m *metadataAPE) Album() string           { return m.getString("album") }
+func (m *metadataAPE) Artist() string          { return m.getString("artist") }
+func (m *metadataAPE) Composer() string        { return m.getString("composer") }
+func (m *metadataAPE) Genre() string           { return m.getString("genre") }
+func (m *metadataAPE) Lyrics() string          { return m.getString("lyrics") }
+func (m *metadataAPE) Comment() string         { return m.getString("comment") }
+func (m *metadataAPE) Duration() time.Duration { return 0 }
+func (m *metadataAPE) Audio() *Audio           { return nil }
+func (m *metadataAPE) Chapters() []Chapter     { return nil }
+
+func (m *metadataAPE) SyncedLyrics() []LyricLine { return parseLRC(m.getString("lyrics")) }
+func (m *metadataAPE) ReplayGain() *ReplayGain   { return replayGainFromFields(m.getString) }
+func (m *metadataAPE) Ratings() []Rating         { return ratingsFromFields(m.getString) }
+func (m *metadataAPE) PlayCount() int            { return playCountFromFields(m.getString) }
+
+// Get returns the first non-empty item given for the field.
+func (m *metadataAPE) Get(f Field) string { return getField(APEv2, m.items, f) }
+
+func (m *metadataAPE) TitleSort() string       { return m.Get(FieldTitleSort) }
+func (m *metadataAPE) AlbumSort() string       { return m.Get(FieldAlbumSort) }
+func (m *metadataAPE) ArtistSort() string      { return m.Get(FieldArtistSort) }
+func (m *metadataAPE) AlbumArtistSort() string { return m.Get(FieldAlbumArtistSort) }
+func (m *metadataAPE) BPM() int                { return parseBPM(m.Get(FieldBPM)) }
+func (m *metadataAPE) Key() string             { return m.Get(FieldKey) }
+func (m *metadataAPE) ISRC() string            { return m.Get(FieldISRC) }
+func (m *metadataAPE) Label() string           { return m.Get(FieldLabel) }
+func (m *metadataAPE) CatalogNumber() string   { return m.Get(FieldCatalogNumber) }
+func (m *metadataAPE) Copyright() string       { return m.Get(FieldCopyright) }
+func (m *metadataAPE) Encoder() string         { return m.Get(FieldEncoder) }
+func (m *metadataAPE) EncodedBy() string       { return m.Get(FieldEncodedBy) }
+func (m *metadataAPE) Grouping() string        { return m.Get(FieldGrouping) }
+func (m *metadataAPE) Conductor() string       { return m.Get(FieldConductor) }
+func (m *metadataAPE) Lyricist() string        { return m.Get(FieldLyricist) }
+func (m *metadataAPE) Mood() string            { return m.Get(FieldMood) }
+func (m *metadataAPE) Compilation() bool       { return parseBool(m.Get(FieldCompilation)) }
+
+func (m *metadataAPE) AlbumArtist() string {
+	if s := m.getString("album artist"); s != "" {
+		return s
+	}
+	return m.getString("albumartist")
+}
+
+func (m *metadataAPE) Year() int {
+	y := m.getString("year")
+	if len(y) >= 4 {
+		n, _ := strconv.Atoi(y[:4])
+		return n
+	}
+	return 0
+}
+
+func (m *metadataAPE) Date() Date {
+	return parseDate(m.getString("year"))
+}
+
+func (m *metadataAPE) OriginalDate() Date {
+	if d := parseDate(m.getString("originaldate")); !d.IsZero() {
+		return d
+	}
+	return parseDate(m.getString("originalyear"))
+}
+
+func (m *metadataAPE) Track() (int, int) {
+	return parseXofN(m.getString("track"))
+}
+
+func (m *metadataAPE) Disc() (int, int) {
+	return parseXofN(m.getString("disc"))
+}
+
+func (m *metadataAPE) Picture() *Picture {
+	if p, ok := m.items["cover art (front)"].(*Picture); ok {
+		return p
+	}
+	for k, v := range m.items {
+		if p, ok := v.(*Picture); ok && strings.HasPrefix(k, "cover art") {
+			return p
+		}
+	}
+	return nil
+}
@@ -0,0 +1,316 @@
+package tag
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Codec is an enumeration of the audio codecs detected by this package.
+type Codec string
+
+// Detected codecs.
+const (
+	UnknownCodec Codec = ""         // Unknown codec.
+	CodecAAC     Codec = "AAC"      // AAC with a profile other than LC or HE (e.g. Main).
+	CodecAACLC   Codec = "AAC-LC"   // AAC Low Complexity.
+	CodecHEAAC   Codec = "HE-AAC"   // High Efficiency AAC (AAC with SBR).
+	CodecHEAACv2 Codec = "HE-AACv2" // High Efficiency AAC v2 (AAC with SBR and PS).
+	CodecALAC    Codec = "ALAC"     // Apple Lossless.
+	CodecAC3     Codec = "AC-3"     // Dolby Digital.
+	CodecEAC3    Codec = "E-AC-3"   // Dolby Digital Plus.
+	CodecOpus    Codec = "Opus"     // Opus.
+	CodecVorbis  Codec = "Vorbis"   // Vorbis.
+	CodecFLAC    Codec = "FLAC"     // FLAC.
+	CodecMP1     Codec = "MP1"      // MPEG audio layer I.
+	CodecMP2     Codec = "MP2"      // MPEG audio layer II.
+	CodecMP3     Codec = "MP3"      // MPEG audio layer III.
+	CodecPCM     Codec = "PCM"      // Uncompressed PCM.
+	CodecDSD     Codec = "DSD"      // Direct Stream Digital.
+)
+
+// Audio describes the audio stream of a file.  Fields are zero if unknown.
+type Audio struct {
+	Codec      Codec
+	SampleRate int // Samples per second (per channel).
+	Channels   int
+	BitDepth   int   // Bits per sample of lossless and uncompressed codecs.
+	Samples    int64 // Total number of samples (per channel).
+}
+
+// samplesDuration returns the duration of n samples at the given rate (samples per second),
+// or 0 if the rate is 0.  Durations too long to represent are capped.
+func samplesDuration(n uint64, rate uint32) time.Duration {
+	if rate == 0 {
+		return 0
+	}
+	s, rem := n/uint64(rate), n%uint64(rate)
+	if s >= math.MaxInt64/uint64(time.Second) {
+		return math.MaxInt64
+	}
+	// rem is less than 2^32, so rem * time.Second cannot overflow.
+	return time.Duration(s)*time.Second + time.Duration(rem*uint64(time.Second)/uint64(rate))
+}
+
+// aacSampleRates are the sample rates given by MPEG-4 sampling frequency indices.
+var aacSampleRates = [...]int{96000, 88200, 64000, 48000, 44100, 32000, 24000, 22050, 16000, 12000, 11025, 8000, 7350}
+
+// readMP4SampleEntry reads the body of an audio sample entry from an stsd atom.
+func readMP4SampleEntry(name string, b []byte) (*Audio, error) {
+	a := &Audio{}
+	switch name {
+	case "mp4a":
+		a.Codec = CodecAAC
+	case "alac":
+		a.Codec = CodecALAC
+	case "ac-3":
+		a.Codec = CodecAC3
+	case "ec-3":
+		a.Codec = CodecEAC3
+	case "Opus":
+		a.Codec = CodecOpus
+	case "fLaC":
+		a.Codec = CodecFLAC
+	case ".mp3":
+		a.Codec = CodecMP3
+	case "lpcm", "sowt", "twos", "in24", "in32", "fl32", "fl64", "ipcm", "fpcm":
+		a.Codec = CodecPCM
+	}
+
+	// reserved (6 bytes), data reference index (2 bytes), version (2 bytes), revision level
+	// (2 bytes), vendor (4 bytes), channel count (2 bytes), sample size (2 bytes), compression
+	// ID (2 bytes), packet size (2 bytes), sample rate (16.16 fixed point)
+	if len(b) < 28 {
+		return nil, fmt.Errorf("%w: stsd", ErrTruncated)
+	}
+	a.Channels = int(binary.BigEndian.Uint16(b[16:]))
+	a.SampleRate = int(binary.BigEndian.Uint32(b[24:]) >> 16)
+	if a.Codec == CodecPCM {
+		a.BitDepth = int(binary.BigEndian.Uint16(b[18:]))
+	}
+
+	// QuickTime sound sample descriptions version 1 and 2 have extra fields.
+	n := 28
+	switch binary.BigEndian.Uint16(b[8:]) {
+	case 1:
+		n += 16
+	case 2:
+		n += 36
+	}
+	if n > len(b) {
+		return nil, fmt.Errorf("%w: stsd", ErrTruncated)
+	}
+
+	err := readMP4Boxes(b[n:], func(name string, b []byte) error {
+		switch name {
+		case "esds":
+			return readESDS(a, b)
+
+		case "alac":
+			// version and flags (4 bytes), frame length (4 bytes), compatible version (1 byte),
+			// bit depth (1 byte), rice parameters (3 bytes), channels (1 byte), max run
+			// (2 bytes), max frame bytes (4 bytes), average bit rate (4 bytes), sample rate
+			// (4 bytes)
+			if len(b) < 28 {
+				return fmt.Errorf("%w: %v", ErrTruncated, name)
+			}
+			a.BitDepth = int(b[9])
+			a.Channels = int(b[13])
+			a.SampleRate = int(binary.BigEndian.Uint32(b[24:]))
+
+		case "dOps":
+			// version (1 byte), output channel count (1 byte), pre-skip (2 bytes), input
+			// sample rate (4 bytes)
+			if len(b) < 8 {
+				return fmt.Errorf("%w: %v", ErrTruncated, name)
+			}
+			a.Channels = int(b[1])
+
+		case "dfLa":
+			// version and flags (4 bytes), metadata block header (4 bytes), STREAMINFO
+			if len(b) < 8+18 || b[4]&0x7f != byte(streamInfoBlock) {
+				return fmt.Errorf("%w: %v", ErrTruncated, name)
+			}
+			info := b[8:]
+			sampleRate, _ := cutBits(info, 80, 20)
+			channels, _ := cutBits(info, 100, 3)
+			bitDepth, _ := cutBits(info, 103, 5)
+			a.SampleRate = int(sampleRate)
+			a.Channels = int(channels) + 1
+			a.BitDepth = int(bitDepth) + 1
+		}
+		return nil
+	})
+	return a, err
+}
+
+// readESDS reads the codec from the body of an esds atom (an MPEG-4 elementary stream
+// descriptor).
+func readESDS(a *Audio, b []byte) error {
+	if len(b) < 4 {
+		return fmt.Errorf("%w: esds", ErrTruncated)
+	}
+	b = b[4:] // version and flags
+
+	// readDescriptor returns the tag and body of the descriptor at the start of b.
+	readDescriptor := func(b []byte) (tag byte, body []byte, err error) {
+		if len(b) < 2 {
+			return 0, nil, fmt.Errorf("%w: esds", ErrTruncated)
+		}
+		// The size is 1 to 4 bytes of 7 bits, the top bit being set if more follow.
+		tag = b[0]
+		size, i := 0, 1
+		for more := true; more; i++ {
+			if i >= len(b) || i > 4 {
+				return 0, nil, fmt.Errorf("%w: esds descriptor size", ErrMalformed)
+			}
+			size = size<<7 | int(b[i]&0x7f)
+			more = b[i]&0x80 != 0
+		}
+		if i+size > len(b) {
+			return 0, nil, fmt.Errorf("%w: esds", ErrTruncated)
+		}
+		return tag, b[i : i+size], nil
+	}
+
+	tag, es, err := readDescriptor(b)
+	if err != nil {
+		return err
+	}
+	if tag != 0x03 || len(es) < 3 {
+		return nil
+	}
+	// ES ID (2 bytes), flags (1 byte) followed by optional fields
+	flags := es[2]
+	es = es[3:]
+	if flags&0x80 != 0 {
+		es = es[min(2, len(es)):] // depends on ES ID
+	}
+	if flags&0x40 != 0 && len(es) > 0 {
+		es = es[min(1+int(es[0]), len(es)):] // URL
+	}
+	if flags&0x20 != 0 {
+		es = es[min(2, len(es)):] // OCR ES ID
+	}
+
+	tag, dc, err := readDescriptor(es)
+	if err != nil {
+		return err
+	}
+	if tag != 0x04 || len(dc) < 13 {
+		return nil
+	}
+	// object type (1 byte), stream type (1 byte), buffer size (3 bytes), max bit rate
+	// (4 bytes), average bit rate (4 bytes)
+	switch dc[0] {
+	case 0x40: // MPEG-4 audio
+	case 0x66, 0x68: // MPEG-2 AAC Main, SSR
+		a.Codec = CodecAAC
+		return nil
+	case 0x67: // MPEG-2 AAC LC
+		a.Codec = CodecAACLC
+		return nil
+	case 0x69, 0x6b: // MPEG-2 and MPEG-1 audio
+		a.Codec = CodecMP3
+		return nil
+	case 0xa5:
+		a.Codec = CodecAC3
+		return nil
+	case 0xa6:
+		a.Codec = CodecEAC3
+		return nil
+	default:
+		return nil
+	}
+
+	tag, asc, err := readDescriptor(dc[13:])
+	if err != nil || tag != 0x05 {
+		return nil
+	}
+	readAudioSpecificConfig(a, asc)
+	return nil
+}
+
+// readAudioSpecificConfig reads the codec, sample rate and channels from an MPEG-4
+// AudioSpecificConfig.  Both explicit and backward compatible signalling of SBR and PS
+// (HE-AAC) are detected.
+func readAudioSpecificConfig(a *Audio, b []byte) {
+	var offset uint
+	bits := func(n uint) int {
+		x, err := cutBits(b, offset, n)
+		offset += n
+		if err != nil {
+			return -1
+		}
+		return int(x)
+	}
+	objectType := func() int {
+		t := bits(5)
+		if t == 31 {
+			t = 32 + bits(6)
+		}
+		return t
+	}
+	sampleRate := func() int {
+		i := bits(4)
+		if i == 15 {
+			return bits(24)
+		}
+		if i >= 0 && i < len(aacSampleRates) {
+			return aacSampleRates[i]
+		}
+		return 0
+	}
+
+	t := objectType()
+	rate := sampleRate()
+	channels := bits(4)
+	sbr, ps := false, false
+	if t == 5 || t == 29 {
+		// Explicit signalling: the extension sample rate and the underlying object type.
+		sbr, ps = true, t == 29
+		rate = sampleRate()
+		t = objectType()
+	}
+
+	if t == 2 && !sbr {
+		// GASpecificConfig: frame length flag, depends on core coder (and core coder delay),
+		// extension flag.  Then look for the backward compatible SBR and PS extensions.
+		bits(1)
+		if bits(1) == 1 {
+			bits(14)
+		}
+		bits(1)
+		if bits(11) == 0x2b7 && objectType() == 5 && bits(1) == 1 {
+			sbr = true
+			rate = sampleRate()
+			if bits(11) == 0x548 && bits(1) == 1 {
+				ps = true
+			}
+		}
+	}
+
+	switch {
+	case ps:
+		a.Codec = CodecHEAACv2
+	case sbr:
+		a.Codec = CodecHEAAC
+	case t == 2:
+		a.Codec = CodecAACLC
+	case t == 34:
+		a.Codec = CodecMP3
+	}
+	if rate > 0 {
+		a.SampleRate = rate
+	}
+	if channels > 0 && channels < 8 {
+		a.Channels = channels
+		if channels == 7 {
+			a.Channels = 8
+		}
+		if ps && channels == 1 {
+			a.Channels = 2 // PS decodes mono to stereo
+		}
+	}
+}
@@ -0,0 +1,467 @@
+package tag
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// TagBlock is a single block of tag metadata found in a file.
+type TagBlock struct {
+	Format Format // Format of the tag block.
+	Offset int64  // Offset of the block from the start of the file, in bytes.
+	Size   int64  // Size of the block, in bytes.
+
+	// Metadata is the metadata parsed from the block.  For formats which embed
+	// tags in the container (FLAC, OGG, MP4, DSF, WAV) the block spans the whole
+	// container.
+	Metadata Metadata
+}
+
+// ReadAll reads every tag block present in the io.ReadSeeker: a leading ID3v2 tag,
+// the tags of a FLAC, OGG, MP4, DSF or WAV container, and trailing APEv2 and ID3v1
+// tags.  Blocks are returned in the order in which they appear in the file.
+// Returns ErrNoTagsFound if no tag blocks were found.
+func ReadAll(r io.ReadSeeker, opts ...ReadOption) ([]TagBlock, error) {
+	o := newReadOptions(opts)
+
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, fmt.Errorf("could not get file size: %w", err)
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	var blocks []TagBlock
+	var audioStart int64
+	var v2 *metadataV2MP3
+	var v1 *metadataV1MP3
+	if b, err := readBytes(r, 3); err == nil && string(b) == "ID3" {
+		if _, err := r.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		id3, err := readID3v2Tags(r, o)
+		if err != nil {
+			return nil, fmt.Errorf("reading id3v2 tags: %w", err)
+		}
+		audioStart = id3v2TagSize(id3.header)
+		v2 = &metadataV2MP3{metadataID3v2: id3}
+		blocks = append(blocks, TagBlock{
+			Format:   id3.Format(),
+			Offset:   0,
+			Size:     audioStart,
+			Metadata: v2,
+		})
+	}
+
+	// Trailing tags are read backwards from the end of the file.
+	end := size
+	if end-audioStart >= 128 {
+		if m, err := ReadID3v1Tags(r, opts...); err == nil {
+			v1 = &metadataV1MP3{metadataID3v1: &m}
+			blocks = append(blocks, TagBlock{
+				Format:   ID3v1,
+				Offset:   end - 128,
+				Size:     128,
+				Metadata: v1,
+			})
+			end -= 128
+		}
+	}
+	// APEv2 tags and ID3v2 tags with a footer may be appended in any order.
+	for {
+		if m, offset, n, err := readAPETagAt(r, end, o); err == nil && offset >= audioStart {
+			blocks = append(blocks, TagBlock{
+				Format:   APEv2,
+				Offset:   offset,
+				Size:     n,
+				Metadata: m,
+			})
+			end = offset
+			continue
+		}
+		if offset, err := findID3v2Footer(r, end); err == nil && offset >= audioStart {
+			id3, err := readID3v2TagsAt(r, offset, o)
+			if err != nil {
+				return nil, fmt.Errorf("reading id3v2 tags: %w", err)
+			}
+			if id3 != nil {
+				blocks = append(blocks, TagBlock{
+					Format:   id3.Format(),
+					Offset:   offset,
+					Size:     end - offset,
+					Metadata: &metadataV2MP3{metadataID3v2: id3},
+				})
+				end = offset
+				continue
+			}
+		}
+		break
+	}
+
+	// A SEEK frame in the leading tag points to a further ID3v2 tag.
+	if v2 != nil {
+		if offset, ok := v2.seekOffset(); ok && offset < end && !hasBlockAt(blocks, offset) {
+			id3, err := readID3v2TagsAt(r, offset, o)
+			if err != nil {
+				return nil, fmt.Errorf("reading id3v2 tags: %w", err)
+			}
+			if id3 != nil {
+				blocks = append(blocks, TagBlock{
+					Format:   id3.Format(),
+					Offset:   offset,
+					Size:     id3v2TagSize(id3.header),
+					Metadata: &metadataV2MP3{metadataID3v2: id3},
+				})
+			}
+		}
+	}
+
+	fileType := UnknownFileType
+	if audioStart < end {
+		if _, err := r.Seek(audioStart, io.SeekStart); err != nil {
+			return nil, err
+		}
+		m, err := readContainer(r, opts)
+		if err != nil {
+			return nil, err
+		}
+		if m != nil {
+			blocks = append(blocks, TagBlock{
+				Format:   m.Format(),
+				Offset:   audioStart,
+				Size:     end - audioStart,
+				Metadata: m,
+			})
+			fileType = m.FileType()
+		} else if header, err := readBytes(r, 4); err == nil && header[0] == 0xff && header[1]&0xe0 == 0xe0 {
+			fileType = MP3
+			// The MP3 duration is shared by the ID3 tags of the file.
+			lame := readLAMEReplayGain(r, audioStart)
+			audio := getMP3Audio(header)
+			for _, b := range blocks {
+				switch m := b.Metadata.(type) {
+				case *metadataV2MP3:
+					m.lame, m.audio = lame, audio
+				case *metadataV1MP3:
+					m.lame, m.audio = lame, audio
+				}
+			}
+			if d, err := getMP3Duration(header, end-audioStart); err == nil {
+				for _, b := range blocks {
+					switch m := b.Metadata.(type) {
+					case *metadataV2MP3:
+						m.duration = d
+						m.frameDuration = getMP3FrameDuration(header)
+					case *metadataV1MP3:
+						m.duration = d
+					}
+				}
+			}
+		}
+	}
+
+	if len(blocks) == 0 {
+		return nil, ErrNoTagsFound
+	}
+	for _, b := range blocks {
+		if m, ok := b.Metadata.(*metadataAPE); ok {
+			m.fileType = fileType
+		}
+	}
+
+	sort.SliceStable(blocks, func(i, j int) bool {
+		return blocks[i].Offset < blocks[j].Offset
+	})
+	return blocks, nil
+}
+
+func hasBlockAt(blocks []TagBlock, offset int64) bool {
+	for _, b := range blocks {
+		if b.Offset == offset {
+			return true
+		}
+	}
+	return false
+}
+
+// readContainer reads the metadata of a FLAC, OGG, MP4, DSF or WAV container starting at
+// the current position of r.  Returns nil Metadata if r is not positioned at a supported
+// container.
+func readContainer(r io.ReadSeeker, opts []ReadOption) (Metadata, error) {
+	b, err := readBytes(r, 11)
+	if err != nil {
+		return nil, nil
+	}
+	if _, err = r.Seek(-11, io.SeekCurrent); err != nil {
+		return nil, fmt.Errorf("could not seek back to original position: %v", err)
+	}
+
+	switch {
+	case string(b[0:4]) == "fLaC":
+		return ReadFLACMeta(r, opts...)
+
+	case string(b[0:4]) == "OggS":
+		return ReadOGGMeta(r, opts...)
+
+	case string(b[4:8]) == "ftyp":
+		return ReadAtoms(r, opts...)
+
+	case string(b[0:4]) == "DSD ":
+		return ReadDSFMeta(r, opts...)
+
+	case string(b[0:4]) == "RIFF":
+		return ReadWAVMeta(r, opts...)
+	}
+	return nil, nil
+}
+
+// id3v2TagSize returns the total size in bytes of the ID3v2 tag described by h,
+// including the header and footer.
+func id3v2TagSize(h *id3v2Header) int64 {
+	n := int64(h.Size) + 10
+	if h.FooterPresent {
+		n += 10
+	}
+	return n
+}
+
+// DefaultPrecedence is the order of formats used by Merge when no precedence is given.
+var DefaultPrecedence = []Format{ID3v2_4, ID3v2_3, ID3v2_2, VORBIS, MP4, APEv2, ID3v1}
+
+// Merge returns a Metadata which combines the given tag blocks.  Each value is taken from
+// the first block which has it set, where blocks are ordered by the position of their format
+// in precedence (blocks with formats not listed come last, in their original order).
+// If no precedence is given, DefaultPrecedence is used.
+func Merge(blocks []TagBlock, precedence ...Format) Metadata {
+	if len(precedence) == 0 {
+		precedence = DefaultPrecedence
+	}
+	rank := func(f Format) int {
+		for i, p := range precedence {
+			if p == f {
+				return i
+			}
+		}
+		return len(precedence)
+	}
+
+	sorted := make([]TagBlock, len(blocks))
+	copy(sorted, blocks)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return rank(sorted[i].Format) < rank(sorted[j].Format)
+	})
+
+	m := make(metadataMerged, 0, len(sorted))
+	for _, b := range sorted {
+		if b.Metadata != nil {
+			m = append(m, b.Metadata)
+		}
+	}
+	return m
+}
+
+// metadataMerged is the implementation of Metadata which combines several tag blocks,
+// ordered by precedence.
+type metadataMerged []Metadata
+
+func (m metadataMerged) getString(f func(Metadata) string) string {
+	for _, x := range m {
+		if s := f(x); s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+func (m metadataMerged) getXofN(f func(Metadata) (int, int)) (x, n int) {
+	for _, b := range m {
+		bx, bn := f(b)
+		if x == 0 {
+			x = bx
+		}
+		if n == 0 {
+			n = bn
+		}
+	}
+	return x, n
+}
+
+func (m metadataMerged) Format() Format {
+	if len(m) == 0 {
+		return UnknownFormat
+	}
+	return m[0].Format()
+}
+
+func (m metadataMerged) FileType() FileType {
+	for _, x := range m {
+		if t := x.FileType(); t != UnknownFileType {
+			return t
+		}
+	}
+	return UnknownFileType
+}
+
+func (m metadataMerged) Title() string           { return m.getString(Metadata.Title) }
+func (m metadataMerged) Album() string           { return m.getString(Metadata.Album) }
+func (m metadataMerged) Artist() string          { return m.getString(Metadata.Artist) }
+func (m metadataMerged) AlbumArtist() string     { return m.getString(Metadata.AlbumArtist) }
+func (m metadataMerged) Composer() string        { return m.getString(Metadata.Composer) }
+func (m metadataMerged) Genre() string           { return m.getString(Metadata.Genre) }
+func (m metadataMerged) Lyrics() string          { return m.getString(Metadata.Lyrics) }
+func (m metadataMerged) Comment() string         { return m.getString(Metadata.Comment) }
+func (m metadataMerged) TitleSort() string       { return m.getString(Metadata.TitleSort) }
+func (m metadataMerged) AlbumSort() string       { return m.getString(Metadata.AlbumSort) }
+func (m metadataMerged) ArtistSort() string      { return m.getString(Metadata.ArtistSort) }
+func (m metadataMerged) AlbumArtistSort() string { return m.getString(Metadata.AlbumArtistSort) }
+func (m metadataMerged) Key() string             { return m.getString(Metadata.Key) }
+func (m metadataMerged) ISRC() string            { return m.getString(Metadata.ISRC) }
+func (m metadataMerged) Label() string           { return m.getString(Metadata.Label) }
+func (m metadataMerged) CatalogNumber() string   { return m.getString(Metadata.CatalogNumber) }
+func (m metadataMerged) Copyright() string       { return m.getString(Metadata.Copyright) }
+func (m metadataMerged) Encoder() string         { return m.getString(Metadata.Encoder) }
+func (m metadataMerged) EncodedBy() string       { return m.getString(Metadata.EncodedBy) }
+func (m metadataMerged) Grouping() string        { return m.getString(Metadata.Grouping) }
+func (m metadataMerged) Conductor() string       { return m.getString(Metadata.Conductor) }
+func (m metadataMerged) Lyricist() string        { return m.getString(Metadata.Lyricist) }
+func (m metadataMerged) Mood() string            { return m.getString(Metadata.Mood) }
+
+func (m metadataMerged) BPM() int {
+	for _, x := range m {
+		if n := x.BPM(); n != 0 {
+			return n
+		}
+	}
+	return 0
+}
+
+// Get returns the field from the first block in which it is set.
+func (m metadataMerged) Get(f Field) string {
+	return m.getString(func(x Metadata) string { return x.Get(f) })
+}
+
+func (m metadataMerged) Compilation() bool {
+	for _, x := range m {
+		if x.Compilation() {
+			return true
+		}
+	}
+	return false
+}
+
+func (m metadataMerged) Track() (int, int) { return m.getXofN(Metadata.Track) }
+func (m metadataMerged) Disc() (int, int)  { return m.getXofN(Metadata.Disc) }
+
+func (m metadataMerged) Year() int {
+	for _, x := range m {
+		if y := x.Year(); y != 0 {
+			return y
+		}
+	}
+	return 0
+}
+
+func (m metadataMerged) Date() Date {
+	for _, x := range m {
+		if d := x.Date(); !d.IsZero() {
+			return d
+		}
+	}
+	return Date{}
+}
+
+func (m metadataMerged) OriginalDate() Date {
+	for _, x := range m {
+		if d := x.OriginalDate(); !d.IsZero() {
+			return d
+		}
+	}
+	return Date{}
+}
+
+func (m metadataMerged) Picture() *Picture {
+	for _, x := range m {
+		if p := x.Picture(); p != nil {
+			return p
+		}
+	}
+	return nil
+}
+
+func (m metadataMerged) Chapters() []Chapter {
+	for _, x := range m {
+		if c := x.Chapters(); len(c) > 0 {
+			return c
+		}
+	}
+	return nil
+}
+
+func (m metadataMerged) SyncedLyrics() []LyricLine {
+	for _, x := range m {
+		if l := x.SyncedLyrics(); len(l) > 0 {
+			return l
+		}
+	}
+	return nil
+}
+
+func (m metadataMerged) ReplayGain() *ReplayGain {
+	for _, x := range m {
+		if g := x.ReplayGain(); g != nil {
+			return g
+		}
+	}
+	return nil
+}
+
+func (m metadataMerged) Ratings() []Rating {
+	for _, x := range m {
+		if r := x.Ratings(); len(r) > 0 {
+			return r
+		}
+	}
+	return nil
+}
+
+func (m metadataMerged) PlayCount() int {
+	for _, x := range m {
+		if n := x.PlayCount(); n != 0 {
+			return n
+		}
+	}
+	return 0
+}
+
+// Raw returns the union of the raw tags of all blocks.  Where several blocks use the same
+// tag name the value from the block with the highest precedence is used.
+func (m metadataMerged) Raw() map[string]interface{} {
+	raw := make(map[string]interface{})
+	for i := len(m) - 1; i >= 0; i-- {
+		for k, v := range m[i].Raw() {
+			raw[k] = v
+		}
+	}
+	return raw
+}
+
+func (m metadataMerged) Audio() *Audio {
+	for _, x := range m {
+		if a := x.Audio(); a != nil {
+			return a
+		}
+	}
+	return nil
+}
+
+func (m metadataMerged) Duration() time.Duration {
+	for _, x := range m {
+		if d := x.Duration(); d != 0 {
+			return d
+		}
+	}
+	return 0
+}
@@ -0,0 +1,123 @@
+package tag
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"testing"
+)
+
+// id3v23Tag builds an ID3v2.3 tag holding the given ISO-8859-1 text frames.
+func id3v23Tag(frames map[string]string) []byte {
+	var body bytes.Buffer
+	for name, text := range frames {
+		body.WriteString(name)
+		binary.Write(&body, binary.BigEndian, uint32(len(text)+1))
+		body.Write([]byte{0, 0, 0})
+		body.WriteString(text)
+	}
+
+	n := body.Len()
+	b := []byte{'I', 'D', '3', 3, 0, 0, byte(n >> 21 & 0x7f), byte(n >> 14 & 0x7f), byte(n >> 7 & 0x7f), byte(n & 0x7f)}
+	return append(b, body.Bytes()...)
+}
+
+// apeTag builds an APEv2 tag (with header and footer) holding the given text items.
+func apeTag(items [][2]string) []byte {
+	var body bytes.Buffer
+	for _, kv := range items {
+		binary.Write(&body, binary.LittleEndian, uint32(len(kv[1])))
+		binary.Write(&body, binary.LittleEndian, uint32(0))
+		body.WriteString(kv[0])
+		body.WriteByte(0)
+		body.WriteString(kv[1])
+	}
+
+	footer := func(flags uint32) []byte {
+		var b bytes.Buffer
+		b.WriteString(apeTagPreamble)
+		binary.Write(&b, binary.LittleEndian, uint32(2000))
+		binary.Write(&b, binary.LittleEndian, uint32(body.Len()+apeTagFooterSize))
+		binary.Write(&b, binary.LittleEndian, uint32(len(items)))
+		binary.Write(&b, binary.LittleEndian, flags)
+		b.Write(make([]byte, 8))
+		return b.Bytes()
+	}
+
+	var b bytes.Buffer
+	b.Write(footer(1<<31 | 1<<29))
+	b.Write(body.Bytes())
+	b.Write(footer(1 << 31))
+	return b.Bytes()
+}
+
+// id3v1Tag builds an ID3v1.1 tag with the given title and track number.
+func id3v1Tag(title string, track byte) []byte {
+	b := make([]byte, 128)
+	copy(b, "TAG")
+	copy(b[3:33], title)
+	copy(b[93:97], "1999")
+	b[126] = track
+	b[127] = 0xff
+	return b
+}
+
+func TestReadAll(t *testing.T) {
+	audio, err := os.ReadFile("testdata/without_tags/sample.mp3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v2 := id3v23Tag(map[string]string{"TIT2": "V2 Title"})
+	ape := apeTag([][2]string{{"Title", "APE Title"}, {"Album", "APE Album"}, {"Track", "4/9"}})
+	v1 := id3v1Tag("V1 Title", 4)
+
+	var file []byte
+	file = append(file, v2...)
+	file = append(file, audio...)
+	file = append(file, ape...)
+	file = append(file, v1...)
+
+	blocks, err := ReadAll(bytes.NewReader(file))
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+
+	want := []struct {
+		format Format
+		offset int64
+		size   int64
+		title  string
+	}{
+		{ID3v2_3, 0, int64(len(v2)), "V2 Title"},
+		{APEv2, int64(len(v2) + len(audio)), int64(len(ape)), "APE Title"},
+		{ID3v1, int64(len(file) - 128), 128, "V1 Title"},
+	}
+	if len(blocks) != len(want) {
+		t.Fatalf("ReadAll() returned %d blocks, expected %d", len(blocks), len(want))
+	}
+	for i, w := range want {
+		b := blocks[i]
+		if b.Format != w.format || b.Offset != w.offset || b.Size != w.size {
+			t.Errorf("[%d] got block {%v, %d, %d}, expected {%v, %d, %d}", i, b.Format, b.Offset, b.Size, w.format, w.offset, w.size)
+		}
+		if got := b.Metadata.Title(); got != w.title {
+			t.Errorf("[%d] Title() = %q, expected %q", i, got, w.title)
+		}
+		if got := b.Metadata.FileType(); got != MP3 {
+			t.Errorf("[%d] FileType() = %v, expected %v", i, got, MP3)
+		}
+	}
+
+	m := Merge(blocks)
+	testValue(t, "V2 Title", m.Title())
+	testValue(t, "APE Album", m.Album())
+	testValue(t, 1999, m.Year())
+	track, total := m.Track()
+	testValue(t, 4, track)
+	testValue(t, 9, total)
+
+	m = Merge(blocks, ID3v1, APEv2)
+	testValue(t, ID3v1, m.Format())
+	testValue(t, "V1 Title", m.Title())
+}
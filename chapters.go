@@ -0,0 +1,61 @@
+package tag
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Chapter is a chapter of an audio file (e.g. of a podcast or audiobook).
+type Chapter struct {
+	Start   time.Duration // Start time of the chapter.
+	End     time.Duration // End time of the chapter, or zero if unknown.
+	Title   string        // Title of the chapter, if any.
+	URL     string        // URL associated with the chapter, if any.
+	Picture *Picture      // Image associated with the chapter, if any.
+}
+
+// fillChapterEnds sorts chapters by start time and sets missing end times to the start
+// of the next chapter, or end for the last chapter.
+func fillChapterEnds(chapters []Chapter, end time.Duration) {
+	sort.SliceStable(chapters, func(i, j int) bool {
+		return chapters[i].Start < chapters[j].Start
+	})
+	for i := range chapters {
+		if chapters[i].End != 0 {
+			continue
+		}
+		if i+1 < len(chapters) {
+			chapters[i].End = chapters[i+1].Start
+		} else if end > chapters[i].Start {
+			chapters[i].End = end
+		}
+	}
+}
+
+// parseClockTime parses a time of the form [[HH:]MM:]SS[.sss].
+func parseClockTime(s string) (time.Duration, bool) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) > 3 {
+		return 0, false
+	}
+
+	var d time.Duration
+	for i, p := range parts {
+		if i < len(parts)-1 {
+			n, err := strconv.Atoi(p)
+			if err != nil || n < 0 {
+				return 0, false
+			}
+			d = (d + time.Duration(n)) * 60
+			continue
+		}
+		f, err := strconv.ParseFloat(p, 64)
+		if err != nil || f < 0 {
+			return 0, false
+		}
+		d = d*time.Second + time.Duration(f*float64(time.Second)+0.5)
+	}
+	return d, true
+}
@@ -0,0 +1,205 @@
+package tag
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// id3v23Frame builds an ID3v2.3 frame (also a valid ID3v2.4 frame if data is less than
+// 128 bytes).
+func id3v23Frame(name string, data []byte) []byte {
+	b := []byte(name)
+	b = binary.BigEndian.AppendUint32(b, uint32(len(data)))
+	b = append(b, 0, 0)
+	return append(b, data...)
+}
+
+// id3v2FramesTag builds an ID3v2 tag of the given version holding the given frames (whose
+// sizes must be less than 128 bytes for ID3v2.4).
+func id3v2FramesTag(version byte, frames ...[]byte) []byte {
+	body := bytes.Join(frames, nil)
+	n := len(body)
+	b := []byte{'I', 'D', '3', version, 0, 0, byte(n >> 21 & 0x7f), byte(n >> 14 & 0x7f), byte(n >> 7 & 0x7f), byte(n & 0x7f)}
+	return append(b, body...)
+}
+
+func TestID3v2Chapters(t *testing.T) {
+	chap := func(id string, start, end uint32, frames ...[]byte) []byte {
+		b := append([]byte(id), 0)
+		b = binary.BigEndian.AppendUint32(b, start)
+		b = binary.BigEndian.AppendUint32(b, end)
+		b = append(b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff)
+		return id3v23Frame("CHAP", append(b, bytes.Join(frames, nil)...))
+	}
+	ctoc := id3v23Frame("CTOC", []byte("toc\x00\x03\x02ch2\x00ch1\x00"))
+
+	b := id3v2FramesTag(3,
+		chap("ch1", 0, 5000, id3v23Frame("TIT2", []byte("\x00Intro"))),
+		chap("ch2", 5000, 10000, id3v23Frame("TIT2", []byte("\x00Outro")), id3v23Frame("WXXX", []byte("\x00\x00http://example.com"))),
+		ctoc,
+	)
+	m, err := ReadID3v2Tags(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("ReadID3v2Tags() = %v", err)
+	}
+
+	want := []Chapter{
+		{Start: 5 * time.Second, End: 10 * time.Second, Title: "Outro", URL: "http://example.com"},
+		{Start: 0, End: 5 * time.Second, Title: "Intro"},
+	}
+	if got := m.Chapters(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Chapters() = %+v, expected %+v", got, want)
+	}
+}
+
+func TestID3v2ChaptersDeterministic(t *testing.T) {
+	chap := func(id string, start uint32, frames ...[]byte) []byte {
+		b := append([]byte(id), 0)
+		b = binary.BigEndian.AppendUint32(b, start)
+		b = binary.BigEndian.AppendUint32(b, start+1000)
+		b = append(b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff)
+		return id3v23Frame("CHAP", append(b, bytes.Join(frames, nil)...))
+	}
+	// Two ordered CTOCs, neither of them top-level: the first by element ID is used.
+	b := id3v2FramesTag(3,
+		chap("ch1", 0, id3v23Frame("WXXX", []byte("\x00\x00http://b.example.com")), id3v23Frame("WOAR", []byte("http://a.example.com"))),
+		chap("ch2", 1000),
+		id3v23Frame("CTOC", []byte("toc2\x00\x01\x02ch1\x00ch2\x00")),
+		id3v23Frame("CTOC", []byte("toc1\x00\x01\x02ch2\x00ch1\x00")),
+	)
+	want := []Chapter{
+		{Start: time.Second, End: 2 * time.Second},
+		{Start: 0, End: time.Second, URL: "http://a.example.com"},
+	}
+	for i := 0; i < 20; i++ {
+		m, err := ReadID3v2Tags(bytes.NewReader(b))
+		if err != nil {
+			t.Fatalf("ReadID3v2Tags() = %v", err)
+		}
+		if got := m.Chapters(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("Chapters() = %+v, expected %+v", got, want)
+		}
+	}
+}
+
+func TestID3v2ChapterErrors(t *testing.T) {
+	chap := append([]byte("ch1\x00"), make([]byte, 16)...)
+	chap = append(chap, id3v23Frame("TIT2", []byte("\x00Truncated"))[:14]...)
+	b := id3v2FramesTag(3,
+		id3v23Frame("TIT2", []byte("\x00Title")),
+		id3v23Frame("CHAP", chap),
+		id3v23Frame("RVA2", []byte("track\x00\x01")),
+		id3v23Frame("CTOC", []byte("toc\x00\x03\x02ch1\x00")),
+	)
+
+	// Frames which can't be decoded are kept as raw data.
+	m, err := ReadID3v2Tags(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("ReadID3v2Tags() = %v", err)
+	}
+	if _, ok := m.Raw()["CHAP"].([]byte); !ok || m.Title() != "Title" {
+		t.Errorf("Raw()[\"CHAP\"] = %#v, Title() = %q", m.Raw()["CHAP"], m.Title())
+	}
+	for _, name := range []string{"CTOC", "RVA2"} {
+		if _, ok := m.Raw()[name].([]byte); !ok {
+			t.Errorf("Raw()[%q] = %#v, expected []byte", name, m.Raw()[name])
+		}
+	}
+
+	// The offsets of embedded frames are from the start of the file.
+	var warnings []error
+	if _, err := ReadID3v2Tags(bytes.NewReader(b), Lenient(&warnings)); err != nil {
+		t.Fatalf("lenient ReadID3v2Tags() = %v", err)
+	}
+	var perr *ParseError
+	if len(warnings) != 3 || !errors.As(warnings[0], &perr) || perr.Name != "TIT2" || perr.Offset != 56 {
+		t.Errorf("warnings = %v, expected TIT2 at offset 56 first", warnings)
+	}
+}
+
+func TestVorbisChapters(t *testing.T) {
+	m := newMetadataVorbis()
+	m.c["chapter001"] = "00:00:00.000"
+	m.c["chapter001name"] = "One"
+	m.c["chapter002"] = "00:01:30.500"
+	m.c["chapter002name"] = "Two"
+	m.c["chapter002url"] = "http://example.com"
+
+	want := []Chapter{
+		{Start: 0, End: 90500 * time.Millisecond, Title: "One"},
+		{Start: 90500 * time.Millisecond, Title: "Two", URL: "http://example.com"},
+	}
+	if got := m.Chapters(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Chapters() = %+v, expected %+v", got, want)
+	}
+}
+
+// mp4Box builds an MP4 box.
+func mp4Box(name string, body ...[]byte) []byte {
+	b := bytes.Join(body, nil)
+	return append(binary.BigEndian.AppendUint32(nil, uint32(len(b)+8)), append([]byte(name), b...)...)
+}
+
+func u32s(x ...uint32) []byte {
+	var b []byte
+	for _, v := range x {
+		b = binary.BigEndian.AppendUint32(b, v)
+	}
+	return b
+}
+
+func TestMP4Chapters(t *testing.T) {
+	ftyp := mp4Box("ftyp", []byte("M4A \x00\x00\x00\x00"))
+	samples := [][]byte{[]byte("\x00\x05First"), []byte("\x00\x06Second")}
+	mdat := mp4Box("mdat", samples...)
+	offset := uint32(len(ftyp) + 8)
+
+	hdlr := func(t string) []byte { return mp4Box("hdlr", u32s(0, 0), []byte(t), make([]byte, 12)) }
+	audio := mp4Box("trak",
+		mp4Box("tkhd", u32s(0, 0, 0, 1)),
+		mp4Box("tref", mp4Box("chap", u32s(2))),
+		mp4Box("mdia", hdlr("soun")),
+	)
+	text := mp4Box("trak",
+		mp4Box("tkhd", u32s(0, 0, 0, 2)),
+		mp4Box("mdia",
+			mp4Box("mdhd", u32s(0, 0, 0, 1000, 3000)),
+			hdlr("text"),
+			mp4Box("minf", mp4Box("stbl",
+				mp4Box("stts", u32s(0, 2, 1, 1000, 1, 2000)),
+				mp4Box("stsc", u32s(0, 1, 1, 2, 1)),
+				mp4Box("stsz", u32s(0, 0, 2, uint32(len(samples[0])), uint32(len(samples[1])))),
+				mp4Box("stco", u32s(0, 1, offset)),
+			)),
+		),
+	)
+	chpl := mp4Box("chpl", []byte{1, 0, 0, 0, 0, 0, 0, 0, 1}, make([]byte, 8), []byte("\x04Nero"))
+	moov := mp4Box("moov", mp4Box("mvhd", u32s(0, 0, 0, 1000, 3000), make([]byte, 80)), audio, text, mp4Box("udta", chpl))
+
+	m, err := ReadAtoms(bytes.NewReader(bytes.Join([][]byte{ftyp, mdat, moov}, nil)))
+	if err != nil {
+		t.Fatalf("ReadAtoms() = %v", err)
+	}
+	want := []Chapter{
+		{Start: 0, End: time.Second, Title: "First"},
+		{Start: time.Second, End: 3 * time.Second, Title: "Second"},
+	}
+	if got := m.Chapters(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Chapters() = %+v, expected %+v", got, want)
+	}
+
+	// Without the chapter track the Nero chapter list is used.
+	moov = mp4Box("moov", mp4Box("mvhd", u32s(0, 0, 0, 1000, 3000), make([]byte, 80)), mp4Box("udta", chpl))
+	m, err = ReadAtoms(bytes.NewReader(bytes.Join([][]byte{ftyp, moov}, nil)))
+	if err != nil {
+		t.Fatalf("ReadAtoms() = %v", err)
+	}
+	want = []Chapter{{Start: 0, End: 3 * time.Second, Title: "Nero"}}
+	if got := m.Chapters(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Chapters() = %+v, expected %+v", got, want)
+	}
+}
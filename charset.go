@@ -0,0 +1,139 @@
+package tag
+
+import (
+	"encoding/binary"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+)
+
+// Charset sets the character set of ID3v1 tags and of ID3v2 text marked as ISO-8859-1, which
+// is often written in a legacy code page instead, e.g. charmap.Windows1251 or
+// japanese.ShiftJIS from golang.org/x/text/encoding.
+//
+// By default ID3v2 text is decoded as ISO-8859-1 and ID3v1 text is left as is.
+func Charset(e encoding.Encoding) ReadOption {
+	return func(o *readOptions) {
+		o.charset = e
+	}
+}
+
+// DetectCharset enables detection of the character set of ID3v1 tags and of ID3v2 text marked
+// as ISO-8859-1.  Text which is valid UTF-8 is used as is, otherwise the most plausible
+// decoding by the candidates (Windows-1251 and Shift JIS if none are given) or ISO-8859-1 is
+// chosen.  A charset given by the Charset option is tried first.
+func DetectCharset(candidates ...encoding.Encoding) ReadOption {
+	if len(candidates) == 0 {
+		candidates = []encoding.Encoding{charmap.Windows1251, japanese.ShiftJIS}
+	}
+	return func(o *readOptions) {
+		o.detect = true
+		o.candidates = candidates
+	}
+}
+
+// UTF16ByteOrder sets the byte order of ID3v2 text marked as UTF-16 with a byte order mark
+// when the mark is missing (little endian by default).
+func UTF16ByteOrder(bo binary.ByteOrder) ReadOption {
+	return func(o *readOptions) {
+		o.byteOrder = bo
+	}
+}
+
+// utf16ByteOrder returns the byte order of UTF-16 text without a byte order mark.
+func (o *readOptions) utf16ByteOrder() binary.ByteOrder {
+	if o == nil || o.byteOrder == nil {
+		return DefaultUTF16WithBOMByteOrder
+	}
+	return o.byteOrder
+}
+
+// decodeLegacy decodes ID3v2 text marked as ISO-8859-1, using the configured charset.
+func (o *readOptions) decodeLegacy(b []byte) string {
+	if s, ok := o.decodeCharset(b); ok {
+		return s
+	}
+	return decodeISO8859(b)
+}
+
+// decodeID3v1 decodes ID3v1 text, using the configured charset.  Without one the bytes are
+// used as is (ID3v1 tags are commonly written in UTF-8).
+func (o *readOptions) decodeID3v1(b []byte) string {
+	if s, ok := o.decodeCharset(b); ok {
+		return s
+	}
+	return string(b)
+}
+
+// decodeCharset decodes b with the charset given by the Charset or DetectCharset options.
+// Returns false if neither is set.
+func (o *readOptions) decodeCharset(b []byte) (string, bool) {
+	if o == nil || (o.charset == nil && !o.detect) {
+		return "", false
+	}
+	if !o.detect {
+		s, err := o.charset.NewDecoder().Bytes(b)
+		if err != nil {
+			return "", false
+		}
+		return string(s), true
+	}
+
+	if utf8.Valid(b) {
+		return string(b), true
+	}
+	candidates := o.candidates
+	if o.charset != nil {
+		candidates = append([]encoding.Encoding{o.charset}, candidates...)
+	}
+	best := decodeISO8859(b)
+	bestScore := textScore(best)
+	for i := len(candidates) - 1; i >= 0; i-- {
+		// Iterate in reverse so that earlier candidates win ties (ISO-8859-1 is last).
+		s, err := candidates[i].NewDecoder().Bytes(b)
+		if err != nil {
+			continue
+		}
+		if score := textScore(string(s)); score >= bestScore {
+			best, bestScore = string(s), score
+		}
+	}
+	return best, true
+}
+
+// textScore returns a score of how plausible the text is as the result of decoding with the
+// right charset: letters from one script score highly, whereas replacement characters,
+// control characters, stray symbols and runs of mixed or accented letters (which result
+// from decoding with the wrong charset) are penalised.
+func textScore(s string) int {
+	var score int
+	var prev rune
+	for _, r := range s {
+		switch {
+		case r == utf8.RuneError || (unicode.IsControl(r) && r != '\t' && r != '\n' && r != '\r'):
+			score -= 10
+		case r < utf8.RuneSelf:
+		case r >= 0xff61 && r <= 0xff9f:
+			// Halfwidth katakana: rare, but Shift JIS decodes many single bytes to them.
+			score -= 2
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana):
+			score += 2
+		case unicode.Is(unicode.Cyrillic, r) && unicode.IsLetter(r):
+			score++
+			if prev < utf8.RuneSelf && unicode.IsLetter(prev) {
+				score -= 2
+			}
+		case unicode.IsLetter(r):
+			if prev >= utf8.RuneSelf && unicode.IsLetter(prev) {
+				score--
+			}
+		default:
+			score--
+		}
+		prev = r
+	}
+	return score
+}
@@ -0,0 +1,81 @@
+package tag
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+
+	"github.com/zeozeozeo/tag/internal/id3v1_test"
+)
+
+func encodeCharset(t *testing.T, e encoding.Encoding, s string) []byte {
+	t.Helper()
+	b, err := e.NewEncoder().Bytes([]byte(s))
+	if err != nil {
+		t.Fatalf("encoding %q: %v", s, err)
+	}
+	return b
+}
+
+func TestDetectCharset(t *testing.T) {
+	tests := []struct {
+		input []byte
+		want  string
+	}{
+		{[]byte("Plain ASCII"), "Plain ASCII"},
+		{[]byte("Café Noël"), "Café Noël"}, // UTF-8
+		{encodeCharset(t, charmap.ISO8859_1, "Café Noël"), "Café Noël"},
+		{encodeCharset(t, charmap.Windows1251, "Кино - Группа крови"), "Кино - Группа крови"},
+		{encodeCharset(t, japanese.ShiftJIS, "こんにちは世界"), "こんにちは世界"},
+	}
+	o := newReadOptions([]ReadOption{DetectCharset()})
+	for _, tt := range tests {
+		if got := o.decodeLegacy(tt.input); got != tt.want {
+			t.Errorf("decodeLegacy(%q) = %q, expected %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestCharsetID3v2(t *testing.T) {
+	title := append([]byte{encodingISO8859}, encodeCharset(t, charmap.Windows1251, "Группа крови")...)
+	b := id3v2FramesTag(3, id3v23Frame("TIT2", title), id3v23Frame("TPE1", []byte("\x01\x00K\x00i\x00n\x00o")))
+
+	tests := []struct {
+		opts   []ReadOption
+		title  string
+		artist string
+	}{
+		{nil, "Ãðóïïà êðîâè", "䬀椀渀漀"},
+		{[]ReadOption{Charset(charmap.Windows1251), UTF16ByteOrder(binary.BigEndian)}, "Группа крови", "Kino"},
+		{[]ReadOption{DetectCharset()}, "Группа крови", "䬀椀渀漀"},
+	}
+	for _, tt := range tests {
+		m, err := ReadID3v2Tags(bytes.NewReader(b), tt.opts...)
+		if err != nil {
+			t.Fatalf("ReadID3v2Tags() = %v", err)
+		}
+		if got := m.Title(); got != tt.title {
+			t.Errorf("Title() = %q, expected %q", got, tt.title)
+		}
+		if got := m.Artist(); got != tt.artist {
+			t.Errorf("Artist() = %q, expected %q", got, tt.artist)
+		}
+	}
+}
+
+func TestCharsetID3v1(t *testing.T) {
+	mp3 := id3v1_test.MustAsset("internal/id3v1_test/sample_ms932_v1.mp3")
+	for _, opt := range []ReadOption{Charset(japanese.ShiftJIS), DetectCharset()} {
+		m, err := ReadID3v1Tags(bytes.NewReader(mp3), opt)
+		if err != nil {
+			t.Fatalf("ReadID3v1Tags() = %v", err)
+		}
+		if got, want := m.Title(), "サンプルのタイトル"; got != want {
+			t.Errorf("Title() = %q, expected %q", got, want)
+		}
+	}
+}
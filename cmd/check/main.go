@@ -8,6 +8,7 @@ The check tool performs tag lookups on full music collections (iTunes or directo
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"io"
@@ -34,6 +35,7 @@ var (
 	itlXML = flag.String("itlXML", "", "iTunes Library Path")
 	path   = flag.String("path", "", "path to directory containing audio files")
 	sum    = flag.Bool("sum", false, "compute the checksum of the audio file (doesn't work for .flac or .ogg yet)")
+	verify = flag.Bool("verify", false, "decode FLAC files, verifying their frame CRCs and audio MD5")
 )
 
 func main() {
@@ -63,6 +65,7 @@ func main() {
 		decodingErrors: make(map[string]int),
 		hashErrors:     make(map[string]int),
 		hashes:         make(map[string]int),
+		verifyErrors:   make(map[string]int),
 	}
 
 	done := make(chan bool)
@@ -128,6 +131,7 @@ type processor struct {
 	decodingErrors map[string]int
 	hashErrors     map[string]int
 	hashes         map[string]int
+	verifyErrors   map[string]int
 }
 
 func (p *processor) String() string {
@@ -140,6 +144,10 @@ func (p *processor) String() string {
 		result += fmt.Sprintf("%v : %v\n", k, v)
 	}
 
+	for k, v := range p.verifyErrors {
+		result += fmt.Sprintf("%v : %v\n", k, v)
+	}
+
 	for k, v := range p.hashErrors {
 		if v > 1 {
 			result += fmt.Sprintf("%v : %v\n", k, v)
@@ -148,6 +156,16 @@ func (p *processor) String() string {
 	return result
 }
 
+// errorBucket returns the key used to group decoding errors: the format and kind of
+// parse errors, or the error message for anything else.
+func errorBucket(err error) string {
+	var pe *tag.ParseError
+	if errors.As(err, &pe) {
+		return fmt.Sprintf("%v: %v", pe.Format, pe.Kind)
+	}
+	return err.Error()
+}
+
 func (p *processor) do(ch <-chan string) {
 	for path := range ch {
 		func() {
@@ -169,10 +187,24 @@ func (p *processor) do(ch <-chan string) {
 				fmt.Println("IDENTIFY:", path, err.Error())
 			}
 
-			_, err = tag.ReadFrom(tf)
+			m, err := tag.ReadFrom(tf)
 			if err != nil {
 				fmt.Println("READFROM:", path, err.Error())
-				p.decodingErrors[err.Error()]++
+				p.decodingErrors[errorBucket(err)]++
+			}
+
+			if *verify && m != nil && m.FileType() == tag.FLAC {
+				_, err = tf.Seek(0, io.SeekStart)
+				if err != nil {
+					fmt.Println("DIED:", path, "error seeking back to 0:", err)
+					return
+				}
+
+				err = tag.VerifyFLAC(tf)
+				if err != nil {
+					fmt.Println("VERIFY:", path, err.Error())
+					p.verifyErrors[errorBucket(err)]++
+				}
 			}
 
 			if *sum {
@@ -86,6 +86,8 @@ func printMetadata(m tag.Metadata) {
 	fmt.Printf(" Composer: %v\n", m.Composer())
 	fmt.Printf(" Genre: %v\n", m.Genre())
 	fmt.Printf(" Year: %v\n", m.Year())
+	fmt.Printf(" Date: %v\n", m.Date())
+	fmt.Printf(" Original Date: %v\n", m.OriginalDate())
 
 	track, trackCount := m.Track()
 	fmt.Printf(" Track: %v of %v\n", track, trackCount)
@@ -96,4 +98,8 @@ func printMetadata(m tag.Metadata) {
 	fmt.Printf(" Picture: %v\n", m.Picture())
 	fmt.Printf(" Lyrics: %v\n", m.Lyrics())
 	fmt.Printf(" Comment: %v\n", m.Comment())
+
+	for _, c := range m.Chapters() {
+		fmt.Printf(" Chapter: %v-%v %v\n", c.Start, c.End, c.Title)
+	}
 }
@@ -0,0 +1,584 @@
+package tag
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// Conversion is metadata converted to another tag format by Convert.
+type Conversion struct {
+	Format Format // Format of the tag.
+
+	// Data is the encoded tag:
+	//   - ID3v2.3 and ID3v2.4: a complete tag (header and frames, without padding).
+	//   - MP4: an ilst atom, for moov.udta.meta.
+	//   - VORBIS: a Vorbis comment header without the packet type and framing bit (as in a
+	//     FLAC VORBIS_COMMENT block).
+	//   - APEv2: a complete tag with header and footer.
+	Data []byte
+
+	// Unsupported lists the fields (see Field) and raw keys of the source metadata which
+	// could not be represented in the format, sorted.
+	Unsupported []string
+}
+
+// Convert converts the metadata m to a tag of the given format (ID3v2_3, ID3v2_4, MP4,
+// VORBIS or APEv2).  Fields are translated using the field registry (see NativeKeys),
+// user-defined fields (TXXX frames, freeform atoms and Vorbis and APE fields which are not
+// in the registry) are carried over as user-defined fields, and all pictures are included.
+//
+// NB: values of ID3v2 text frames holding several values are joined when read, so they are
+// converted as a single value.
+func Convert(m Metadata, to Format) (*Conversion, error) {
+	t := &tagValues{
+		fields:      make(map[Field][]string),
+		custom:      make(map[string][]string),
+		unsupported: make(map[string]bool),
+	}
+	t.read(m)
+
+	c := &Conversion{Format: to}
+	items := t.items(to)
+	switch to {
+	case ID3v2_3:
+		c.Data = encodeID3v2(3, items, t.pictures)
+	case ID3v2_4:
+		c.Data = encodeID3v2(4, items, t.pictures)
+	case MP4:
+		c.Data = encodeMP4(items, t.pictures)
+	case VORBIS:
+		c.Data = encodeVorbisComment(t.vendor, items, t.pictures)
+	case APEv2:
+		c.Data = encodeAPE(items, t.pictures, t.unsupported)
+	default:
+		return nil, fmt.Errorf("%w: conversion to %q", errors.ErrUnsupported, to)
+	}
+
+	for k := range t.unsupported {
+		c.Unsupported = append(c.Unsupported, k)
+	}
+	sort.Strings(c.Unsupported)
+	return c, nil
+}
+
+// tagValues holds the format-independent values of a tag, used by Convert.
+type tagValues struct {
+	fields      map[Field][]string
+	custom      map[string][]string // user-defined fields, by name
+	pictures    []*Picture
+	vendor      string // Vorbis vendor string
+	unsupported map[string]bool
+}
+
+// convertItem is a native key and its values.
+type convertItem struct {
+	key    string
+	values []string
+}
+
+// id3v2StructureFrames are ID3v2 frames which are not carried over by Convert: date frames
+// which are read with TYER, and SEEK.
+var id3v2StructureFrames = map[string]bool{"TDA": true, "TDAT": true, "TIM": true, "TIME": true, "TRD": true, "TRDA": true, "SEEK": true}
+
+// read reads the values of m.
+func (t *tagValues) read(m Metadata) {
+	if merged, ok := m.(metadataMerged); ok {
+		// Read in reverse order of precedence, so that values are overwritten.
+		for i := len(merged) - 1; i >= 0; i-- {
+			t.read(merged[i])
+		}
+		return
+	}
+
+	format := m.Format()
+	raw := m.Raw()
+	multi, _ := m.(interface{ rawValues(k string) []string })
+	keys := make([]string, 0, len(raw))
+	for k := range raw {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var pictures []*Picture
+	for _, k := range keys {
+		name := k
+		if format == ID3v2_2 || format == ID3v2_3 || format == ID3v2_4 {
+			// Repeated frames are named e.g. TXXX_0.
+			name, _, _ = strings.Cut(k, "_")
+			if id3v2StructureFrames[name] {
+				continue
+			}
+		}
+
+		switch v := raw[k].(type) {
+		case *Picture:
+			pictures = append(pictures, v)
+
+		case *Comm:
+			if strings.HasPrefix(name, "TXX") {
+				t.set(format, name+":"+v.Description, v.Description, v.Text)
+			} else {
+				t.set(format, name, "", v.Text)
+			}
+
+		case *UFID:
+			t.set(format, name+":"+v.Provider, "", string(v.Identifier))
+
+		case string, int, []string:
+			values := []string{textValue(v)}
+			if multi != nil && len(multi.rawValues(k)) > 1 {
+				values = multi.rawValues(k)
+			}
+			var custom string
+			switch {
+			case format == VORBIS && k == "vendor":
+				t.vendor = values[0]
+				continue
+			case format == VORBIS || format == APEv2:
+				// Names are lower-cased when read.
+				custom = strings.ToUpper(k)
+			case format == MP4 && strings.HasPrefix(k, "----:"):
+				custom = k[strings.LastIndexByte(k, ':')+1:]
+			}
+			t.set(format, name, custom, values...)
+
+		default:
+			t.unsupported[k] = true
+		}
+	}
+
+	// Use the parsed values where the formats differ.
+	if d := m.Date(); !d.IsZero() {
+		t.fields[FieldDate] = []string{d.String()}
+	}
+	if d := m.OriginalDate(); !d.IsZero() {
+		t.fields[FieldOriginalDate] = []string{d.String()}
+	}
+	setXofN := func(n, total int, field, totalField Field) {
+		if n <= 0 {
+			return
+		}
+		t.fields[field] = []string{strconv.Itoa(n)}
+		delete(t.fields, totalField)
+		if total > 0 {
+			t.fields[totalField] = []string{strconv.Itoa(total)}
+		}
+	}
+	n, total := m.Track()
+	setXofN(n, total, FieldTrack, FieldTrackTotal)
+	n, total = m.Disc()
+	setXofN(n, total, FieldDisc, FieldDiscTotal)
+	if format == ID3v1 || format == ID3v2_2 || format == ID3v2_3 || format == ID3v2_4 || format == MP4 {
+		// Resolve genre references such as "(17)", and MP4 gnre atoms.
+		if g := m.Genre(); g != "" {
+			t.fields[FieldGenre] = []string{g}
+		}
+	}
+
+	if len(pictures) == 0 {
+		if p := m.Picture(); p != nil {
+			pictures = append(pictures, p)
+		}
+	}
+	if len(pictures) > 0 {
+		t.pictures = pictures
+	}
+}
+
+// set sets the field given by the native key k to the non-empty values, or the custom
+// (user-defined) field if k is not in the field registry.
+func (t *tagValues) set(format Format, k, custom string, values ...string) {
+	var nonEmpty []string
+	for _, v := range values {
+		if v != "" {
+			nonEmpty = append(nonEmpty, v)
+		}
+	}
+	if len(nonEmpty) == 0 {
+		return
+	}
+
+	if f, ok := FieldFor(format, k); ok {
+		t.fields[f] = nonEmpty
+		return
+	}
+	if custom != "" {
+		t.custom[custom] = nonEmpty
+		return
+	}
+	t.unsupported[k] = true
+}
+
+// items returns the native keys and values for the format.  Fields which the format cannot
+// represent are added to unsupported.
+func (t *tagValues) items(to Format) []convertItem {
+	totals := map[Field]Field{FieldTrack: FieldTrackTotal, FieldDisc: FieldDiscTotal}
+
+	var items []convertItem
+	for _, f := range Fields() {
+		values := t.fields[f]
+		if len(values) == 0 || f == FieldPicture {
+			// Pictures are taken from the Picture values.
+			continue
+		}
+		if to != VORBIS && (f == FieldTrackTotal || f == FieldDiscTotal) {
+			// Written with the number, as "n/total".
+			continue
+		}
+		keys := fieldKeys[f][to]
+		if len(keys) == 0 {
+			t.unsupported[string(f)] = true
+			continue
+		}
+		if total := t.fields[totals[f]]; to != VORBIS && len(total) > 0 {
+			values = []string{values[0] + "/" + total[0]}
+		}
+		items = append(items, convertItem{key: convertKey(to, keys[0]), values: values})
+	}
+
+	names := make([]string, 0, len(t.custom))
+	for name := range t.custom {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var key string
+		switch to {
+		case ID3v2_3, ID3v2_4:
+			key = "TXXX:" + name
+		case MP4:
+			key = "----:com.apple.iTunes:" + name
+		case VORBIS:
+			key = strings.ToUpper(name)
+		default:
+			key = name
+		}
+		items = append(items, convertItem{key: key, values: t.custom[name]})
+	}
+	return items
+}
+
+// convertKey returns the key written for the registered native key k: Vorbis field names
+// are upper case, and APE item keys are capitalised ("Album Artist", "MUSICBRAINZ_ALBUMID").
+func convertKey(to Format, k string) string {
+	switch {
+	case to == VORBIS, to == APEv2 && strings.Contains(k, "_"):
+		return strings.ToUpper(k)
+	case to == APEv2:
+		words := strings.Fields(k)
+		for i, w := range words {
+			words[i] = strings.ToUpper(w[:1]) + w[1:]
+		}
+		return strings.Join(words, " ")
+	}
+	return k
+}
+
+// pictureType returns the picture type code for the type name (see pictureTypes), or the
+// front cover type if the name is unknown.
+func pictureType(name string) byte {
+	for b, t := range pictureTypes {
+		if t == name {
+			return b
+		}
+	}
+	return 0x03
+}
+
+// encodeID3v2 encodes an ID3v2.3 or ID3v2.4 tag.
+func encodeID3v2(version byte, items []convertItem, pictures []*Picture) []byte {
+	var frames bytes.Buffer
+	for _, it := range items {
+		name, desc, _ := strings.Cut(it.key, ":")
+		// ID3v2.4 separates values with $00, ID3v2.3 taggers commonly use "/".
+		sep := "/"
+		if version == 4 {
+			sep = "\x00"
+		}
+		text := strings.Join(it.values, sep)
+
+		switch name {
+		case "TXXX":
+			enc := id3v2TextEncoding(version, desc, text)
+			writeID3v2Frame(&frames, version, name, []byte{enc}, encodeID3v2Text(enc, desc, true), encodeID3v2Text(enc, text, false))
+
+		case "UFID":
+			writeID3v2Frame(&frames, version, name, []byte(desc), []byte{0}, []byte(it.values[0]))
+
+		case "COMM", "USLT":
+			enc := id3v2TextEncoding(version, text)
+			writeID3v2Frame(&frames, version, name, []byte{enc}, []byte("eng"), encodeID3v2Text(enc, "", true), encodeID3v2Text(enc, text, false))
+
+		case "TYER", "TORY":
+			// ID3v2.3 dates are split into the year (TYER), day and month (TDAT, DDMM) and
+			// time (TIME, HHMM).
+			d := parseDate(it.values[0])
+			if d.IsZero() {
+				writeID3v2TextFrame(&frames, version, name, it.values[0])
+				break
+			}
+			writeID3v2TextFrame(&frames, version, name, fmt.Sprintf("%04d", d.Year))
+			if name == "TYER" && d.Day != 0 {
+				writeID3v2TextFrame(&frames, version, "TDAT", fmt.Sprintf("%02d%02d", d.Day, d.Month))
+			}
+			if name == "TYER" && d.HasTime {
+				writeID3v2TextFrame(&frames, version, "TIME", fmt.Sprintf("%02d%02d", d.Hour, d.Minute))
+			}
+
+		default:
+			writeID3v2TextFrame(&frames, version, name, text)
+		}
+	}
+
+	for _, p := range pictures {
+		enc := id3v2TextEncoding(version, p.Description)
+		writeID3v2Frame(&frames, version, "APIC", []byte{enc}, []byte(p.MIMEType), []byte{0, pictureType(p.Type)},
+			encodeID3v2Text(enc, p.Description, true), p.Data)
+	}
+
+	b := append([]byte("ID3"), version, 0, 0)
+	b = append(b, synchsafe(frames.Len())...)
+	return append(b, frames.Bytes()...)
+}
+
+// writeID3v2TextFrame writes a text information frame.
+func writeID3v2TextFrame(w *bytes.Buffer, version byte, name, text string) {
+	enc := id3v2TextEncoding(version, text)
+	writeID3v2Frame(w, version, name, []byte{enc}, encodeID3v2Text(enc, text, false))
+}
+
+// writeID3v2Frame writes a frame with the data given by the concatenation of parts.
+func writeID3v2Frame(w *bytes.Buffer, version byte, name string, parts ...[]byte) {
+	var size int
+	for _, p := range parts {
+		size += len(p)
+	}
+	w.WriteString(name)
+	if version == 4 {
+		w.Write(synchsafe(size))
+	} else {
+		w.Write(binary.BigEndian.AppendUint32(nil, uint32(size)))
+	}
+	w.Write([]byte{0, 0}) // flags
+	for _, p := range parts {
+		w.Write(p)
+	}
+}
+
+// synchsafe returns n as a 4 byte synchsafe integer (7 bits per byte).
+func synchsafe(n int) []byte {
+	return []byte{byte(n>>21) & 0x7f, byte(n>>14) & 0x7f, byte(n>>7) & 0x7f, byte(n) & 0x7f}
+}
+
+// id3v2TextEncoding returns the encoding used to write the text: UTF-8 for ID3v2.4,
+// otherwise ISO-8859-1 if possible, or UTF-16.
+func id3v2TextEncoding(version byte, text ...string) byte {
+	if version == 4 {
+		return encodingUTF8
+	}
+	for _, s := range text {
+		for _, r := range s {
+			if r > 0xff {
+				return encodingUTF16WithBOM
+			}
+		}
+	}
+	return encodingISO8859
+}
+
+// encodeID3v2Text encodes s, with a terminator if terminated is set.
+func encodeID3v2Text(enc byte, s string, terminated bool) []byte {
+	var b []byte
+	switch enc {
+	case encodingISO8859:
+		for _, r := range s {
+			b = append(b, byte(r))
+		}
+	case encodingUTF16WithBOM:
+		b = []byte{0xff, 0xfe}
+		for _, u := range utf16.Encode([]rune(s)) {
+			b = binary.LittleEndian.AppendUint16(b, u)
+		}
+	default:
+		b = []byte(s)
+	}
+	if !terminated {
+		return b
+	}
+	if enc == encodingUTF16WithBOM {
+		return append(b, 0, 0)
+	}
+	return append(b, 0)
+}
+
+// encodeMP4 encodes an ilst atom.
+func encodeMP4(items []convertItem, pictures []*Picture) []byte {
+	var ilst bytes.Buffer
+	for _, it := range items {
+		switch {
+		case strings.HasPrefix(it.key, "----:"):
+			i := strings.LastIndexByte(it.key, ':')
+			atoms := [][]byte{
+				mp4Atom("mean", []byte{0, 0, 0, 0}, []byte(it.key[5:i])),
+				mp4Atom("name", []byte{0, 0, 0, 0}, []byte(it.key[i+1:])),
+			}
+			for _, v := range it.values {
+				atoms = append(atoms, mp4DataAtom(mp4UTF8, []byte(v)))
+			}
+			ilst.Write(mp4Atom("----", atoms...))
+
+		case it.key == "trkn" || it.key == "disk":
+			// reserved (2 bytes), number (2 bytes), total (2 bytes), [reserved (2 bytes)]
+			n, total := parseXofN(it.values[0])
+			b := binary.BigEndian.AppendUint16([]byte{0, 0}, uint16(min(n, math.MaxUint16)))
+			b = binary.BigEndian.AppendUint16(b, uint16(min(total, math.MaxUint16)))
+			if it.key == "trkn" {
+				b = append(b, 0, 0)
+			}
+			ilst.Write(mp4Atom(it.key, mp4DataAtom(mp4Implicit, b)))
+
+		case it.key == "tmpo":
+			n := parseBPM(it.values[0])
+			ilst.Write(mp4Atom(it.key, mp4DataAtom(mp4Int, []byte{byte(n >> 8), byte(n)})))
+
+		case it.key == "cpil":
+			var b byte
+			if parseBool(it.values[0]) {
+				b = 1
+			}
+			ilst.Write(mp4Atom(it.key, mp4DataAtom(mp4Int, []byte{b})))
+
+		default:
+			var atoms [][]byte
+			for _, v := range it.values {
+				atoms = append(atoms, mp4DataAtom(mp4UTF8, []byte(v)))
+			}
+			ilst.Write(mp4Atom(it.key, atoms...))
+		}
+	}
+
+	if len(pictures) > 0 {
+		var covr [][]byte
+		for _, p := range pictures {
+			class := map[string]int{"image/jpeg": mp4JPEG, "image/png": mp4PNG, "image/gif": mp4GIF, "image/bmp": mp4BMP}[p.MIMEType]
+			if class == mp4Implicit {
+				class = imageClass(p.Data)
+			}
+			if class == mp4Implicit {
+				class = mp4JPEG
+			}
+			covr = append(covr, mp4DataAtom(uint32(class), p.Data))
+		}
+		ilst.Write(mp4Atom("covr", covr...))
+	}
+	return mp4Atom("ilst", ilst.Bytes())
+}
+
+// mp4Atom returns an atom with the body given by the concatenation of parts.
+func mp4Atom(name string, parts ...[]byte) []byte {
+	size := 8
+	for _, p := range parts {
+		size += len(p)
+	}
+	b := binary.BigEndian.AppendUint32(make([]byte, 0, size), uint32(size))
+	b = append(b, name...)
+	for _, p := range parts {
+		b = append(b, p...)
+	}
+	return b
+}
+
+// mp4DataAtom returns a data atom with the given type class (e.g. mp4UTF8).
+func mp4DataAtom(class uint32, b []byte) []byte {
+	// version (1 byte) and class (3 bytes), locale (4 bytes)
+	return mp4Atom("data", binary.BigEndian.AppendUint32(nil, class), []byte{0, 0, 0, 0}, b)
+}
+
+// encodeVorbisComment encodes a Vorbis comment header.
+func encodeVorbisComment(vendor string, items []convertItem, pictures []*Picture) []byte {
+	var comments []string
+	for _, it := range items {
+		for _, v := range it.values {
+			comments = append(comments, it.key+"="+v)
+		}
+	}
+	for _, p := range pictures {
+		comments = append(comments, "METADATA_BLOCK_PICTURE="+base64.StdEncoding.EncodeToString(encodeFLACPicture(p)))
+	}
+
+	b := binary.LittleEndian.AppendUint32(nil, uint32(len(vendor)))
+	b = append(b, vendor...)
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(comments)))
+	for _, c := range comments {
+		b = binary.LittleEndian.AppendUint32(b, uint32(len(c)))
+		b = append(b, c...)
+	}
+	return b
+}
+
+// encodeFLACPicture encodes a FLAC PICTURE block (without the block header).
+func encodeFLACPicture(p *Picture) []byte {
+	b := binary.BigEndian.AppendUint32(nil, uint32(pictureType(p.Type)))
+	b = binary.BigEndian.AppendUint32(b, uint32(len(p.MIMEType)))
+	b = append(b, p.MIMEType...)
+	b = binary.BigEndian.AppendUint32(b, uint32(len(p.Description)))
+	b = append(b, p.Description...)
+	b = append(b, make([]byte, 16)...) // width, height, colour depth and number of colours
+	b = binary.BigEndian.AppendUint32(b, uint32(len(p.Data)))
+	return append(b, p.Data...)
+}
+
+// encodeAPE encodes an APEv2 tag.  Only one picture can be stored for each of the front
+// cover, back cover and other types, others are added to unsupported.
+func encodeAPE(items []convertItem, pictures []*Picture, unsupported map[string]bool) []byte {
+	var body bytes.Buffer
+	var count uint32
+	writeItem := func(key string, flags uint32, value []byte) {
+		body.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(value))))
+		body.Write(binary.LittleEndian.AppendUint32(nil, flags))
+		body.WriteString(key)
+		body.WriteByte(0)
+		body.Write(value)
+		count++
+	}
+
+	for _, it := range items {
+		writeItem(it.key, 0, []byte(strings.Join(it.values, "\x00")))
+	}
+	written := make(map[string]bool)
+	for _, p := range pictures {
+		key := "Cover Art (Other)"
+		switch pictureType(p.Type) {
+		case 0x03:
+			key = "Cover Art (Front)"
+		case 0x04:
+			key = "Cover Art (Back)"
+		}
+		if written[key] {
+			unsupported[string(FieldPicture)] = true
+			continue
+		}
+		written[key] = true
+		writeItem(key, apeItemTypeBinary<<1, append(append([]byte(p.Description), 0), p.Data...))
+	}
+
+	header := func(flags uint32) []byte {
+		b := append([]byte(apeTagPreamble), binary.LittleEndian.AppendUint32(nil, 2000)...)
+		b = binary.LittleEndian.AppendUint32(b, uint32(body.Len()+apeTagFooterSize))
+		b = binary.LittleEndian.AppendUint32(b, count)
+		b = binary.LittleEndian.AppendUint32(b, flags)
+		return append(b, make([]byte, 8)...)
+	}
+	// Flags: bit 31 is set if there is a header, bit 29 is set in the header.
+	b := header(1<<31 | 1<<29)
+	b = append(b, body.Bytes()...)
+	return append(b, header(1<<31)...)
+}
@@ -0,0 +1,165 @@
+package tag
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// convertSource returns Vorbis comments with a range of fields, as read from a file.
+func convertSource(t *testing.T) Metadata {
+	t.Helper()
+	comments := []string{
+		"TITLE=Tomorrow Never Knows",
+		"ARTIST=John Lennon",
+		"ARTIST=Paul McCartney",
+		"DATE=1966-08-05",
+		"TRACKNUMBER=14",
+		"TRACKTOTAL=14",
+		"LABEL=Parlophone",
+		"MUSICBRAINZ_ALBUMID=0ee2ac4b-0f05-4b38-b1b0-2b6fe5a0e4d2",
+		"MUSICBRAINZ_TRACKID=d5b7f5d7-8e30-4b4a-9d2b-3f4c1a1c1e2f",
+		"MY_FIELD=x",
+	}
+	b := encodeVorbisComment("vendor", nil, nil)
+	b = b[:len(b)-4]
+	b = append(b, byte(len(comments)), 0, 0, 0)
+	for _, c := range comments {
+		b = append(b, byte(len(c)), 0, 0, 0)
+		b = append(b, c...)
+	}
+
+	m := newMetadataVorbis()
+	if err := m.readVorbisComment(bytes.NewReader(b), func(err error) error { return err }); err != nil {
+		t.Fatalf("readVorbisComment() = %v", err)
+	}
+	m.p = &Picture{MIMEType: "image/png", Type: pictureTypes[0x03], Description: "cover", Data: []byte("\x89PNG")}
+	return &metadataOGG{metadataVorbis: m}
+}
+
+// readConverted reads the tag produced by Convert.
+func readConverted(t *testing.T, c *Conversion) Metadata {
+	t.Helper()
+	var m Metadata
+	var err error
+	switch c.Format {
+	case ID3v2_3, ID3v2_4:
+		var id3 *metadataID3v2
+		id3, err = ReadID3v2Tags(bytes.NewReader(c.Data))
+		m = &metadataV2MP3{metadataID3v2: id3}
+	case MP4:
+		ftyp := mp4Box("ftyp", []byte("M4A "), u32s(0))
+		moov := mp4Box("moov", mp4Box("udta", mp4Box("meta", u32s(0), c.Data)))
+		m, err = ReadAtoms(bytes.NewReader(append(ftyp, moov...)))
+	case VORBIS:
+		v := newMetadataVorbis()
+		err = v.readVorbisComment(bytes.NewReader(c.Data), func(err error) error { return err })
+		m = &metadataOGG{metadataVorbis: v}
+	case APEv2:
+		m, err = ReadAPETags(bytes.NewReader(c.Data))
+	}
+	if err != nil {
+		t.Fatalf("%v: reading converted tag: %v", c.Format, err)
+	}
+	return m
+}
+
+func TestConvert(t *testing.T) {
+	src := convertSource(t)
+	for _, format := range []Format{ID3v2_3, ID3v2_4, MP4, VORBIS, APEv2} {
+		c, err := Convert(src, format)
+		if err != nil {
+			t.Fatalf("Convert(%v) = %v", format, err)
+		}
+		if len(c.Unsupported) != 0 {
+			t.Errorf("Convert(%v) unsupported = %v, expected none", format, c.Unsupported)
+		}
+
+		m := readConverted(t, c)
+		if got := m.Title(); got != "Tomorrow Never Knows" {
+			t.Errorf("%v: Title() = %q", format, got)
+		}
+		if got, want := m.Date().String(), "1966-08-05"; got != want {
+			t.Errorf("%v: Date() = %q, expected %q", format, got, want)
+		}
+		if n, total := m.Track(); n != 14 || total != 14 {
+			t.Errorf("%v: Track() = %v, %v, expected 14, 14", format, n, total)
+		}
+		if got := m.Label(); got != "Parlophone" {
+			t.Errorf("%v: Label() = %q", format, got)
+		}
+		for _, f := range []Field{FieldMusicBrainzAlbumID, FieldMusicBrainzRecordingID} {
+			if got, want := m.Get(f), src.Get(f); got != want {
+				t.Errorf("%v: Get(%q) = %q, expected %q", format, f, got, want)
+			}
+		}
+		if p := m.Picture(); p == nil || !bytes.Equal(p.Data, []byte("\x89PNG")) {
+			t.Errorf("%v: Picture() = %v, expected the cover", format, p)
+		}
+
+		// User-defined fields are carried over.
+		c2, err := Convert(m, VORBIS)
+		if err != nil {
+			t.Fatalf("Convert(%v, VORBIS) = %v", format, err)
+		}
+		if got := readConverted(t, c2).Raw()["my_field"]; got != "x" {
+			t.Errorf("%v: MY_FIELD = %q, expected %q", format, got, "x")
+		}
+	}
+}
+
+func TestConvertMultipleValues(t *testing.T) {
+	src := convertSource(t)
+	want := []string{"John Lennon", "Paul McCartney"}
+	for _, format := range []Format{MP4, VORBIS, APEv2} {
+		c, err := Convert(src, format)
+		if err != nil {
+			t.Fatalf("Convert(%v) = %v", format, err)
+		}
+		m := readConverted(t, c)
+		key := convertKey(format, fieldKeys[FieldArtist][format][0])
+		if got := m.(interface{ rawValues(string) []string }).rawValues(fieldKeys[FieldArtist][format][0]); !reflect.DeepEqual(got, want) {
+			t.Errorf("%v: %s values = %q, expected %q", format, key, got, want)
+		}
+	}
+
+	c, err := Convert(src, ID3v2_4)
+	if err != nil {
+		t.Fatalf("Convert(ID3v2.4) = %v", err)
+	}
+	if !bytes.Contains(c.Data, []byte("TPE1\x00\x00\x00\x1b\x00\x00\x03John Lennon\x00Paul McCartney")) {
+		t.Errorf("ID3v2.4 TPE1 frame does not hold both values")
+	}
+}
+
+func TestConvertUnsupported(t *testing.T) {
+	b := id3v2FramesTag(3,
+		id3v23Frame("TIT2", []byte("\x00Title")),
+		id3v23Frame("PRIV", []byte("owner\x00data")),
+	)
+	id3, err := ReadID3v2Tags(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("ReadID3v2Tags() = %v", err)
+	}
+	c, err := Convert(&metadataV2MP3{metadataID3v2: id3}, APEv2)
+	if err != nil {
+		t.Fatalf("Convert() = %v", err)
+	}
+	if want := []string{"PRIV"}; !reflect.DeepEqual(c.Unsupported, want) {
+		t.Errorf("Unsupported = %v, expected %v", c.Unsupported, want)
+	}
+
+	mp4 := metadataMP4{data: map[string]interface{}{"\xa9nam": "Title", "keyw": "a, b"}}
+	c, err = Convert(mp4, ID3v2_4)
+	if err != nil {
+		t.Fatalf("Convert() = %v", err)
+	}
+	if want := []string{string(FieldKeywords)}; !reflect.DeepEqual(c.Unsupported, want) {
+		t.Errorf("Unsupported = %v, expected %v", c.Unsupported, want)
+	}
+
+	if _, err := Convert(mp4, ID3v1); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("Convert(ID3v1) = %v, expected ErrUnsupported", err)
+	}
+}
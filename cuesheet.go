@@ -0,0 +1,462 @@
+package tag
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrNoCueSheet is returned by CueTracks when no cue sheet is given or embedded in the
+// metadata.
+var ErrNoCueSheet = errors.New("no cue sheet found")
+
+// CueSheet is a cue sheet describing the tracks of a single-file album, read from a .cue file
+// (see ParseCueSheet), a CUESHEET Vorbis comment or APE item, or a FLAC CUESHEET block.
+type CueSheet struct {
+	Catalog    string // Media catalog number (e.g. the UPC/EAN of a CD).
+	Title      string // Album title.
+	Performer  string // Album artist.
+	Songwriter string
+	File       string            // The first FILE, if any.
+	Rem        map[string]string // REM comments by (upper case) name, e.g. "GENRE", "DATE".
+	Tracks     []CueTrack
+
+	leadOut int64 // offset of the FLAC lead-out track in samples, or 0
+}
+
+// CueTrack is a track of a cue sheet.
+type CueTrack struct {
+	Number     int
+	Type       string // Data type, e.g. "AUDIO".
+	File       string // The FILE the track is in.
+	Title      string
+	Performer  string
+	Songwriter string
+	ISRC       string
+	Flags      []string // e.g. "DCP", "PRE".
+	Indexes    []CueIndex
+}
+
+// CueIndex is an index point of a cue sheet track.  INDEX 01 is the start of the track, and
+// INDEX 00 the start of its pregap.
+type CueIndex struct {
+	Number int
+	Offset time.Duration // Offset from the start of the file.
+
+	samples    int64 // offset in samples from FLAC CUESHEET blocks
+	hasSamples bool  // whether samples is set
+}
+
+// start returns the start of the track: INDEX 01, or else its first index.
+func (t CueTrack) start() (CueIndex, bool) {
+	for _, x := range t.Indexes {
+		if x.Number == 1 {
+			return x, true
+		}
+	}
+	if len(t.Indexes) > 0 {
+		return t.Indexes[0], true
+	}
+	return CueIndex{}, false
+}
+
+// cueFramesPerSecond is the number of CD frames in a second (MSF times are minutes, seconds
+// and frames).
+const cueFramesPerSecond = 75
+
+// ParseCueSheet parses a cue sheet (e.g. a .cue file) from r.  Text which is not UTF-8 (or
+// UTF-16 with a byte order mark) is decoded using the Charset or DetectCharset options, or
+// else as ISO-8859-1.  If lenient parsing is enabled, lines which cannot be parsed are skipped.
+func ParseCueSheet(r io.Reader, opts ...ReadOption) (*CueSheet, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return parseCueSheet(b, newReadOptions(opts))
+}
+
+func parseCueSheet(b []byte, o *readOptions) (*CueSheet, error) {
+	var text string
+	switch {
+	case bytes.HasPrefix(b, []byte{0xef, 0xbb, 0xbf}):
+		text = string(b[3:])
+	case bytes.HasPrefix(b, []byte{0xff, 0xfe}), bytes.HasPrefix(b, []byte{0xfe, 0xff}):
+		s, err := decodeUTF16WithBOM(b, binary.LittleEndian)
+		if err != nil {
+			return nil, newParseError("CUE", 0, "", err)
+		}
+		text = s
+	case utf8.Valid(b):
+		text = string(b)
+	default:
+		text = o.decodeLegacy(b)
+	}
+
+	p := &cueParser{c: &CueSheet{Rem: make(map[string]string)}, track: -1}
+	var offset int64
+	for _, line := range strings.SplitAfter(text, "\n") {
+		start := offset
+		offset += int64(len(line))
+		args := cueFields(line)
+		if len(args) == 0 {
+			continue
+		}
+		cmd := strings.ToUpper(args[0])
+		if err := o.warn(newParseError("CUE", start, cmd, p.readCommand(cmd, args[1:]))); err != nil {
+			return nil, err
+		}
+	}
+	return p.c, nil
+}
+
+// cueParser holds the state of a cue sheet being parsed.
+type cueParser struct {
+	c     *CueSheet
+	track int    // index of the current track, or -1 before the first TRACK
+	file  string // the current FILE
+}
+
+// readCommand reads a cue sheet command.  Commands which are not needed are ignored.
+func (p *cueParser) readCommand(cmd string, args []string) error {
+	arg := func(i int) string {
+		if i < len(args) {
+			return args[i]
+		}
+		return ""
+	}
+	var t *CueTrack
+	if p.track >= 0 {
+		t = &p.c.Tracks[p.track]
+	}
+
+	switch cmd {
+	case "REM":
+		if len(args) > 0 && t == nil {
+			p.c.Rem[strings.ToUpper(args[0])] = strings.Join(args[1:], " ")
+		}
+
+	case "CATALOG":
+		p.c.Catalog = arg(0)
+
+	case "FILE":
+		p.file = arg(0)
+		if p.c.File == "" {
+			p.c.File = p.file
+		}
+
+	case "TRACK":
+		n, err := strconv.Atoi(arg(0))
+		if err != nil {
+			return fmt.Errorf("%w: track number %q", ErrMalformed, arg(0))
+		}
+		p.c.Tracks = append(p.c.Tracks, CueTrack{Number: n, Type: arg(1), File: p.file})
+		p.track = len(p.c.Tracks) - 1
+
+	case "TITLE", "PERFORMER", "SONGWRITER":
+		// Before the first track these are album fields.
+		fields := map[string]*string{"TITLE": &p.c.Title, "PERFORMER": &p.c.Performer, "SONGWRITER": &p.c.Songwriter}
+		if t != nil {
+			fields = map[string]*string{"TITLE": &t.Title, "PERFORMER": &t.Performer, "SONGWRITER": &t.Songwriter}
+		}
+		*fields[cmd] = arg(0)
+
+	case "ISRC", "FLAGS", "INDEX":
+		if t == nil {
+			return fmt.Errorf("%w: %v before TRACK", ErrMalformed, cmd)
+		}
+		switch cmd {
+		case "ISRC":
+			t.ISRC = arg(0)
+		case "FLAGS":
+			t.Flags = args
+		case "INDEX":
+			n, err := strconv.Atoi(arg(0))
+			if err != nil {
+				return fmt.Errorf("%w: index number %q", ErrMalformed, arg(0))
+			}
+			d, err := parseCueTime(arg(1))
+			if err != nil {
+				return err
+			}
+			t.Indexes = append(t.Indexes, CueIndex{Number: n, Offset: d})
+		}
+	}
+	return nil
+}
+
+// cueFields splits a cue sheet line into fields separated by spaces, where fields may be
+// quoted.
+func cueFields(line string) []string {
+	var fields []string
+	line = strings.TrimSpace(line)
+	for line != "" {
+		var f string
+		if line[0] == '"' {
+			end := strings.IndexByte(line[1:], '"')
+			if end < 0 {
+				f, line = line[1:], ""
+			} else {
+				f, line = line[1:end+1], line[end+2:]
+			}
+		} else {
+			end := strings.IndexAny(line, " \t")
+			if end < 0 {
+				end = len(line)
+			}
+			f, line = line[:end], line[end:]
+		}
+		fields = append(fields, f)
+		line = strings.TrimLeft(line, " \t")
+	}
+	return fields
+}
+
+// parseCueTime parses an MSF time (mm:ss:ff, where there are 75 frames in a second).
+func parseCueTime(s string) (time.Duration, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("%w: time %q", ErrMalformed, s)
+	}
+	var msf [3]int
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("%w: time %q", ErrMalformed, s)
+		}
+		msf[i] = n
+	}
+	if msf[1] > 59 || msf[2] >= cueFramesPerSecond {
+		return 0, fmt.Errorf("%w: time %q", ErrMalformed, s)
+	}
+	frames := (int64(msf[0])*60+int64(msf[1]))*cueFramesPerSecond + int64(msf[2])
+	return time.Duration(frames) * time.Second / cueFramesPerSecond, nil
+}
+
+// cueSheetFromFLAC returns the cue sheet given by a FLAC CUESHEET block, where offsets are in
+// samples at the given rate.
+func cueSheetFromFLAC(fc *FLACCueSheet, sampleRate int) *CueSheet {
+	c := &CueSheet{Catalog: fc.CatalogNumber, Rem: make(map[string]string)}
+	for _, ft := range fc.Tracks {
+		if (fc.CD && ft.Number == 170) || ft.Number == 255 {
+			c.leadOut = int64(ft.Offset)
+			continue
+		}
+		t := CueTrack{Number: ft.Number, Type: "AUDIO", ISRC: ft.ISRC}
+		if !ft.Audio {
+			t.Type = "DATA"
+		}
+		if ft.PreEmphasis {
+			t.Flags = []string{"PRE"}
+		}
+		for _, fx := range ft.Indexes {
+			n := ft.Offset + fx.Offset
+			t.Indexes = append(t.Indexes, CueIndex{
+				Number:     fx.Number,
+				Offset:     samplesDuration(n, uint32(sampleRate)),
+				samples:    int64(n),
+				hasSamples: true,
+			})
+		}
+		c.Tracks = append(c.Tracks, t)
+	}
+	return c
+}
+
+// EmbeddedCueSheet returns the cue sheet embedded in m: a CUESHEET Vorbis comment or APE item,
+// or else a FLAC CUESHEET block.  Returns nil if there is none.
+func EmbeddedCueSheet(m Metadata, opts ...ReadOption) (*CueSheet, error) {
+	for k, v := range m.Raw() {
+		if s, ok := v.(string); ok && strings.EqualFold(k, "cuesheet") {
+			return parseCueSheet([]byte(s), newReadOptions(opts))
+		}
+	}
+	if f, ok := m.(FLACMetadata); ok && f.CueSheet() != nil {
+		rate := 0
+		if a := m.Audio(); a != nil {
+			rate = a.SampleRate
+		}
+		return cueSheetFromFLAC(f.CueSheet(), rate), nil
+	}
+	return nil, nil
+}
+
+// CueTracks splits the metadata of a single-file album into the metadata of each track given
+// by the cue sheet.  If cue is nil, the cue sheet embedded in m is used (see EmbeddedCueSheet),
+// and ErrNoCueSheet is returned if there is none.  Tracks without indexes are skipped.
+func CueTracks(m Metadata, cue *CueSheet) ([]*CueTrackMetadata, error) {
+	if cue == nil {
+		var err error
+		if cue, err = EmbeddedCueSheet(m); err != nil {
+			return nil, err
+		}
+		if cue == nil {
+			return nil, ErrNoCueSheet
+		}
+	}
+
+	rate := 0
+	if a := m.Audio(); a != nil {
+		rate = a.SampleRate
+	}
+	// samples returns the offset of x in samples, or -1 if unknown.
+	samples := func(x CueIndex) int64 {
+		switch {
+		case x.hasSamples:
+			return x.samples
+		case rate > 0:
+			return int64(math.Round(x.Offset.Seconds() * float64(rate)))
+		}
+		return -1
+	}
+
+	var tracks []*CueTrackMetadata
+	for _, t := range cue.Tracks {
+		start, ok := t.start()
+		if !ok {
+			continue
+		}
+		tracks = append(tracks, &CueTrackMetadata{
+			Metadata:    m,
+			CueTrack:    t,
+			Start:       start.Offset,
+			StartSample: samples(start),
+			EndSample:   -1,
+			cue:         cue,
+		})
+	}
+
+	// Tracks end at the start of the next track in the same file, or else the end of the audio.
+	for i, t := range tracks {
+		if i+1 < len(tracks) && tracks[i+1].CueTrack.File == t.CueTrack.File {
+			next := tracks[i+1]
+			t.End, t.EndSample = next.Start, next.StartSample
+			continue
+		}
+		switch {
+		case cue.leadOut > 0:
+			t.End = samplesDuration(uint64(cue.leadOut), uint32(rate))
+			t.EndSample = cue.leadOut
+		case m.Duration() > t.Start:
+			t.End = m.Duration()
+			if a := m.Audio(); a != nil && a.Samples > 0 {
+				t.EndSample = a.Samples
+			}
+		}
+	}
+	return tracks, nil
+}
+
+// CueTrackMetadata is the metadata of a track of a single-file album, given by a cue sheet.
+// Fields of the track (and of the cue sheet) take precedence over the metadata of the file,
+// except for the title and ISRC, which are only those of the track.
+type CueTrackMetadata struct {
+	Metadata // Metadata of the whole file.
+
+	CueTrack    CueTrack
+	Start       time.Duration // Start of the track (INDEX 01) in the file.
+	End         time.Duration // End of the track in the file, or zero if unknown.
+	StartSample int64         // Start of the track in samples, or -1 if unknown.
+	EndSample   int64         // End of the track in samples, or -1 if unknown.
+
+	cue *CueSheet
+}
+
+// firstNonEmpty returns the first non-empty string.
+func firstNonEmpty(s ...string) string {
+	for _, x := range s {
+		if x != "" {
+			return x
+		}
+	}
+	return ""
+}
+
+func (m *CueTrackMetadata) Title() string { return m.CueTrack.Title }
+
+func (m *CueTrackMetadata) Album() string { return firstNonEmpty(m.cue.Title, m.Metadata.Album()) }
+
+func (m *CueTrackMetadata) Artist() string {
+	return firstNonEmpty(m.CueTrack.Performer, m.cue.Performer, m.Metadata.Artist())
+}
+
+func (m *CueTrackMetadata) AlbumArtist() string {
+	return firstNonEmpty(m.cue.Performer, m.Metadata.AlbumArtist())
+}
+
+func (m *CueTrackMetadata) Composer() string {
+	return firstNonEmpty(m.CueTrack.Songwriter, m.cue.Songwriter, m.Metadata.Composer())
+}
+
+func (m *CueTrackMetadata) Genre() string {
+	return firstNonEmpty(m.cue.Rem["GENRE"], m.Metadata.Genre())
+}
+
+func (m *CueTrackMetadata) ISRC() string { return m.CueTrack.ISRC }
+
+func (m *CueTrackMetadata) CatalogNumber() string {
+	return firstNonEmpty(m.cue.Catalog, m.Metadata.CatalogNumber())
+}
+
+func (m *CueTrackMetadata) Date() Date {
+	if d := parseDate(m.cue.Rem["DATE"]); !d.IsZero() {
+		return d
+	}
+	return m.Metadata.Date()
+}
+
+func (m *CueTrackMetadata) Year() int {
+	if d := parseDate(m.cue.Rem["DATE"]); !d.IsZero() {
+		return d.Year
+	}
+	return m.Metadata.Year()
+}
+
+func (m *CueTrackMetadata) Track() (int, int) { return m.CueTrack.Number, len(m.cue.Tracks) }
+
+// Chapters returns nil: chapters of the file do not apply to its tracks.
+func (m *CueTrackMetadata) Chapters() []Chapter { return nil }
+
+func (m *CueTrackMetadata) Duration() time.Duration {
+	if m.End == 0 {
+		return 0
+	}
+	return m.End - m.Start
+}
+
+func (m *CueTrackMetadata) Get(f Field) string {
+	switch f {
+	case FieldTitle:
+		return m.Title()
+	case FieldAlbum:
+		return m.Album()
+	case FieldArtist:
+		return m.Artist()
+	case FieldAlbumArtist:
+		return m.AlbumArtist()
+	case FieldComposer:
+		return m.Composer()
+	case FieldGenre:
+		return m.Genre()
+	case FieldISRC:
+		return m.ISRC()
+	case FieldCatalogNumber:
+		return m.CatalogNumber()
+	case FieldDate:
+		if d := m.Date(); !d.IsZero() {
+			return d.String()
+		}
+		return ""
+	case FieldTrack:
+		return strconv.Itoa(m.CueTrack.Number)
+	case FieldTrackTotal:
+		return strconv.Itoa(len(m.cue.Tracks))
+	}
+	return m.Metadata.Get(f)
+}
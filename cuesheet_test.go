@@ -0,0 +1,221 @@
+package tag
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+const testCueSheet = "\xef\xbb\xbfREM GENRE \"Progressive Rock\"\r\n" +
+	"REM DATE 1994\r\n" +
+	"CATALOG 0724382975229\r\n" +
+	"PERFORMER \"Pink Floyd\"\r\n" +
+	"TITLE \"The Division Bell\"\r\n" +
+	"FILE \"The Division Bell.flac\" WAVE\r\n" +
+	"  TRACK 01 AUDIO\r\n" +
+	"    TITLE \"Cluster One\"\r\n" +
+	"    ISRC GBN9Y1100088\r\n" +
+	"    INDEX 01 00:00:00\r\n" +
+	"  TRACK 02 AUDIO\r\n" +
+	"    TITLE \"What Do You Want from Me\"\r\n" +
+	"    PERFORMER \"David Gilmour\"\r\n" +
+	"    SONGWRITER Gilmour\r\n" +
+	"    FLAGS DCP PRE\r\n" +
+	"    INDEX 00 00:01:00\r\n" +
+	"    INDEX 01 00:01:37\r\n"
+
+func TestParseCueSheet(t *testing.T) {
+	c, err := ParseCueSheet(strings.NewReader(testCueSheet))
+	if err != nil {
+		t.Fatalf("ParseCueSheet() = %v", err)
+	}
+	want := &CueSheet{
+		Catalog:   "0724382975229",
+		Title:     "The Division Bell",
+		Performer: "Pink Floyd",
+		File:      "The Division Bell.flac",
+		Rem:       map[string]string{"GENRE": "Progressive Rock", "DATE": "1994"},
+		Tracks: []CueTrack{
+			{
+				Number:  1,
+				Type:    "AUDIO",
+				File:    "The Division Bell.flac",
+				Title:   "Cluster One",
+				ISRC:    "GBN9Y1100088",
+				Indexes: []CueIndex{{Number: 1, Offset: 0}},
+			},
+			{
+				Number:     2,
+				Type:       "AUDIO",
+				File:       "The Division Bell.flac",
+				Title:      "What Do You Want from Me",
+				Performer:  "David Gilmour",
+				Songwriter: "Gilmour",
+				Flags:      []string{"DCP", "PRE"},
+				Indexes: []CueIndex{
+					{Number: 0, Offset: time.Second},
+					{Number: 1, Offset: time.Second + 37*time.Second/75},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("ParseCueSheet() = %+v, expected %+v", c, want)
+	}
+}
+
+func TestParseCueSheetErrors(t *testing.T) {
+	b := "TRACK 01 AUDIO\nINDEX 01 00:60:00\nINDEX 01 00:00:00\n"
+	_, err := ParseCueSheet(strings.NewReader(b))
+	var pe *ParseError
+	if !errors.As(err, &pe) || !errors.Is(err, ErrMalformed) || pe.Offset != 15 || pe.Name != "INDEX" {
+		t.Errorf("ParseCueSheet() = %v, expected malformed INDEX at offset 15", err)
+	}
+
+	var warnings []error
+	c, err := ParseCueSheet(strings.NewReader(b), Lenient(&warnings))
+	if err != nil {
+		t.Fatalf("ParseCueSheet() = %v", err)
+	}
+	if len(warnings) != 1 || len(c.Tracks) != 1 || len(c.Tracks[0].Indexes) != 1 {
+		t.Errorf("ParseCueSheet() = %+v with warnings %v, expected one index and one warning", c, warnings)
+	}
+}
+
+func TestParseCueSheetCharset(t *testing.T) {
+	b := encodeCharset(t, charmap.Windows1251, "TITLE \"Группа крови\"\n")
+	c, err := ParseCueSheet(bytes.NewReader(b), Charset(charmap.Windows1251))
+	if err != nil {
+		t.Fatalf("ParseCueSheet() = %v", err)
+	}
+	if want := "Группа крови"; c.Title != want {
+		t.Errorf("Title = %q, expected %q", c.Title, want)
+	}
+}
+
+// cueFLAC builds a FLAC file of 441000 samples at 44100 Hz with the given blocks.
+func cueFLAC(blocks ...[]byte) []byte {
+	// 4096/4096 block size, unknown frame sizes, 44100 Hz, 2 channels, 16 bits
+	streamInfo := []byte{0x10, 0x00, 0x10, 0x00, 0, 0, 0, 0, 0, 0, 0x0a, 0xc4, 0x42, 0xf0, 0x00, 0x06, 0xba, 0xa8}
+	b := append([]byte("fLaC"), flacBlock(streamInfoBlock, false, streamInfo, make([]byte, 16))...)
+	for i, x := range blocks {
+		x = append([]byte(nil), x...)
+		if i == len(blocks)-1 {
+			x[0] |= 0x80
+		}
+		b = append(b, x...)
+	}
+	return b
+}
+
+func TestCueTracks(t *testing.T) {
+	comment := func(s ...string) []byte {
+		b := append(u32le(0), u32le(uint32(len(s)))...)
+		for _, x := range s {
+			b = append(append(b, u32le(uint32(len(x)))...), x...)
+		}
+		return flacBlock(vorbisCommentBlock, false, b)
+	}
+
+	m, err := ReadFLACMeta(bytes.NewReader(cueFLAC(comment("ALBUM=Tag Album", "ARTIST=Tag Artist", "CUESHEET="+testCueSheet))))
+	if err != nil {
+		t.Fatalf("ReadFLACMeta() = %v", err)
+	}
+	tracks, err := CueTracks(m, nil)
+	if err != nil {
+		t.Fatalf("CueTracks() = %v", err)
+	}
+	if len(tracks) != 2 {
+		t.Fatalf("CueTracks() returned %d tracks, expected 2", len(tracks))
+	}
+
+	first, second := tracks[0], tracks[1]
+	if first.Start != 0 || first.StartSample != 0 || first.EndSample != 44100+37*588 {
+		t.Errorf("track 1 samples = %v to %v, expected 0 to %v", first.StartSample, first.EndSample, 44100+37*588)
+	}
+	if second.EndSample != 441000 || second.Duration() != 10*time.Second-second.Start {
+		t.Errorf("track 2 ends at %v (%v), expected sample 441000", second.EndSample, second.End)
+	}
+	tests := []struct {
+		m    Metadata
+		f    Field
+		want string
+	}{
+		{first, FieldTitle, "Cluster One"},
+		{first, FieldArtist, "Pink Floyd"},
+		{first, FieldAlbum, "The Division Bell"},
+		{first, FieldISRC, "GBN9Y1100088"},
+		{first, FieldGenre, "Progressive Rock"},
+		{first, FieldDate, "1994"},
+		{first, FieldTrack, "1"},
+		{first, FieldTrackTotal, "2"},
+		{second, FieldArtist, "David Gilmour"},
+		{second, FieldAlbumArtist, "Pink Floyd"},
+		{second, FieldComposer, "Gilmour"},
+		{second, FieldISRC, ""},
+	}
+	for _, tt := range tests {
+		if got := tt.m.Get(tt.f); got != tt.want {
+			t.Errorf("%q: Get(%v) = %q, expected %q", tt.m.Title(), tt.f, got, tt.want)
+		}
+	}
+	if n, total := second.Track(); n != 2 || total != 2 {
+		t.Errorf("Track() = %v, %v, expected 2, 2", n, total)
+	}
+}
+
+func TestCueTracksFLACCueSheet(t *testing.T) {
+	cueTrack := func(offset uint64, n byte, indexes ...uint64) []byte {
+		b := bytes.Join([][]byte{u64s(offset), {n}, make([]byte, 12), make([]byte, 14), {byte(len(indexes))}}, nil)
+		for i, x := range indexes {
+			b = append(append(b, u64s(x)...), byte(i+1), 0, 0, 0)
+		}
+		return b
+	}
+	cueSheet := flacBlock(cueSheetBlock, false, make([]byte, 128), u64s(88200), []byte{0x80}, make([]byte, 258), []byte{3},
+		cueTrack(0, 1, 0), cueTrack(100000, 2, 588), cueTrack(400000, 170))
+
+	m, err := ReadFLACMeta(bytes.NewReader(cueFLAC(cueSheet)))
+	if err != nil {
+		t.Fatalf("ReadFLACMeta() = %v", err)
+	}
+	tracks, err := CueTracks(m, nil)
+	if err != nil {
+		t.Fatalf("CueTracks() = %v", err)
+	}
+	var got [][2]int64
+	for _, t := range tracks {
+		got = append(got, [2]int64{t.StartSample, t.EndSample})
+	}
+	if want := [][2]int64{{0, 100588}, {100588, 400000}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("CueTracks() samples = %v, expected %v", got, want)
+	}
+	if d := tracks[1].End; d != 400000*time.Second/44100 {
+		t.Errorf("End = %v, expected %v", d, 400000*time.Second/44100)
+	}
+
+	// Cue sheets built by hand give their offsets as durations.
+	cue := &CueSheet{Tracks: []CueTrack{
+		{Number: 1, Indexes: []CueIndex{{Number: 1}}},
+		{Number: 2, Indexes: []CueIndex{{Number: 1, Offset: time.Second}}},
+	}}
+	if tracks, err = CueTracks(m, cue); err != nil {
+		t.Fatalf("CueTracks() = %v", err)
+	}
+	if tracks[1].StartSample != 44100 {
+		t.Errorf("StartSample = %v, expected %v", tracks[1].StartSample, 44100)
+	}
+
+	m, err = ReadFLACMeta(bytes.NewReader(cueFLAC(flacBlock(blockType(1), false, make([]byte, 4)))))
+	if err != nil {
+		t.Fatalf("ReadFLACMeta() = %v", err)
+	}
+	if _, err := CueTracks(m, nil); !errors.Is(err, ErrNoCueSheet) {
+		t.Errorf("CueTracks() = %v, expected ErrNoCueSheet", err)
+	}
+}
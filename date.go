@@ -0,0 +1,123 @@
+package tag
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Date is a possibly partial date (and time) read from metadata.  Components which are not
+// given are zero: a Date may hold only a year, a year and month, and so on.
+type Date struct {
+	Year   int
+	Month  int // 1–12, or 0 if unknown.
+	Day    int // 1–31, or 0 if unknown.
+	Hour   int
+	Minute int
+	Second int
+
+	HasTime bool // Whether Hour, Minute and Second were given.
+}
+
+// IsZero reports whether the date is unknown.
+func (d Date) IsZero() bool {
+	return d.Year == 0
+}
+
+// String returns the date in (partial) ISO 8601 format, e.g. "2006", "2006-01" or
+// "2006-01-02T15:04:05".
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	s := fmt.Sprintf("%04d", d.Year)
+	if d.Month == 0 {
+		return s
+	}
+	s += fmt.Sprintf("-%02d", d.Month)
+	if d.Day == 0 {
+		return s
+	}
+	s += fmt.Sprintf("-%02d", d.Day)
+	if !d.HasTime {
+		return s
+	}
+	return s + fmt.Sprintf("T%02d:%02d:%02d", d.Hour, d.Minute, d.Second)
+}
+
+// Before reports whether d is before e.  Unknown components sort before known ones, so
+// "2006" is before "2006-01".
+func (d Date) Before(e Date) bool {
+	a := [...]int{d.Year, d.Month, d.Day, d.Hour, d.Minute, d.Second}
+	b := [...]int{e.Year, e.Month, e.Day, e.Hour, e.Minute, e.Second}
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
+
+// parseDate parses a (partial) ISO 8601 date such as "2006", "2006-01-02",
+// "2006-01-02T15:04:05Z" or "2006-01-02 15:04", as well as "20060102".  Parsing stops at
+// the first invalid component, so as much of the date as possible is returned.
+func parseDate(s string) Date {
+	s = strings.TrimSpace(s)
+	if len(s) == 8 && strings.Trim(s, "0123456789") == "" {
+		// YYYYMMDD
+		s = s[0:4] + "-" + s[4:6] + "-" + s[6:8]
+	}
+
+	var d Date
+	date, clock, hasClock := strings.Cut(s, "T")
+	if !hasClock {
+		date, clock, hasClock = strings.Cut(s, " ")
+	}
+
+	parts := strings.SplitN(date, "-", 3)
+	fields := []struct {
+		v              *int
+		digits, lo, hi int
+	}{{&d.Year, 4, 1, 9999}, {&d.Month, 2, 1, 12}, {&d.Day, 2, 1, 31}}
+	for i, p := range parts {
+		f := fields[i]
+		n, ok := parseDateComponent(p, f.digits, f.lo, f.hi)
+		if !ok {
+			return d
+		}
+		*f.v = n
+	}
+	if len(parts) < 3 || !hasClock {
+		return d
+	}
+
+	// Drop any fractional seconds or time zone.
+	clock = strings.TrimRight(strings.SplitN(clock, ".", 2)[0], "Z")
+	if i := strings.IndexAny(clock, "+-"); i >= 0 {
+		clock = clock[:i]
+	}
+	var t [3]int
+	for i, p := range strings.SplitN(clock, ":", 3) {
+		n, ok := parseDateComponent(p, 2, 0, []int{23, 59, 59}[i])
+		if !ok {
+			return d
+		}
+		t[i] = n
+	}
+	d.Hour, d.Minute, d.Second = t[0], t[1], t[2]
+	d.HasTime = true
+	return d
+}
+
+// parseDateComponent parses a date or time component with the given number of digits,
+// which must be in the range lo to hi.
+func parseDateComponent(s string, digits, lo, hi int) (int, bool) {
+	if len(s) != digits || strings.Trim(s, "0123456789") != "" {
+		return 0, false
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < lo || n > hi {
+		return 0, false
+	}
+	return n, true
+}
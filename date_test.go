@@ -0,0 +1,83 @@
+package tag
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Date
+	}{
+		{"", Date{}},
+		{"2006", Date{Year: 2006}},
+		{"2006-01", Date{Year: 2006, Month: 1}},
+		{"2006-01-02", Date{Year: 2006, Month: 1, Day: 2}},
+		{"20060102", Date{Year: 2006, Month: 1, Day: 2}},
+		{"2006-01-02T15:04:05Z", Date{2006, 1, 2, 15, 4, 5, true}},
+		{"2006-01-02 15:04", Date{2006, 1, 2, 15, 4, 0, true}},
+		{"2006-13-02", Date{Year: 2006}},
+		{"06", Date{}},
+	}
+
+	for _, tt := range tests {
+		if got := parseDate(tt.in); got != tt.want {
+			t.Errorf("parseDate(%q) = %+v, expected %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDateString(t *testing.T) {
+	tests := []struct {
+		in   Date
+		want string
+	}{
+		{Date{}, ""},
+		{Date{Year: 2006}, "2006"},
+		{Date{Year: 2006, Month: 1}, "2006-01"},
+		{Date{2006, 1, 2, 15, 4, 5, true}, "2006-01-02T15:04:05"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.in.String(); got != tt.want {
+			t.Errorf("%+v.String() = %q, expected %q", tt.in, got, tt.want)
+		}
+	}
+
+	if !(Date{Year: 2006}).Before(Date{Year: 2006, Month: 1}) {
+		t.Errorf("expected 2006 to be before 2006-01")
+	}
+}
+
+func TestID3v23Date(t *testing.T) {
+	b := id3v2FramesTag(3,
+		id3v23Frame("TYER", []byte("\x001999")),
+		id3v23Frame("TDAT", []byte("\x003112")),
+		id3v23Frame("TIME", []byte("\x002359")),
+		id3v23Frame("TORY", []byte("\x001971")),
+	)
+	m, err := ReadID3v2Tags(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("ReadID3v2Tags() = %v", err)
+	}
+	if got, want := m.Date(), (Date{1999, 12, 31, 23, 59, 0, true}); got != want {
+		t.Errorf("Date() = %+v, expected %+v", got, want)
+	}
+	if got, want := m.OriginalDate(), (Date{Year: 1971}); got != want {
+		t.Errorf("OriginalDate() = %+v, expected %+v", got, want)
+	}
+}
+
+func TestVorbisDate(t *testing.T) {
+	m := newMetadataVorbis()
+	m.c["date"] = "2010-05-04"
+	m.c["originalyear"] = "1984"
+
+	if got, want := m.Date(), (Date{Year: 2010, Month: 5, Day: 4}); got != want {
+		t.Errorf("Date() = %+v, expected %+v", got, want)
+	}
+	if got, want := m.OriginalDate(), (Date{Year: 1984}); got != want {
+		t.Errorf("OriginalDate() = %+v, expected %+v", got, want)
+	}
+}
@@ -5,7 +5,7 @@
 package tag
 
 import (
-	"errors"
+	"fmt"
 	"io"
 	"time"
 )
@@ -13,53 +13,64 @@ import (
 // ReadDSFMeta reads DSF metadata from the io.ReadSeeker, returning the resulting
 // metadata in a Metadata implementation, or non-nil error if there was a problem.
 // samples: http://www.2l.no/hires/index.html
-func ReadDSFMeta(r io.ReadSeeker) (Metadata, error) {
+func ReadDSFMeta(r io.ReadSeeker, opts ...ReadOption) (Metadata, error) {
+	offset := tell(r)
+	dsfError := func(name string, err error) error {
+		return newParseError(string(DSF), offset, name, err)
+	}
+
 	dsd, err := readString(r, 4)
 	if err != nil {
-		return nil, err
+		return nil, dsfError("", err)
 	}
 	if dsd != "DSD " {
-		return nil, errors.New("expected 'DSD '")
+		return nil, dsfError("", fmt.Errorf("%w: expected 'DSD '", ErrMalformed))
 	}
 
 	_, err = r.Seek(int64(16), io.SeekCurrent)
 	if err != nil {
-		return nil, err
+		return nil, dsfError("DSD ", err)
 	}
 
 	id3Pointer, err := readUint64LittleEndian(r)
 	if err != nil {
-		return nil, err
+		return nil, dsfError("DSD ", err)
 	}
 
-	_, err = r.Seek(int64(28), io.SeekCurrent)
+	offset = tell(r)
+	_, err = r.Seek(int64(24), io.SeekCurrent)
 	if err != nil {
-		return nil, err
+		return nil, dsfError("fmt ", err)
+	}
+
+	channels, err := readUint32LittleEndian(r)
+	if err != nil {
+		return nil, dsfError("fmt ", err)
 	}
 
 	sampleRate, err := readUint32LittleEndian(r)
 	if err != nil {
-		return nil, err
+		return nil, dsfError("fmt ", err)
 	}
 
 	_, err = r.Seek(int64(4), io.SeekCurrent)
 	if err != nil {
-		return nil, err
+		return nil, dsfError("fmt ", err)
 	}
 
 	sampleNum, err := readUint64LittleEndian(r)
 	if err != nil {
-		return nil, err
+		return nil, dsfError("fmt ", err)
 	}
 
-	duration := time.Second * (time.Duration(sampleNum) / time.Duration(sampleRate))
+	duration := samplesDuration(sampleNum, sampleRate)
 
 	_, err = r.Seek(int64(id3Pointer), io.SeekStart)
 	if err != nil {
-		return nil, err
+		return nil, newParseError(string(DSF), int64(id3Pointer), "", err)
 	}
 
-	id3, err := ReadID3v2Tags(r)
+	id3, err := ReadID3v2Tags(r, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -67,18 +78,24 @@ func ReadDSFMeta(r io.ReadSeeker) (Metadata, error) {
 	return metadataDSF{
 		metadataID3v2: id3,
 		duration:      duration,
+		audio:         &Audio{Codec: CodecDSD, SampleRate: int(sampleRate), Channels: int(channels), BitDepth: 1, Samples: int64(sampleNum)},
 	}, nil
 }
 
 type metadataDSF struct {
 	*metadataID3v2
 	duration time.Duration
+	audio    *Audio
 }
 
 func (m metadataDSF) FileType() FileType {
 	return DSF
 }
 
+func (m metadataDSF) Audio() *Audio {
+	return m.audio
+}
+
 func (m metadataDSF) Duration() time.Duration {
 	return m.duration
 }
@@ -0,0 +1,98 @@
+package tag
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Kinds of ParseError.  These can be matched against errors returned by this package using
+// errors.Is.
+var (
+	ErrTruncated          = errors.New("truncated data")
+	ErrBadChecksum        = errors.New("bad checksum")
+	ErrUnsupportedVersion = errors.New("unsupported version")
+	ErrInvalidEncoding    = errors.New("invalid encoding")
+	ErrMalformed          = errors.New("malformed data")
+)
+
+var parseErrorKinds = []error{
+	ErrTruncated,
+	ErrBadChecksum,
+	ErrUnsupportedVersion,
+	ErrInvalidEncoding,
+	ErrMalformed,
+	errors.ErrUnsupported,
+}
+
+// ParseError is the error returned when tag or container data cannot be parsed.  Use
+// errors.As to retrieve it, and errors.Is to match its Kind.
+type ParseError struct {
+	Format string // Format or container being read, e.g. "ID3v2.4", "MP4", "FLAC".
+	Offset int64  // Offset of the failing frame/atom/block from the start of the file.
+	Name   string // Name of the frame, atom, block or chunk, if any.
+	Kind   error  // Kind of error (ErrTruncated, ErrBadChecksum, etc).
+	Err    error  // Underlying error.
+}
+
+// Error implements error.
+func (e *ParseError) Error() string {
+	s := e.Format
+	if e.Name != "" {
+		s += fmt.Sprintf(" %q", e.Name)
+	}
+	s += fmt.Sprintf(" at offset %d: ", e.Offset)
+
+	if e.Err == nil {
+		return s + e.Kind.Error()
+	}
+	if errors.Is(e.Err, e.Kind) {
+		return s + e.Err.Error()
+	}
+	return s + e.Kind.Error() + ": " + e.Err.Error()
+}
+
+// Unwrap returns the Kind and the underlying error.
+func (e *ParseError) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// newParseError wraps err in a ParseError, deriving its Kind from err.  Returns err
+// unchanged if it is nil or already contains a ParseError.
+func newParseError(format string, offset int64, name string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var pe *ParseError
+	if errors.As(err, &pe) {
+		return err
+	}
+	return &ParseError{
+		Format: format,
+		Offset: offset,
+		Name:   name,
+		Kind:   errorKind(err),
+		Err:    err,
+	}
+}
+
+func errorKind(err error) error {
+	for _, k := range parseErrorKinds {
+		if errors.Is(err, k) {
+			return k
+		}
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return ErrTruncated
+	}
+	return ErrMalformed
+}
+
+// tell returns the current offset of r, or -1 if it cannot be determined.
+func tell(r io.Seeker) int64 {
+	n, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return -1
+	}
+	return n
+}
@@ -0,0 +1,54 @@
+package tag
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestParseErrorTruncatedFrame(t *testing.T) {
+	b := id3v23Tag(map[string]string{"TIT2": "Test Title"})
+	b = b[:len(b)-4]
+
+	_, err := ReadFrom(bytes.NewReader(b))
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("ReadFrom() error = %v, expected *ParseError", err)
+	}
+	if !errors.Is(err, ErrTruncated) {
+		t.Errorf("ReadFrom() error kind = %v, expected %v", pe.Kind, ErrTruncated)
+	}
+	testValue(t, string(ID3v2_3), pe.Format)
+	testValue(t, "TIT2", pe.Name)
+	testValue(t, int64(10), pe.Offset)
+}
+
+func TestParseErrorBadChecksum(t *testing.T) {
+	b, err := os.ReadFile("testdata/with_tags/sample.ogg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b[100] ^= 0xff // corrupt the second page (the first is 58 bytes long)
+
+	_, err = ReadFrom(bytes.NewReader(b))
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("ReadFrom() error = %v, expected *ParseError", err)
+	}
+	if !errors.Is(err, ErrBadChecksum) {
+		t.Errorf("ReadFrom() error kind = %v, expected %v", pe.Kind, ErrBadChecksum)
+	}
+	testValue(t, string(OGG), pe.Format)
+	testValue(t, int64(58), pe.Offset)
+}
+
+func TestParseErrorUnsupportedVersion(t *testing.T) {
+	b := id3v23Tag(nil)
+	b[3] = 5
+
+	_, err := ReadID3v2Tags(bytes.NewReader(b))
+	if !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("ReadID3v2Tags() error = %v, expected %v", err, ErrUnsupportedVersion)
+	}
+}
@@ -0,0 +1,363 @@
+package tag
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Field is a canonical, format-independent metadata field name.  Use NativeKeys and
+// FieldFor to translate between fields and the keys returned by Metadata.Raw, and
+// Metadata.Get to read a field from any format.
+type Field string
+
+// Canonical fields.
+const (
+	FieldTitle           Field = "title"
+	FieldAlbum           Field = "album"
+	FieldArtist          Field = "artist"
+	FieldAlbumArtist     Field = "album_artist"
+	FieldComposer        Field = "composer"
+	FieldDate            Field = "date"
+	FieldOriginalDate    Field = "original_date"
+	FieldGenre           Field = "genre"
+	FieldTrack           Field = "track"
+	FieldTrackTotal      Field = "track_total"
+	FieldDisc            Field = "disc"
+	FieldDiscTotal       Field = "disc_total"
+	FieldComment         Field = "comment"
+	FieldLyrics          Field = "lyrics"
+	FieldPicture         Field = "picture"
+	FieldTitleSort       Field = "title_sort"
+	FieldAlbumSort       Field = "album_sort"
+	FieldArtistSort      Field = "artist_sort"
+	FieldAlbumArtistSort Field = "album_artist_sort"
+	FieldBPM             Field = "bpm"
+	FieldKey             Field = "key"
+	FieldISRC            Field = "isrc"
+	FieldLabel           Field = "label"
+	FieldCatalogNumber   Field = "catalog_number"
+	FieldCopyright       Field = "copyright"
+	FieldEncoder         Field = "encoder"
+	FieldEncodedBy       Field = "encoded_by"
+	FieldGrouping        Field = "grouping"
+	FieldConductor       Field = "conductor"
+	FieldLyricist        Field = "lyricist"
+	FieldMood            Field = "mood"
+	FieldCompilation     Field = "compilation"
+	FieldRating          Field = "rating"
+	FieldKeywords        Field = "keywords"
+
+	// MusicBrainz identifiers, as written by MusicBrainz Picard.
+	FieldMusicBrainzRecordingID    Field = "musicbrainz_recording_id"
+	FieldMusicBrainzTrackID        Field = "musicbrainz_track_id" // Release track.
+	FieldMusicBrainzAlbumID        Field = "musicbrainz_album_id"
+	FieldMusicBrainzArtistID       Field = "musicbrainz_artist_id"
+	FieldMusicBrainzAlbumArtistID  Field = "musicbrainz_album_artist_id"
+	FieldMusicBrainzReleaseGroupID Field = "musicbrainz_release_group_id"
+	FieldMusicBrainzDiscID         Field = "musicbrainz_disc_id"
+	FieldAcoustID                  Field = "acoustid_id"
+)
+
+// fieldKeys maps each field to its native keys, in order of preference.  ID3v2 TXXX frames
+// are written "TXXX:DESCRIPTION" (TXX for ID3v2.2), UFID frames "UFID:owner" and MP4 freeform
+// atoms "----:mean:name".
+var fieldKeys = map[Field]map[Format][]string{
+	FieldTitle: {
+		ID3v1: {"title"}, ID3v2_2: {"TT2"}, ID3v2_3: {"TIT2"}, ID3v2_4: {"TIT2"},
+		MP4: {"\xa9nam"}, VORBIS: {"title"}, APEv2: {"title"},
+	},
+	FieldAlbum: {
+		ID3v1: {"album"}, ID3v2_2: {"TAL"}, ID3v2_3: {"TALB"}, ID3v2_4: {"TALB"},
+		MP4: {"\xa9alb"}, VORBIS: {"album"}, APEv2: {"album"},
+	},
+	FieldArtist: {
+		ID3v1: {"artist"}, ID3v2_2: {"TP1"}, ID3v2_3: {"TPE1"}, ID3v2_4: {"TPE1"},
+		MP4: {"\xa9ART", "\xa9art"}, VORBIS: {"artist"}, APEv2: {"artist"},
+	},
+	FieldAlbumArtist: {
+		ID3v2_2: {"TP2"}, ID3v2_3: {"TPE2"}, ID3v2_4: {"TPE2"},
+		MP4: {"aART"}, VORBIS: {"albumartist", "album artist"}, APEv2: {"album artist", "albumartist"},
+	},
+	FieldComposer: {
+		ID3v2_2: {"TCM"}, ID3v2_3: {"TCOM"}, ID3v2_4: {"TCOM"},
+		MP4: {"\xa9wrt"}, VORBIS: {"composer"}, APEv2: {"composer"},
+	},
+	FieldDate: {
+		ID3v1: {"year"}, ID3v2_2: {"TYE"}, ID3v2_3: {"TYER", "TDRC"}, ID3v2_4: {"TDRC", "TDRL"},
+		MP4: {"\xa9day"}, VORBIS: {"date", "year"}, APEv2: {"year"},
+	},
+	FieldOriginalDate: {
+		ID3v2_2: {"TOR", "TXX:ORIGINALDATE", "TXX:ORIGINALYEAR"},
+		ID3v2_3: {"TORY", "TXXX:ORIGINALDATE", "TXXX:ORIGINALYEAR"},
+		ID3v2_4: {"TDOR", "TXXX:ORIGINALDATE", "TXXX:ORIGINALYEAR"},
+		MP4:     {"----:com.apple.iTunes:ORIGINALDATE", "----:com.apple.iTunes:ORIGINALYEAR"},
+		VORBIS:  {"originaldate", "originalyear"}, APEv2: {"originaldate", "originalyear"},
+	},
+	FieldGenre: {
+		ID3v1: {"genre"}, ID3v2_2: {"TCO"}, ID3v2_3: {"TCON"}, ID3v2_4: {"TCON"},
+		MP4: {"\xa9gen"}, VORBIS: {"genre"}, APEv2: {"genre"},
+	},
+	FieldTrack: {
+		ID3v1: {"track"}, ID3v2_2: {"TRK"}, ID3v2_3: {"TRCK"}, ID3v2_4: {"TRCK"},
+		MP4: {"trkn"}, VORBIS: {"tracknumber"}, APEv2: {"track"},
+	},
+	FieldTrackTotal: {
+		MP4: {"trkn_count"}, VORBIS: {"tracktotal", "totaltracks"},
+	},
+	FieldDisc: {
+		ID3v2_2: {"TPA"}, ID3v2_3: {"TPOS"}, ID3v2_4: {"TPOS"},
+		MP4: {"disk"}, VORBIS: {"discnumber"}, APEv2: {"disc"},
+	},
+	FieldDiscTotal: {
+		MP4: {"disk_count"}, VORBIS: {"disctotal", "totaldiscs"},
+	},
+	FieldComment: {
+		ID3v1: {"comment"}, ID3v2_2: {"COM"}, ID3v2_3: {"COMM"}, ID3v2_4: {"COMM"},
+		MP4: {"\xa9cmt"}, VORBIS: {"comment", "description"}, APEv2: {"comment"},
+	},
+	FieldLyrics: {
+		ID3v2_2: {"ULT"}, ID3v2_3: {"USLT"}, ID3v2_4: {"USLT"},
+		MP4: {"\xa9lyr"}, VORBIS: {"lyrics"}, APEv2: {"lyrics"},
+	},
+	FieldPicture: {
+		ID3v2_2: {"PIC"}, ID3v2_3: {"APIC"}, ID3v2_4: {"APIC"},
+		MP4: {"covr"}, VORBIS: {"metadata_block_picture"}, APEv2: {"cover art (front)"},
+	},
+	FieldTitleSort: {
+		ID3v2_2: {"TST"}, ID3v2_3: {"TSOT"}, ID3v2_4: {"TSOT"},
+		MP4: {"sonm"}, VORBIS: {"titlesort"}, APEv2: {"titlesort"},
+	},
+	FieldAlbumSort: {
+		ID3v2_2: {"TSA"}, ID3v2_3: {"TSOA"}, ID3v2_4: {"TSOA"},
+		MP4: {"soal"}, VORBIS: {"albumsort"}, APEv2: {"albumsort"},
+	},
+	FieldArtistSort: {
+		ID3v2_2: {"TSP"}, ID3v2_3: {"TSOP"}, ID3v2_4: {"TSOP"},
+		MP4: {"soar"}, VORBIS: {"artistsort"}, APEv2: {"artistsort"},
+	},
+	FieldAlbumArtistSort: {
+		ID3v2_2: {"TS2", "TXX:ALBUMARTISTSORT"},
+		ID3v2_3: {"TSO2", "TXXX:ALBUMARTISTSORT"},
+		ID3v2_4: {"TSO2", "TXXX:ALBUMARTISTSORT"},
+		MP4:     {"soaa"}, VORBIS: {"albumartistsort"}, APEv2: {"albumartistsort"},
+	},
+	FieldBPM: {
+		ID3v2_2: {"TBP"}, ID3v2_3: {"TBPM"}, ID3v2_4: {"TBPM"},
+		MP4: {"tmpo", "----:com.apple.iTunes:BPM"}, VORBIS: {"bpm"}, APEv2: {"bpm"},
+	},
+	FieldKey: {
+		ID3v2_2: {"TKE"}, ID3v2_3: {"TKEY"}, ID3v2_4: {"TKEY"},
+		MP4:    {"----:com.apple.iTunes:initialkey", "----:com.apple.iTunes:KEY", "----:com.mixedinkey.mixedinkey:key"},
+		VORBIS: {"key", "initialkey"}, APEv2: {"key", "initialkey"},
+	},
+	FieldISRC: {
+		ID3v2_2: {"TRC"}, ID3v2_3: {"TSRC"}, ID3v2_4: {"TSRC"},
+		MP4: {"----:com.apple.iTunes:ISRC"}, VORBIS: {"isrc"}, APEv2: {"isrc"},
+	},
+	FieldLabel: {
+		ID3v2_2: {"TPB", "TXX:LABEL"}, ID3v2_3: {"TPUB", "TXXX:LABEL"}, ID3v2_4: {"TPUB", "TXXX:LABEL"},
+		MP4:    {"\xa9pub", "----:com.apple.iTunes:LABEL", "----:com.apple.iTunes:publisher"},
+		VORBIS: {"label", "organization", "publisher"}, APEv2: {"label", "publisher"},
+	},
+	FieldCatalogNumber: {
+		ID3v2_2: {"TXX:CATALOGNUMBER"}, ID3v2_3: {"TXXX:CATALOGNUMBER"}, ID3v2_4: {"TXXX:CATALOGNUMBER"},
+		MP4: {"----:com.apple.iTunes:CATALOGNUMBER"}, VORBIS: {"catalognumber"}, APEv2: {"catalognumber"},
+	},
+	FieldCopyright: {
+		ID3v2_2: {"TCR"}, ID3v2_3: {"TCOP"}, ID3v2_4: {"TCOP"},
+		MP4: {"cprt"}, VORBIS: {"copyright"}, APEv2: {"copyright"},
+	},
+	FieldEncoder: {
+		ID3v2_2: {"TSS"}, ID3v2_3: {"TSSE"}, ID3v2_4: {"TSSE"},
+		MP4: {"\xa9too"}, VORBIS: {"encoder"}, APEv2: {"encoder"},
+	},
+	FieldEncodedBy: {
+		ID3v2_2: {"TEN"}, ID3v2_3: {"TENC"}, ID3v2_4: {"TENC"},
+		MP4:    {"----:com.apple.iTunes:ENCODEDBY", "----:com.apple.iTunes:ENCODED_BY"},
+		VORBIS: {"encodedby", "encoded-by"}, APEv2: {"encodedby"},
+	},
+	FieldGrouping: {
+		ID3v2_2: {"TT1"}, ID3v2_3: {"TIT1"}, ID3v2_4: {"TIT1"},
+		MP4:    {"\xa9grp", "----:com.apple.iTunes:GROUPING"},
+		VORBIS: {"grouping", "contentgroup"}, APEv2: {"grouping"},
+	},
+	FieldConductor: {
+		ID3v2_2: {"TP3"}, ID3v2_3: {"TPE3"}, ID3v2_4: {"TPE3"},
+		MP4: {"----:com.apple.iTunes:CONDUCTOR"}, VORBIS: {"conductor"}, APEv2: {"conductor"},
+	},
+	FieldLyricist: {
+		ID3v2_2: {"TXT"}, ID3v2_3: {"TEXT"}, ID3v2_4: {"TEXT"},
+		MP4: {"----:com.apple.iTunes:LYRICIST"}, VORBIS: {"lyricist"}, APEv2: {"lyricist"},
+	},
+	FieldMood: {
+		ID3v2_2: {"TXX:MOOD"}, ID3v2_3: {"TMOO", "TXXX:MOOD"}, ID3v2_4: {"TMOO", "TXXX:MOOD"},
+		MP4: {"----:com.apple.iTunes:MOOD"}, VORBIS: {"mood"}, APEv2: {"mood"},
+	},
+	FieldCompilation: {
+		ID3v2_2: {"TCP", "TXX:COMPILATION"}, ID3v2_3: {"TCMP", "TXXX:COMPILATION"},
+		ID3v2_4: {"TCMP", "TXXX:COMPILATION"},
+		MP4:     {"cpil"}, VORBIS: {"compilation"}, APEv2: {"compilation"},
+	},
+	FieldRating: {
+		ID3v2_2: {"TXX:RATING"}, ID3v2_3: {"TXXX:RATING"}, ID3v2_4: {"TXXX:RATING"},
+		MP4: {"rate", "----:com.apple.iTunes:RATING"}, VORBIS: {"rating"}, APEv2: {"rating"},
+	},
+	FieldKeywords: {
+		MP4: {"keyw"},
+	},
+
+	// See https://picard-docs.musicbrainz.org/en/appendices/tag_mapping.html
+	FieldMusicBrainzRecordingID:    mbzKeys("MusicBrainz Track Id", "musicbrainz_trackid"),
+	FieldMusicBrainzTrackID:        mbzKeys("MusicBrainz Release Track Id", "musicbrainz_releasetrackid"),
+	FieldMusicBrainzAlbumID:        mbzKeys("MusicBrainz Album Id", "musicbrainz_albumid"),
+	FieldMusicBrainzArtistID:       mbzKeys("MusicBrainz Artist Id", "musicbrainz_artistid"),
+	FieldMusicBrainzAlbumArtistID:  mbzKeys("MusicBrainz Album Artist Id", "musicbrainz_albumartistid"),
+	FieldMusicBrainzReleaseGroupID: mbzKeys("MusicBrainz Release Group Id", "musicbrainz_releasegroupid"),
+	FieldMusicBrainzDiscID:         mbzKeys("MusicBrainz Disc Id", "musicbrainz_discid"),
+	FieldAcoustID:                  mbzKeys("Acoustid Id", "acoustid_id"),
+}
+
+// mbzKeys returns the native keys of a MusicBrainz Picard field, which uses TXXX frames and
+// freeform atoms named desc, and Vorbis and APE fields named name.  The recording ID is
+// stored in a UFID frame instead.
+func mbzKeys(desc, name string) map[Format][]string {
+	if name == "musicbrainz_trackid" {
+		return map[Format][]string{
+			ID3v2_2: {"UFI:http://musicbrainz.org"},
+			ID3v2_3: {"UFID:http://musicbrainz.org"},
+			ID3v2_4: {"UFID:http://musicbrainz.org"},
+			MP4:     {"----:com.apple.iTunes:" + desc},
+			VORBIS:  {name}, APEv2: {name},
+		}
+	}
+	return map[Format][]string{
+		ID3v2_2: {"TXX:" + desc}, ID3v2_3: {"TXXX:" + desc}, ID3v2_4: {"TXXX:" + desc},
+		MP4:    {"----:com.apple.iTunes:" + desc},
+		VORBIS: {name}, APEv2: {name},
+	}
+}
+
+// Fields returns all canonical fields, sorted by name.
+func Fields() []Field {
+	fields := make([]Field, 0, len(fieldKeys))
+	for f := range fieldKeys {
+		fields = append(fields, f)
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i] < fields[j] })
+	return fields
+}
+
+// NativeKeys returns the keys used for the field by the format, in order of preference (the
+// first is the one usually written).  Keys are as returned by Metadata.Raw (MP4 freeform atoms
+// being "----:mean:name"), except that ID3v2 TXXX frames are given as "TXXX:DESCRIPTION" (TXX
+// for ID3v2.2).  Returns nil if the format has no key for the field.
+func NativeKeys(f Field, format Format) []string {
+	keys := fieldKeys[f][format]
+	if len(keys) == 0 {
+		return nil
+	}
+	return append([]string(nil), keys...)
+}
+
+// fieldOrder is the order in which FieldFor tries the fields, so that a key registered for
+// more than one field always gives the same field.
+var fieldOrder = Fields()
+
+// FieldFor returns the canonical field for a native key of the format, as given by
+// NativeKeys.  Keys are matched case-insensitively for ID3v1, Vorbis, APEv2, TXXX
+// descriptions and freeform atom names.
+func FieldFor(format Format, key string) (Field, bool) {
+	for _, f := range fieldOrder {
+		for _, k := range fieldKeys[f][format] {
+			if matchNativeKey(format, k, key) {
+				return f, true
+			}
+		}
+	}
+	return "", false
+}
+
+// matchNativeKey reports whether key matches the registered native key k.
+func matchNativeKey(format Format, k, key string) bool {
+	if k == key {
+		return true
+	}
+	switch format {
+	case ID3v1, VORBIS, APEv2:
+		return strings.EqualFold(k, key)
+	case ID3v2_2, ID3v2_3, ID3v2_4:
+		name, desc, ok := strings.Cut(k, ":")
+		name2, desc2, ok2 := strings.Cut(key, ":")
+		return ok && ok2 && name == name2 && strings.EqualFold(desc, desc2)
+	case MP4:
+		i, j := strings.LastIndexByte(k, ':'), strings.LastIndexByte(key, ':')
+		return i >= 0 && j >= 0 && k[:i] == key[:j] && strings.EqualFold(k[i+1:], key[j+1:])
+	}
+	return false
+}
+
+// getField returns the text value of the field from the raw tags of the format, trying each
+// native key in turn.
+func getField(format Format, raw map[string]interface{}, f Field) string {
+	for _, k := range fieldKeys[f][format] {
+		if s := getNativeKey(format, raw, k); s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// getNativeKey returns the text value of the native key k (see NativeKeys) from raw tags.
+func getNativeKey(format Format, raw map[string]interface{}, k string) string {
+	switch {
+	case strings.HasPrefix(k, "UFI") && strings.Contains(k, ":"):
+		name, owner, _ := strings.Cut(k, ":")
+		for key, v := range raw {
+			if u, ok := v.(*UFID); ok && strings.HasPrefix(key, name) && u.Provider == owner {
+				return string(u.Identifier)
+			}
+		}
+		return ""
+
+	case strings.HasPrefix(k, "TXX") && strings.Contains(k, ":"):
+		name, desc, _ := strings.Cut(k, ":")
+		for key, v := range raw {
+			if c, ok := v.(*Comm); ok && strings.HasPrefix(key, name) && strings.EqualFold(c.Description, desc) {
+				return c.Text
+			}
+		}
+		return ""
+
+	case format == MP4 && strings.HasPrefix(k, "----:"):
+		if v, ok := raw[k]; ok {
+			return textValue(v)
+		}
+		for key, v := range raw {
+			if matchNativeKey(MP4, k, key) {
+				return textValue(v)
+			}
+		}
+		return ""
+	}
+	return textValue(raw[k])
+}
+
+// textValue returns the text of a raw tag value, or "" if it isn't text (e.g. a picture).
+func textValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []string:
+		if len(v) > 0 {
+			return v[0]
+		}
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case *Comm:
+		return v.Text
+	}
+	return ""
+}
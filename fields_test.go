@@ -0,0 +1,160 @@
+package tag
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestExtendedFields(t *testing.T) {
+	b := id3v2FramesTag(3,
+		id3v23Frame("TSOP", []byte("\x00Beatles, The")),
+		id3v23Frame("TBPM", []byte("\x00120")),
+		id3v23Frame("TSRC", []byte("\x00GBAYE0601498")),
+		id3v23Frame("TXXX", []byte("\x00CATALOGNUMBER\x00PCS 7088")),
+		id3v23Frame("TCMP", []byte("\x001")),
+	)
+	id3, err := ReadID3v2Tags(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("ReadID3v2Tags() = %v", err)
+	}
+
+	vorbis := newMetadataVorbis()
+	vorbis.c["artistsort"] = "Beatles, The"
+	vorbis.c["bpm"] = "119.6"
+	vorbis.c["isrc"] = "GBAYE0601498"
+	vorbis.c["catalognumber"] = "PCS 7088"
+	vorbis.c["compilation"] = "1"
+
+	mp4 := metadataMP4{data: map[string]interface{}{
+		"soar":                                "Beatles, The",
+		"tmpo":                                120,
+		"----:com.apple.iTunes:ISRC":          "GBAYE0601498",
+		"----:com.apple.iTunes:CATALOGNUMBER": "PCS 7088",
+		"cpil":                                1,
+	}}
+
+	for _, m := range []Metadata{&metadataV2MP3{metadataID3v2: id3}, &metadataOGG{metadataVorbis: vorbis}, mp4} {
+		if got := m.ArtistSort(); got != "Beatles, The" {
+			t.Errorf("%v: ArtistSort() = %q, expected %q", m.Format(), got, "Beatles, The")
+		}
+		if got := m.BPM(); got != 120 {
+			t.Errorf("%v: BPM() = %v, expected 120", m.Format(), got)
+		}
+		if got := m.ISRC(); got != "GBAYE0601498" {
+			t.Errorf("%v: ISRC() = %q, expected %q", m.Format(), got, "GBAYE0601498")
+		}
+		if got := m.CatalogNumber(); got != "PCS 7088" {
+			t.Errorf("%v: CatalogNumber() = %q, expected %q", m.Format(), got, "PCS 7088")
+		}
+		if !m.Compilation() {
+			t.Errorf("%v: Compilation() = false, expected true", m.Format())
+		}
+	}
+}
+
+func TestFieldRegistry(t *testing.T) {
+	formats := []Format{ID3v1, ID3v2_2, ID3v2_3, ID3v2_4, MP4, VORBIS, APEv2}
+	for _, f := range Fields() {
+		for _, format := range formats {
+			for _, k := range NativeKeys(f, format) {
+				if k == "" {
+					t.Errorf("NativeKeys(%q, %v) contains an empty key", f, format)
+				}
+				if got, ok := FieldFor(format, k); !ok || got != f {
+					t.Errorf("FieldFor(%v, %q) = %q, %v, expected %q, true", format, k, got, ok, f)
+				}
+			}
+		}
+	}
+	tests := []struct {
+		format Format
+		key    string
+		field  Field
+	}{
+		{ID3v2_3, "TIT2", FieldTitle},
+		{ID3v2_2, "TT2", FieldTitle},
+		{ID3v2_4, "TDRC", FieldDate},
+		{ID3v2_3, "TXXX:catalognumber", FieldCatalogNumber},
+		{MP4, "\xa9nam", FieldTitle},
+		{MP4, "----:com.apple.iTunes:isrc", FieldISRC},
+		{VORBIS, "ALBUMARTIST", FieldAlbumArtist},
+		{APEv2, "Album Artist", FieldAlbumArtist},
+	}
+	for _, tt := range tests {
+		if got, ok := FieldFor(tt.format, tt.key); !ok || got != tt.field {
+			t.Errorf("FieldFor(%v, %q) = %q, %v, expected %q, true", tt.format, tt.key, got, ok, tt.field)
+		}
+	}
+	if got, ok := FieldFor(ID3v2_3, "TIT3"); ok {
+		t.Errorf("FieldFor(ID3v2.3, %q) = %q, expected no field", "TIT3", got)
+	}
+
+	keys := NativeKeys(FieldTitle, MP4)
+	keys[0] = "x"
+	if NativeKeys(FieldTitle, MP4)[0] != "\xa9nam" {
+		t.Errorf("NativeKeys returned the registry slice")
+	}
+}
+
+func TestGet(t *testing.T) {
+	b := id3v2FramesTag(3,
+		id3v23Frame("TIT2", []byte("\x00Help!")),
+		id3v23Frame("TRCK", []byte("\x003/14")),
+		id3v23Frame("TXXX", []byte("\x00CATALOGNUMBER\x00PCS 3071")),
+		id3v23Frame("COMM", []byte("\x00eng\x00Remastered")),
+	)
+	id3, err := ReadID3v2Tags(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("ReadID3v2Tags() = %v", err)
+	}
+
+	vorbis := newMetadataVorbis()
+	vorbis.c["title"] = "Help!"
+	vorbis.c["tracknumber"] = "3"
+	vorbis.c["catalognumber"] = "PCS 3071"
+	vorbis.c["description"] = "Remastered"
+
+	mp4 := metadataMP4{data: map[string]interface{}{
+		"\xa9nam":                             "Help!",
+		"trkn":                                3,
+		"----:com.apple.iTunes:CATALOGNUMBER": "PCS 3071",
+		"\xa9cmt":                             "Remastered",
+		"covr":                                &Picture{},
+	}}
+
+	ape := &metadataAPE{items: map[string]interface{}{
+		"title":         "Help!",
+		"track":         "3",
+		"catalognumber": "PCS 3071",
+		"comment":       "Remastered",
+	}}
+
+	for _, m := range []Metadata{&metadataV2MP3{metadataID3v2: id3}, &metadataOGG{metadataVorbis: vorbis}, mp4, ape} {
+		if got := m.Get(FieldTitle); got != "Help!" {
+			t.Errorf("%v: Get(FieldTitle) = %q, expected %q", m.Format(), got, "Help!")
+		}
+		if got := m.Get(FieldTrack); got != "3" && got != "3/14" {
+			t.Errorf("%v: Get(FieldTrack) = %q, expected %q", m.Format(), got, "3")
+		}
+		if got := m.Get(FieldCatalogNumber); got != "PCS 3071" {
+			t.Errorf("%v: Get(FieldCatalogNumber) = %q, expected %q", m.Format(), got, "PCS 3071")
+		}
+		if got := m.Get(FieldComment); got != "Remastered" {
+			t.Errorf("%v: Get(FieldComment) = %q, expected %q", m.Format(), got, "Remastered")
+		}
+		if got := m.Get(FieldPicture); got != "" {
+			t.Errorf("%v: Get(FieldPicture) = %q, expected no text", m.Format(), got)
+		}
+	}
+
+	merged := metadataMerged{ape, mp4}
+	if got := merged.Get(FieldCatalogNumber); got != "PCS 3071" {
+		t.Errorf("merged Get(FieldCatalogNumber) = %q, expected %q", got, "PCS 3071")
+	}
+
+	// A multi-value item without values has no text.
+	mp4.data["\xa9ART"] = []string{}
+	if got := mp4.Get(FieldArtist); got != "" {
+		t.Errorf("Get(FieldArtist) = %q, expected no text", got)
+	}
+}
@@ -5,7 +5,8 @@
 package tag
 
 import (
-	"errors"
+	"bytes"
+	"encoding/binary"
 	"fmt"
 	"io"
 	"time"
@@ -17,23 +18,36 @@ type blockType byte
 // FLAC block types.
 const (
 	// Padding Block               1
-	// Application Block           2
-	// Seektable Block             3
-	// Cue Sheet Block             5
 	streamInfoBlock    blockType = 0
+	applicationBlock   blockType = 2
+	seekTableBlock     blockType = 3
 	vorbisCommentBlock blockType = 4
+	cueSheetBlock      blockType = 5
 	pictureBlock       blockType = 6
 )
 
+var blockTypeNames = [...]string{
+	"STREAMINFO", "PADDING", "APPLICATION", "SEEKTABLE", "VORBIS_COMMENT", "CUESHEET", "PICTURE",
+}
+
+func (t blockType) String() string {
+	if int(t) < len(blockTypeNames) {
+		return blockTypeNames[t]
+	}
+	return fmt.Sprintf("block type %d", byte(t))
+}
+
 // ReadFLACMeta reads FLAC metadata from the io.ReadSeeker, returning the resulting
 // metadata in a Metadata implementation, or non-nil error if there was a problem.
-func ReadFLACMeta(r io.ReadSeeker) (Metadata, error) {
+func ReadFLACMeta(r io.ReadSeeker, opts ...ReadOption) (Metadata, error) {
+	o := newReadOptions(opts)
+	start := tell(r)
 	flac, err := readString(r, 4)
 	if err != nil {
-		return nil, err
+		return nil, newParseError(string(FLAC), start, "", err)
 	}
 	if flac != "fLaC" {
-		return nil, errors.New("expected 'fLaC'")
+		return nil, newParseError(string(FLAC), start, "", fmt.Errorf("%w: expected 'fLaC'", ErrMalformed))
 	}
 
 	m := &metadataFLAC{
@@ -41,7 +55,7 @@ func ReadFLACMeta(r io.ReadSeeker) (Metadata, error) {
 	}
 
 	for {
-		last, err := m.readFLACBlock(r)
+		last, err := m.readFLACBlock(r, o)
 		if err != nil {
 			return nil, err
 		}
@@ -55,13 +69,21 @@ func ReadFLACMeta(r io.ReadSeeker) (Metadata, error) {
 
 type metadataFLAC struct {
 	*metadataVorbis
-	duration time.Duration
+	duration     time.Duration
+	audio        *Audio
+	streamInfo   *FLACStreamInfo
+	seekTable    []FLACSeekPoint
+	cueSheet     *FLACCueSheet
+	applications []FLACApplication
 }
 
-func (m *metadataFLAC) readFLACBlock(r io.ReadSeeker) (last bool, err error) {
+// readFLACBlock reads a metadata block.  If lenient parsing is enabled, blocks which cannot be
+// read are skipped, and unreadable block headers end the read.
+func (m *metadataFLAC) readFLACBlock(r io.ReadSeeker, o *readOptions) (last bool, err error) {
+	offset := tell(r)
 	blockHeader, err := readBytes(r, 1)
 	if err != nil {
-		return
+		return true, o.warn(newParseError(string(FLAC), offset, "", err))
 	}
 
 	if getBit(blockHeader[0], 7) {
@@ -69,14 +91,21 @@ func (m *metadataFLAC) readFLACBlock(r io.ReadSeeker) (last bool, err error) {
 		last = true
 	}
 
+	blockError := func(err error) error {
+		return o.warn(newParseError(string(FLAC), offset, blockType(blockHeader[0]).String(), err))
+	}
+
 	blockLen, err := readInt(r, 3)
 	if err != nil {
-		return
+		return true, blockError(err)
 	}
 
+	// Blocks which don't hold tags are skipped if they can't be read, even when not lenient.
+	optional := false
+
 	switch blockType(blockHeader[0]) {
 	case vorbisCommentBlock:
-		err = m.readVorbisComment(r)
+		err = m.readVorbisComment(r, blockError)
 
 	case pictureBlock:
 		err = m.readPictureBlock(r)
@@ -84,30 +113,225 @@ func (m *metadataFLAC) readFLACBlock(r io.ReadSeeker) (last bool, err error) {
 	case streamInfoBlock:
 		err = m.readStreamingInfoBlock(r, blockLen)
 
+	case seekTableBlock:
+		err = m.readSeekTableBlock(r, blockLen)
+		optional = true
+
+	case cueSheetBlock:
+		err = m.readCueSheetBlock(r, blockLen)
+		optional = true
+
+	case applicationBlock:
+		err = m.readApplicationBlock(r, blockLen)
+		optional = true
+
 	default:
 		_, err = r.Seek(int64(blockLen), io.SeekCurrent)
 	}
+
+	if err != nil {
+		if err = blockError(err); err != nil && !optional {
+			return
+		}
+		// Skip the rest of the bad block.
+		_, err = r.Seek(offset+4+int64(blockLen), io.SeekStart)
+	}
 	return
 }
 
-func (m *metadataFLAC) readStreamingInfoBlock(r io.Reader, len int) error {
-	data := make([]byte, len)
-	if _, err := r.Read(data); err != nil {
+// FLACMetadata is implemented by the Metadata returned for FLAC files, giving the contents of
+// the metadata blocks other than tags and pictures.
+type FLACMetadata interface {
+	Metadata
+
+	// StreamInfo returns the STREAMINFO block, or nil if it was not read.
+	StreamInfo() *FLACStreamInfo
+
+	// SeekTable returns the seek points of the SEEKTABLE block (without placeholders), or nil
+	// if there is none.
+	SeekTable() []FLACSeekPoint
+
+	// CueSheet returns the CUESHEET block, or nil if there is none.
+	CueSheet() *FLACCueSheet
+
+	// Applications returns the APPLICATION blocks.
+	Applications() []FLACApplication
+}
+
+// FLACStreamInfo is the STREAMINFO block of a FLAC file.
+type FLACStreamInfo struct {
+	MinBlockSize  int // Minimum block size, in samples.
+	MaxBlockSize  int // Maximum block size, in samples.
+	MinFrameSize  int // Minimum frame size in bytes, or 0 if unknown.
+	MaxFrameSize  int // Maximum frame size in bytes, or 0 if unknown.
+	SampleRate    int
+	Channels      int
+	BitsPerSample int
+	TotalSamples  int64    // Total samples (per channel), or 0 if unknown.
+	MD5           [16]byte // MD5 of the decoded audio, or zero if unknown.
+}
+
+// FLACSeekPoint is a point in a FLAC SEEKTABLE block.
+type FLACSeekPoint struct {
+	SampleNumber uint64 // First sample of the target frame.
+	Offset       uint64 // Offset of the target frame from the first frame, in bytes.
+	FrameSamples int    // Samples in the target frame.
+}
+
+// FLACCueSheet is the CUESHEET block of a FLAC file.  Offsets are in samples.
+type FLACCueSheet struct {
+	CatalogNumber string // Media catalog number (e.g. the UPC/EAN of a CD).
+	LeadInSamples uint64 // Lead-in of a CD, in samples.
+	CD            bool   // The cue sheet corresponds to a CD.
+	Tracks        []FLACCueSheetTrack
+}
+
+// FLACCueSheetTrack is a track of a FLAC cue sheet.  The last track is the lead-out (number 170
+// for CDs, 255 otherwise).
+type FLACCueSheetTrack struct {
+	Offset      uint64 // Offset of the track from the start of the audio, in samples.
+	Number      int
+	ISRC        string
+	Audio       bool // The track is audio (rather than data).
+	PreEmphasis bool
+	Indexes     []FLACCueSheetIndex
+}
+
+// FLACCueSheetIndex is an index point of a FLAC cue sheet track.
+type FLACCueSheetIndex struct {
+	Offset uint64 // Offset of the index from the start of the track, in samples.
+	Number int
+}
+
+// FLACApplication is an APPLICATION block of a FLAC file.
+type FLACApplication struct {
+	ID   string // Registered application ID (4 bytes).
+	Data []byte
+}
+
+func (m *metadataFLAC) readStreamingInfoBlock(r io.Reader, size int) error {
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
 		return err
 	}
 
-	sampleRate, err := cutBits(data, 80, 20)
-	if err != nil {
-		return fmt.Errorf("reading sample rate: %w", err)
+	// minimum and maximum block size (16 bits each), minimum and maximum frame size (24 bits
+	// each), sample rate (20 bits), channels - 1 (3 bits), bits per sample - 1 (5 bits), total
+	// samples (36 bits), MD5 (128 bits)
+	if size < 34 {
+		return fmt.Errorf("%w: expected 34 bytes of STREAMINFO, got %d", ErrTruncated, size)
+	}
+	field := func(offset, n uint) int {
+		x, _ := cutBits(data, offset, n)
+		return int(x)
+	}
+	info := &FLACStreamInfo{
+		MinBlockSize:  field(0, 16),
+		MaxBlockSize:  field(16, 16),
+		MinFrameSize:  field(32, 24),
+		MaxFrameSize:  field(56, 24),
+		SampleRate:    field(80, 20),
+		Channels:      field(100, 3) + 1,
+		BitsPerSample: field(103, 5) + 1,
+		TotalSamples:  int64(field(108, 36)),
 	}
+	copy(info.MD5[:], data[18:34])
+	m.streamInfo = info
 
-	sampleNum, err := cutBits(data, 108, 36)
+	m.audio = &Audio{
+		Codec:      CodecFLAC,
+		SampleRate: info.SampleRate,
+		Channels:   info.Channels,
+		BitDepth:   info.BitsPerSample,
+		Samples:    info.TotalSamples,
+	}
+
+	m.duration = samplesDuration(uint64(info.TotalSamples), uint32(info.SampleRate))
+
+	return nil
+}
+
+func (m *metadataFLAC) readSeekTableBlock(r io.Reader, size int) error {
+	b, err := readBytes(r, uint(size))
 	if err != nil {
-		return fmt.Errorf("reading sample number: %w", err)
+		return err
+	}
+	// sample number (8 bytes), offset (8 bytes), frame samples (2 bytes)
+	if size%18 != 0 {
+		return fmt.Errorf("%w: SEEKTABLE length %d is not a multiple of 18", ErrMalformed, size)
+	}
+	m.seekTable = make([]FLACSeekPoint, 0, size/18)
+	for ; len(b) > 0; b = b[18:] {
+		p := FLACSeekPoint{
+			SampleNumber: binary.BigEndian.Uint64(b),
+			Offset:       binary.BigEndian.Uint64(b[8:]),
+			FrameSamples: int(binary.BigEndian.Uint16(b[16:])),
+		}
+		if p.SampleNumber != flacPlaceholderSeekPoint {
+			m.seekTable = append(m.seekTable, p)
+		}
 	}
+	return nil
+}
 
-	m.duration = time.Second * (time.Duration(sampleNum) / time.Duration(sampleRate))
+// flacPlaceholderSeekPoint is the sample number of placeholder seek points.
+const flacPlaceholderSeekPoint = 1<<64 - 1
 
+func (m *metadataFLAC) readCueSheetBlock(r io.Reader, size int) error {
+	b, err := readBytes(r, uint(size))
+	if err != nil {
+		return err
+	}
+	// catalog number (128 bytes), lead-in samples (8 bytes), CD flag (1 bit), reserved
+	// (7 bits + 258 bytes), number of tracks (1 byte)
+	if size < 396 {
+		return fmt.Errorf("%w: CUESHEET", ErrTruncated)
+	}
+	cs := &FLACCueSheet{
+		CatalogNumber: string(bytes.TrimRight(b[:128], "\x00")),
+		LeadInSamples: binary.BigEndian.Uint64(b[128:]),
+		CD:            b[136]&0x80 != 0,
+	}
+	n := int(b[395])
+	b = b[396:]
+	for i := 0; i < n; i++ {
+		// offset (8 bytes), number (1 byte), ISRC (12 bytes), type (1 bit), pre-emphasis
+		// (1 bit), reserved (6 bits + 13 bytes), number of indexes (1 byte), then the indexes:
+		// offset (8 bytes), number (1 byte), reserved (3 bytes)
+		if len(b) < 36 || len(b) < 36+12*int(b[35]) {
+			return fmt.Errorf("%w: CUESHEET track", ErrTruncated)
+		}
+		t := FLACCueSheetTrack{
+			Offset:      binary.BigEndian.Uint64(b),
+			Number:      int(b[8]),
+			ISRC:        string(bytes.TrimRight(b[9:21], "\x00")),
+			Audio:       b[21]&0x80 == 0,
+			PreEmphasis: b[21]&0x40 != 0,
+		}
+		indexes := int(b[35])
+		b = b[36:]
+		for j := 0; j < indexes; j++ {
+			t.Indexes = append(t.Indexes, FLACCueSheetIndex{
+				Offset: binary.BigEndian.Uint64(b),
+				Number: int(b[8]),
+			})
+			b = b[12:]
+		}
+		cs.Tracks = append(cs.Tracks, t)
+	}
+	m.cueSheet = cs
+	return nil
+}
+
+func (m *metadataFLAC) readApplicationBlock(r io.Reader, size int) error {
+	b, err := readBytes(r, uint(size))
+	if err != nil {
+		return err
+	}
+	if size < 4 {
+		return fmt.Errorf("%w: APPLICATION", ErrTruncated)
+	}
+	m.applications = append(m.applications, FLACApplication{ID: string(b[:4]), Data: b[4:]})
 	return nil
 }
 
@@ -115,6 +339,18 @@ func (m *metadataFLAC) FileType() FileType {
 	return FLAC
 }
 
+func (m *metadataFLAC) Audio() *Audio {
+	return m.audio
+}
+
 func (m *metadataFLAC) Duration() time.Duration {
 	return m.duration
 }
+
+func (m *metadataFLAC) StreamInfo() *FLACStreamInfo { return m.streamInfo }
+
+func (m *metadataFLAC) SeekTable() []FLACSeekPoint { return m.seekTable }
+
+func (m *metadataFLAC) CueSheet() *FLACCueSheet { return m.cueSheet }
+
+func (m *metadataFLAC) Applications() []FLACApplication { return m.applications }
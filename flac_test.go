@@ -0,0 +1,171 @@
+package tag
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// flacBlock builds a FLAC metadata block.
+func flacBlock(t blockType, last bool, body ...[]byte) []byte {
+	b := bytes.Join(body, nil)
+	h := byte(t)
+	if last {
+		h |= 0x80
+	}
+	return append([]byte{h, byte(len(b) >> 16), byte(len(b) >> 8), byte(len(b))}, b...)
+}
+
+func u64s(x ...uint64) []byte {
+	var b []byte
+	for _, v := range x {
+		b = binary.BigEndian.AppendUint64(b, v)
+	}
+	return b
+}
+
+func u32le(x uint32) []byte {
+	return binary.LittleEndian.AppendUint32(nil, x)
+}
+
+// fixedString returns s padded with zeros to n bytes.
+func fixedString(s string, n int) []byte {
+	return append([]byte(s), make([]byte, n-len(s))...)
+}
+
+func TestFLACBlocks(t *testing.T) {
+	// 4096/4096 block size, 14/9000 frame size, 44100 Hz, 2 channels, 16 bits, 88200 samples
+	streamInfo := []byte{0x10, 0x00, 0x10, 0x00, 0x00, 0x00, 0x0e, 0x00, 0x23, 0x28, 0x0a, 0xc4, 0x42, 0xf0, 0x00, 0x01, 0x58, 0x88}
+	md5 := []byte("0123456789abcdef")
+
+	seekTable := bytes.Join([][]byte{
+		u64s(0, 0), {0x10, 0x00},
+		u64s(44100, 8192), {0x10, 0x00},
+		u64s(1<<64-1, 0), {0x00, 0x00}, // placeholder
+	}, nil)
+
+	cueTrack := func(offset uint64, n byte, isrc string, flags byte, indexes ...[]byte) []byte {
+		return bytes.Join([][]byte{u64s(offset), {n}, fixedString(isrc, 12), {flags}, make([]byte, 13), {byte(len(indexes))}, bytes.Join(indexes, nil)}, nil)
+	}
+	cueIndex := func(offset uint64, n byte) []byte {
+		return append(u64s(offset), n, 0, 0, 0)
+	}
+	cueSheet := bytes.Join([][]byte{
+		fixedString("0123456789012", 128), u64s(88200), {0x80}, make([]byte, 258), {3},
+		cueTrack(0, 1, "USABC0000001", 0, cueIndex(0, 1)),
+		cueTrack(44100, 2, "", 0x40, cueIndex(0, 0), cueIndex(588, 1)),
+		cueTrack(88200, 170, "", 0),
+	}, nil)
+
+	b := bytes.Join([][]byte{
+		[]byte("fLaC"),
+		flacBlock(streamInfoBlock, false, streamInfo, md5),
+		flacBlock(seekTableBlock, false, seekTable),
+		flacBlock(cueSheetBlock, false, cueSheet),
+		flacBlock(applicationBlock, false, []byte("riff"), []byte("payload")),
+		flacBlock(vorbisCommentBlock, true, u32le(6), []byte("vendor"), u32le(1), u32le(9), []byte("TITLE=Foo")),
+	}, nil)
+
+	m, err := ReadFLACMeta(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("ReadFLACMeta() = %v", err)
+	}
+	if got := m.Title(); got != "Foo" {
+		t.Errorf("Title() = %q, expected %q", got, "Foo")
+	}
+	f, ok := m.(FLACMetadata)
+	if !ok {
+		t.Fatalf("ReadFLACMeta() returned %T, which does not implement FLACMetadata", m)
+	}
+
+	wantInfo := &FLACStreamInfo{
+		MinBlockSize:  4096,
+		MaxBlockSize:  4096,
+		MinFrameSize:  14,
+		MaxFrameSize:  9000,
+		SampleRate:    44100,
+		Channels:      2,
+		BitsPerSample: 16,
+		TotalSamples:  88200,
+	}
+	copy(wantInfo.MD5[:], md5)
+	if got := f.StreamInfo(); !reflect.DeepEqual(got, wantInfo) {
+		t.Errorf("StreamInfo() = %+v, expected %+v", got, wantInfo)
+	}
+
+	wantSeekTable := []FLACSeekPoint{
+		{SampleNumber: 0, Offset: 0, FrameSamples: 4096},
+		{SampleNumber: 44100, Offset: 8192, FrameSamples: 4096},
+	}
+	if got := f.SeekTable(); !reflect.DeepEqual(got, wantSeekTable) {
+		t.Errorf("SeekTable() = %+v, expected %+v", got, wantSeekTable)
+	}
+
+	wantCueSheet := &FLACCueSheet{
+		CatalogNumber: "0123456789012",
+		LeadInSamples: 88200,
+		CD:            true,
+		Tracks: []FLACCueSheetTrack{
+			{Offset: 0, Number: 1, ISRC: "USABC0000001", Audio: true, Indexes: []FLACCueSheetIndex{{0, 1}}},
+			{Offset: 44100, Number: 2, Audio: true, PreEmphasis: true, Indexes: []FLACCueSheetIndex{{0, 0}, {588, 1}}},
+			{Offset: 88200, Number: 170, Audio: true},
+		},
+	}
+	if got := f.CueSheet(); !reflect.DeepEqual(got, wantCueSheet) {
+		t.Errorf("CueSheet() = %+v, expected %+v", got, wantCueSheet)
+	}
+
+	wantApplications := []FLACApplication{{ID: "riff", Data: []byte("payload")}}
+	if got := f.Applications(); !reflect.DeepEqual(got, wantApplications) {
+		t.Errorf("Applications() = %+v, expected %+v", got, wantApplications)
+	}
+}
+
+func TestFLACBadOptionalBlocks(t *testing.T) {
+	b := bytes.Join([][]byte{
+		[]byte("fLaC"),
+		flacBlock(seekTableBlock, false, make([]byte, 17)),
+		flacBlock(cueSheetBlock, false, make([]byte, 10)),
+		flacBlock(applicationBlock, false, []byte("ab")),
+		flacBlock(vorbisCommentBlock, true, u32le(6), []byte("vendor"), u32le(1), u32le(9), []byte("TITLE=Foo")),
+	}, nil)
+
+	m, err := ReadFLACMeta(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("ReadFLACMeta() = %v", err)
+	}
+	if got := m.Title(); got != "Foo" {
+		t.Errorf("Title() = %q, expected %q", got, "Foo")
+	}
+	f := m.(FLACMetadata)
+	if f.SeekTable() != nil || f.CueSheet() != nil || f.Applications() != nil {
+		t.Errorf("SeekTable(), CueSheet(), Applications() = %v, %v, %v, expected nil", f.SeekTable(), f.CueSheet(), f.Applications())
+	}
+
+	var warnings []error
+	if _, err := ReadFLACMeta(bytes.NewReader(b), Lenient(&warnings)); err != nil || len(warnings) != 3 {
+		t.Errorf("lenient ReadFLACMeta() = %v, warnings = %v", err, warnings)
+	}
+}
+
+func TestFLACStreamInfo(t *testing.T) {
+	f, err := os.Open("testdata/with_tags/sample.flac")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	m, err := ReadFLACMeta(f)
+	if err != nil {
+		t.Fatalf("ReadFLACMeta() = %v", err)
+	}
+	info := m.(FLACMetadata).StreamInfo()
+	if info == nil || info.SampleRate != 11025 || info.Channels != 1 || info.BitsPerSample != 16 || info.TotalSamples != 37478 {
+		t.Errorf("StreamInfo() = %+v", info)
+	}
+	if info != nil && info.MD5 == [16]byte{} {
+		t.Errorf("StreamInfo().MD5 is zero")
+	}
+}
@@ -0,0 +1,537 @@
+package tag
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"math/bits"
+)
+
+// VerifyFLAC decodes the audio of the FLAC file read from r, checking the CRC-8 of each frame
+// header, the CRC-16 of each frame and the MD5 of the decoded audio given by STREAMINFO (if
+// it is set).  Returns a *ParseError of kind ErrBadChecksum if a checksum does not match, or
+// of another kind if the audio cannot be decoded.
+func VerifyFLAC(r io.Reader) error {
+	cr := &countingReader{r: r}
+	br := &flacBitReader{r: bufio.NewReader(cr)}
+	fail := func(offset int64, name string, err error) error {
+		return newParseError(string(FLAC), offset, name, err)
+	}
+
+	magic := make([]byte, 4)
+	if _, err := io.ReadFull(br.r, magic); err != nil {
+		return fail(0, "", err)
+	}
+	if string(magic) != "fLaC" {
+		return fail(0, "", fmt.Errorf("%w: expected 'fLaC'", ErrMalformed))
+	}
+
+	m := &metadataFLAC{}
+	for last := false; !last; {
+		offset := br.offset(cr)
+		h := make([]byte, 4)
+		if _, err := io.ReadFull(br.r, h); err != nil {
+			return fail(offset, "", err)
+		}
+		last = h[0]&0x80 != 0
+		t := blockType(h[0] & 0x7f)
+		size := int(h[1])<<16 | int(h[2])<<8 | int(h[3])
+		var err error
+		if t == streamInfoBlock {
+			err = m.readStreamingInfoBlock(br.r, size)
+		} else {
+			_, err = io.CopyN(io.Discard, br.r, int64(size))
+		}
+		if err != nil {
+			return fail(offset, t.String(), err)
+		}
+	}
+	info := m.streamInfo
+	if info == nil {
+		return fail(4, "STREAMINFO", fmt.Errorf("%w: missing STREAMINFO", ErrMalformed))
+	}
+
+	d := &flacDecoder{br: br, info: info, md5: md5.New()}
+	var samples int64
+	for {
+		offset := br.offset(cr)
+		if b, err := br.r.Peek(3); errors.Is(err, io.EOF) && len(b) == 0 || string(b) == "TAG" {
+			break // end of the audio, or a trailing ID3v1 tag
+		}
+		n, err := d.decodeFrame()
+		if err != nil {
+			return fail(offset, "frame", err)
+		}
+		samples += int64(n)
+	}
+
+	if info.TotalSamples > 0 && samples != info.TotalSamples {
+		return fail(br.offset(cr), "", fmt.Errorf("%w: decoded %d samples, expected %d", ErrTruncated, samples, info.TotalSamples))
+	}
+	if info.MD5 != [16]byte{} && !bytes.Equal(d.md5.Sum(nil), info.MD5[:]) {
+		return fail(br.offset(cr), "MD5", fmt.Errorf("%w: MD5 of the decoded audio does not match STREAMINFO", ErrBadChecksum))
+	}
+	return nil
+}
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(b []byte) (int, error) {
+	n, err := c.r.Read(b)
+	c.n += int64(n)
+	return n, err
+}
+
+// flacBitReader reads the bits of FLAC frames, computing the CRC-8 and CRC-16 of the bytes
+// read.
+type flacBitReader struct {
+	r     *bufio.Reader
+	cache uint64 // the low n bits are unread
+	n     uint
+	crc8  byte
+	crc16 uint16
+}
+
+// offset returns the offset of the next unread byte, where cr is the underlying reader.
+func (br *flacBitReader) offset(cr *countingReader) int64 {
+	return cr.n - int64(br.r.Buffered()) - int64(br.n/8)
+}
+
+// fill ensures that at least k (up to 56) bits are cached.
+func (br *flacBitReader) fill(k uint) error {
+	for br.n < k {
+		b, err := br.r.ReadByte()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = fmt.Errorf("%w: frame", ErrTruncated)
+			}
+			return err
+		}
+		br.crc8 = crc8Table[br.crc8^b]
+		br.crc16 = br.crc16<<8 ^ crc16Table[byte(br.crc16>>8)^b]
+		br.cache = br.cache<<8 | uint64(b)
+		br.n += 8
+	}
+	return nil
+}
+
+// read returns the next k (up to 32) bits.
+func (br *flacBitReader) read(k uint) (uint64, error) {
+	if k == 0 {
+		return 0, nil
+	}
+	if err := br.fill(k); err != nil {
+		return 0, err
+	}
+	br.n -= k
+	return br.cache >> br.n & (1<<k - 1), nil
+}
+
+// readSigned returns the next k (up to 32) bits as a two's complement integer.
+func (br *flacBitReader) readSigned(k uint) (int64, error) {
+	x, err := br.read(k)
+	if k == 0 || err != nil {
+		return 0, err
+	}
+	return int64(x<<(64-k)) >> (64 - k), nil
+}
+
+// unary returns the number of 0 bits before the next 1 bit.
+func (br *flacBitReader) unary() (uint, error) {
+	var q uint
+	for {
+		if br.n == 0 {
+			if err := br.fill(8); err != nil {
+				return 0, err
+			}
+		}
+		if x := br.cache & (1<<br.n - 1); x != 0 {
+			l := uint(bits.Len64(x))
+			q += br.n - l
+			br.n = l - 1
+			return q, nil
+		}
+		q += br.n
+		br.n = 0
+	}
+}
+
+// align skips to the next byte boundary.
+func (br *flacBitReader) align() {
+	br.n -= br.n % 8
+}
+
+// crc8Table and crc16Table are the tables of the CRC-8 (polynomial x^8 + x^2 + x + 1) and
+// CRC-16 (polynomial x^16 + x^15 + x^2 + 1) used by FLAC frames.
+var crc8Table, crc16Table = func() (t8 [256]byte, t16 [256]uint16) {
+	for i := range t8 {
+		c8, c16 := byte(i), uint16(i)<<8
+		for j := 0; j < 8; j++ {
+			c8 = c8<<1 ^ 0x07*(c8>>7)
+			c16 = c16<<1 ^ 0x8005*(c16>>15)
+		}
+		t8[i], t16[i] = c8, c16
+	}
+	return t8, t16
+}()
+
+// flacDecoder decodes FLAC frames.
+type flacDecoder struct {
+	br      *flacBitReader
+	info    *FLACStreamInfo
+	md5     hash.Hash
+	samples [8][]int32 // decoded samples of each channel
+	buf     []byte
+}
+
+var (
+	flacBlockSizes  = [16]int{0, 192, 576, 1152, 2304, 4608, -8, -16, 256, 512, 1024, 2048, 4096, 8192, 16384, 32768}
+	flacSampleSizes = [8]int{0, 8, 12, 0, 16, 20, 24, 32}
+)
+
+// decodeFrame decodes a frame, adding its audio to the MD5 and returning the number of
+// samples (per channel) decoded.
+func (d *flacDecoder) decodeFrame() (int, error) {
+	br := d.br
+	br.crc8, br.crc16 = 0, 0
+
+	// sync code (14 bits), reserved (1 bit), blocking strategy (1 bit), block size (4 bits),
+	// sample rate (4 bits), channel assignment (4 bits), sample size (3 bits), reserved (1 bit)
+	h, err := br.read(32)
+	if err != nil {
+		return 0, err
+	}
+	if h>>18 != 0x3ffe || h>>17&1 != 0 {
+		return 0, fmt.Errorf("%w: expected frame sync code", ErrMalformed)
+	}
+	blockSizeCode, sampleRateCode := h>>12&0xf, h>>8&0xf
+	channelCode, sampleSizeCode := h>>4&0xf, h>>1&0x7
+
+	// coded frame or sample number (1 to 7 bytes, UTF-8 style)
+	x, err := br.read(8)
+	if err != nil {
+		return 0, err
+	}
+	if n := bits.LeadingZeros8(^byte(x)); n > 0 {
+		if n == 1 || n > 7 {
+			return 0, fmt.Errorf("%w: frame number", ErrMalformed)
+		}
+		for i := 1; i < n; i++ {
+			if _, err := br.read(8); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	blockSize := flacBlockSizes[blockSizeCode]
+	if blockSize < 0 {
+		x, err := br.read(uint(-blockSize))
+		if err != nil {
+			return 0, err
+		}
+		blockSize = int(x) + 1
+	}
+	if blockSize == 0 {
+		return 0, fmt.Errorf("%w: reserved block size", ErrMalformed)
+	}
+	switch sampleRateCode {
+	case 12:
+		_, err = br.read(8)
+	case 13, 14:
+		_, err = br.read(16)
+	case 15:
+		err = fmt.Errorf("%w: invalid sample rate", ErrMalformed)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	bps := flacSampleSizes[sampleSizeCode]
+	if sampleSizeCode == 0 {
+		bps = d.info.BitsPerSample
+	} else if bps == 0 {
+		return 0, fmt.Errorf("%w: reserved sample size", ErrMalformed)
+	}
+	channels := int(channelCode) + 1
+	if channelCode > 10 {
+		return 0, fmt.Errorf("%w: reserved channel assignment", ErrMalformed)
+	} else if channelCode >= 8 {
+		channels = 2
+	}
+
+	crc8 := br.crc8
+	if x, err := br.read(8); err != nil {
+		return 0, err
+	} else if byte(x) != crc8 {
+		return 0, fmt.Errorf("%w: frame header CRC-8 %#02x does not match %#02x", ErrBadChecksum, crc8, x)
+	}
+
+	for ch := 0; ch < channels; ch++ {
+		// The side channel has an extra bit.
+		sideBits := 0
+		if (channelCode == 8 || channelCode == 10) && ch == 1 || channelCode == 9 && ch == 0 {
+			sideBits = 1
+		}
+		if cap(d.samples[ch]) < blockSize {
+			d.samples[ch] = make([]int32, blockSize)
+		}
+		d.samples[ch] = d.samples[ch][:blockSize]
+		if err := d.decodeSubframe(d.samples[ch], bps+sideBits); err != nil {
+			return 0, fmt.Errorf("subframe %d: %w", ch, err)
+		}
+	}
+
+	br.align()
+	crc16 := br.crc16
+	if x, err := br.read(16); err != nil {
+		return 0, err
+	} else if uint16(x) != crc16 {
+		return 0, fmt.Errorf("%w: frame CRC-16 %#04x does not match %#04x", ErrBadChecksum, crc16, x)
+	}
+
+	d.decorrelate(channelCode, blockSize)
+	d.writeMD5(channels, blockSize, bps)
+	return blockSize, nil
+}
+
+// decorrelate restores the left and right channels of stereo frames coded as left/side,
+// side/right or mid/side.
+func (d *flacDecoder) decorrelate(channelCode uint64, n int) {
+	a, b := d.samples[0], d.samples[1]
+	switch channelCode {
+	case 8: // left, side
+		for i := 0; i < n; i++ {
+			b[i] = a[i] - b[i]
+		}
+	case 9: // side, right
+		for i := 0; i < n; i++ {
+			a[i] += b[i]
+		}
+	case 10: // mid, side
+		for i := 0; i < n; i++ {
+			mid := int64(a[i])<<1 | int64(b[i])&1
+			a[i] = int32((mid + int64(b[i])) >> 1)
+			b[i] = int32((mid - int64(b[i])) >> 1)
+		}
+	}
+}
+
+// writeMD5 adds the interleaved samples to the MD5 as signed little-endian integers.
+func (d *flacDecoder) writeMD5(channels, n, bps int) {
+	bytesPerSample := (bps + 7) / 8
+	d.buf = d.buf[:0]
+	for i := 0; i < n; i++ {
+		for ch := 0; ch < channels; ch++ {
+			s := d.samples[ch][i]
+			for j := 0; j < bytesPerSample; j++ {
+				d.buf = append(d.buf, byte(s>>(8*j)))
+			}
+		}
+	}
+	d.md5.Write(d.buf)
+}
+
+// decodeSubframe decodes a subframe of len(s) samples of bps bits into s.
+func (d *flacDecoder) decodeSubframe(s []int32, bps int) error {
+	br := d.br
+	// zero bit, type (6 bits), wasted bits flag (1 bit) followed by the unary wasted bits - 1
+	h, err := br.read(8)
+	if err != nil {
+		return err
+	}
+	if h&0x80 != 0 {
+		return fmt.Errorf("%w: subframe padding", ErrMalformed)
+	}
+	wasted := 0
+	if h&1 != 0 {
+		k, err := br.unary()
+		if err != nil {
+			return err
+		}
+		wasted = int(k) + 1
+	}
+	bps -= wasted
+	if bps <= 0 || bps > 32 {
+		return fmt.Errorf("%w: %d bits per sample", errors.ErrUnsupported, bps+wasted)
+	}
+
+	switch t := h >> 1 & 0x3f; {
+	case t == 0: // constant
+		x, err := br.readSigned(uint(bps))
+		if err != nil {
+			return err
+		}
+		for i := range s {
+			s[i] = int32(x)
+		}
+
+	case t == 1: // verbatim
+		for i := range s {
+			x, err := br.readSigned(uint(bps))
+			if err != nil {
+				return err
+			}
+			s[i] = int32(x)
+		}
+
+	case t >= 8 && t <= 12: // fixed
+		if err := d.decodeFixed(s, bps, int(t-8)); err != nil {
+			return err
+		}
+
+	case t >= 32: // LPC
+		if err := d.decodeLPC(s, bps, int(t-31)); err != nil {
+			return err
+		}
+
+	default:
+		return fmt.Errorf("%w: reserved subframe type %d", ErrMalformed, t)
+	}
+
+	if wasted > 0 {
+		for i := range s {
+			s[i] <<= wasted
+		}
+	}
+	return nil
+}
+
+// fixedCoefficients are the coefficients of the fixed predictors of each order.
+var fixedCoefficients = [5][]int64{{}, {1}, {2, -1}, {3, -3, 1}, {4, -6, 4, -1}}
+
+func (d *flacDecoder) decodeFixed(s []int32, bps, order int) error {
+	if err := d.decodeWarmUp(s, bps, order); err != nil {
+		return err
+	}
+	if err := d.decodeResidual(s, order); err != nil {
+		return err
+	}
+	predict(s, fixedCoefficients[order], 0)
+	return nil
+}
+
+func (d *flacDecoder) decodeLPC(s []int32, bps, order int) error {
+	if err := d.decodeWarmUp(s, bps, order); err != nil {
+		return err
+	}
+	// precision - 1 (4 bits), shift (5 bits, signed), coefficients
+	br := d.br
+	p, err := br.read(4)
+	if err != nil {
+		return err
+	}
+	if p == 15 {
+		return fmt.Errorf("%w: LPC precision", ErrMalformed)
+	}
+	shift, err := br.readSigned(5)
+	if err != nil {
+		return err
+	}
+	if shift < 0 {
+		return fmt.Errorf("%w: negative LPC shift", ErrMalformed)
+	}
+	coefficients := make([]int64, order)
+	for i := range coefficients {
+		if coefficients[i], err = br.readSigned(uint(p + 1)); err != nil {
+			return err
+		}
+	}
+	if err := d.decodeResidual(s, order); err != nil {
+		return err
+	}
+	predict(s, coefficients, uint(shift))
+	return nil
+}
+
+// decodeWarmUp decodes the unpredicted samples at the start of fixed and LPC subframes.
+func (d *flacDecoder) decodeWarmUp(s []int32, bps, order int) error {
+	if order > len(s) {
+		return fmt.Errorf("%w: predictor order %d exceeds the block size", ErrMalformed, order)
+	}
+	for i := 0; i < order; i++ {
+		x, err := d.br.readSigned(uint(bps))
+		if err != nil {
+			return err
+		}
+		s[i] = int32(x)
+	}
+	return nil
+}
+
+// predict adds the prediction of each sample after the warm-up samples to its residual.
+func predict(s []int32, coefficients []int64, shift uint) {
+	order := len(coefficients)
+	for i := order; i < len(s); i++ {
+		var p int64
+		for j, c := range coefficients {
+			p += c * int64(s[i-j-1])
+		}
+		s[i] += int32(p >> shift)
+	}
+}
+
+// decodeResidual decodes the Rice coded residual of the samples after the warm-up samples.
+func (d *flacDecoder) decodeResidual(s []int32, order int) error {
+	br := d.br
+	// coding method (2 bits), partition order (4 bits)
+	h, err := br.read(6)
+	if err != nil {
+		return err
+	}
+	paramBits, escape := uint(4), uint64(15)
+	switch h >> 4 {
+	case 0:
+	case 1:
+		paramBits, escape = 5, 31
+	default:
+		return fmt.Errorf("%w: reserved residual coding method", ErrMalformed)
+	}
+	partitions := 1 << (h & 0xf)
+	if len(s)%partitions != 0 || len(s)/partitions < order {
+		return fmt.Errorf("%w: partition order %d", ErrMalformed, h&0xf)
+	}
+
+	i := order
+	for p := 0; p < partitions; p++ {
+		end := (p + 1) * len(s) / partitions
+		k, err := br.read(paramBits)
+		if err != nil {
+			return err
+		}
+		if k == escape {
+			n, err := br.read(5)
+			if err != nil {
+				return err
+			}
+			for ; i < end; i++ {
+				x, err := br.readSigned(uint(n))
+				if err != nil {
+					return err
+				}
+				s[i] = int32(x)
+			}
+			continue
+		}
+		for ; i < end; i++ {
+			q, err := br.unary()
+			if err != nil {
+				return err
+			}
+			r, err := br.read(uint(k))
+			if err != nil {
+				return err
+			}
+			u := uint64(q)<<k | r
+			s[i] = int32(u>>1) ^ -int32(u&1)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package tag
+
+import (
+	"bytes"
+	"crypto/md5"
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestVerifyFLAC(t *testing.T) {
+	for _, path := range []string{"testdata/with_tags/sample.flac", "testdata/without_tags/sample.flac"} {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := VerifyFLAC(bytes.NewReader(b)); err != nil {
+			t.Errorf("%v: VerifyFLAC() = %v", path, err)
+		}
+	}
+}
+
+func TestVerifyFLACCorrupt(t *testing.T) {
+	b, err := os.ReadFile("testdata/without_tags/sample.flac")
+	if err != nil {
+		t.Fatal(err)
+	}
+	audio := bytes.LastIndex(b, []byte{0xff, 0xf8}) // start of the last frame
+
+	tests := []struct {
+		name   string
+		offset int // of the byte to change
+		block  string
+	}{
+		{"frame", audio + 20, "frame"},
+		{"MD5", 8 + 18, "MD5"},
+	}
+	for _, tt := range tests {
+		c := append([]byte(nil), b...)
+		c[tt.offset] ^= 0x10
+		err := VerifyFLAC(bytes.NewReader(c))
+		var pe *ParseError
+		if !errors.Is(err, ErrBadChecksum) || !errors.As(err, &pe) || pe.Name != tt.block {
+			t.Errorf("%v: VerifyFLAC() = %v, expected bad checksum of %v", tt.name, err, tt.block)
+		}
+	}
+
+	if err := VerifyFLAC(bytes.NewReader(b[:len(b)-100])); !errors.Is(err, ErrTruncated) {
+		t.Errorf("VerifyFLAC() of truncated file = %v, expected ErrTruncated", err)
+	}
+}
+
+// flacBitWriter writes the bits of a FLAC frame.
+type flacBitWriter struct {
+	b []byte
+	n uint // bits used in the last byte, or 0 if it is full
+}
+
+func (w *flacBitWriter) write(x uint64, k uint) {
+	for i := int(k) - 1; i >= 0; i-- {
+		if w.n == 0 {
+			w.b = append(w.b, 0)
+		}
+		w.b[len(w.b)-1] |= byte(x>>uint(i)&1) << (7 - w.n)
+		w.n = (w.n + 1) % 8
+	}
+}
+
+func (w *flacBitWriter) writeRice(x int64, k uint) {
+	u := uint64(x<<1 ^ x>>63)
+	w.write(1, uint(u>>k)+1)
+	w.write(u, k)
+}
+
+// crc returns the CRC-8 and CRC-16 of the bytes written.
+func (w *flacBitWriter) crc() (c8 byte, c16 uint16) {
+	for _, b := range w.b {
+		c8 = crc8Table[c8^b]
+		c16 = c16<<8 ^ crc16Table[byte(c16>>8)^b]
+	}
+	return c8, c16
+}
+
+// frame writes a frame of 16 samples per channel at 44100 Hz with 16 bits per sample, where
+// subframes writes the subframes.
+func (w *flacBitWriter) frame(n, channelCode uint64, subframes func()) {
+	start := len(w.b)
+	w.write(0xfff8, 16)
+	w.write(6<<4|9, 8)              // 8-bit block size, 44100 Hz
+	w.write(channelCode<<4|4<<1, 8) // 16 bits per sample
+	w.write(n, 8)                   // frame number
+	w.write(15, 8)                  // block size - 1
+	c8, _ := (&flacBitWriter{b: w.b[start:]}).crc()
+	w.write(uint64(c8), 8)
+	subframes()
+	w.n = 0
+	_, c16 := (&flacBitWriter{b: w.b[start:]}).crc()
+	w.write(uint64(c16), 16)
+}
+
+func TestVerifyFLACFrames(t *testing.T) {
+	var left, right [32]int64
+	for i := range 16 {
+		left[i] = int64(1000*i - 3000 + i*i*7)
+		right[i] = int64(-500*i + 200 + i%3)
+	}
+	for i := 16; i < 32; i++ {
+		left[i] = -1234 * 2 // constant with a wasted bit
+		right[i] = int64(i*i*i - 9000)
+	}
+
+	w := &flacBitWriter{}
+	// Frame 0: mid/side, with mid as a second order fixed subframe and side as a first order
+	// LPC subframe with an escaped partition.
+	w.frame(0, 10, func() {
+		var mid, side [16]int64
+		for i := range mid {
+			mid[i], side[i] = (left[i]+right[i])>>1, left[i]-right[i]
+		}
+
+		w.write(0, 1)
+		w.write(8+2, 6)
+		w.write(0, 1)
+		w.write(uint64(mid[0]), 16)
+		w.write(uint64(mid[1]), 16)
+		w.write(0, 2) // 4-bit Rice parameters
+		w.write(0, 4) // one partition
+		w.write(7, 4)
+		for i := 2; i < 16; i++ {
+			w.writeRice(mid[i]-(2*mid[i-1]-mid[i-2]), 7)
+		}
+
+		w.write(0, 1)
+		w.write(32+0, 6) // order 1
+		w.write(0, 1)
+		w.write(uint64(side[0]), 17)
+		w.write(3, 4) // precision 4
+		w.write(0, 5) // shift 0
+		w.write(1, 4) // coefficient 1
+		w.write(1, 2) // 5-bit Rice parameters
+		w.write(1, 4) // two partitions
+		w.write(31, 5)
+		w.write(12, 5) // escaped: 12-bit residuals
+		for i := 1; i < 8; i++ {
+			w.write(uint64(side[i]-side[i-1]), 12)
+		}
+		w.write(9, 5)
+		for i := 8; i < 16; i++ {
+			w.writeRice(side[i]-side[i-1], 9)
+		}
+	})
+	// Frame 1: independent channels, with a constant subframe with a wasted bit and a verbatim
+	// subframe.
+	w.frame(1, 1, func() {
+		w.write(0, 1)
+		w.write(0, 6)
+		w.write(1, 1)
+		w.write(1, 1) // 1 wasted bit
+		w.write(uint64(left[16]>>1), 15)
+
+		w.write(0, 1)
+		w.write(1, 6)
+		w.write(0, 1)
+		for _, x := range right[16:] {
+			w.write(uint64(x), 16)
+		}
+	})
+
+	var pcm []byte
+	for i := range left {
+		pcm = append(pcm, byte(left[i]), byte(left[i]>>8), byte(right[i]), byte(right[i]>>8))
+	}
+	sum := md5.Sum(pcm)
+	// 16/16 block size, unknown frame sizes, 44100 Hz, 2 channels, 16 bits, 32 samples
+	streamInfo := []byte{0x00, 0x10, 0x00, 0x10, 0, 0, 0, 0, 0, 0, 0x0a, 0xc4, 0x42, 0xf0, 0, 0, 0, 32}
+	b := bytes.Join([][]byte{[]byte("fLaC"), flacBlock(streamInfoBlock, true, streamInfo, sum[:]), w.b}, nil)
+	if err := VerifyFLAC(bytes.NewReader(b)); err != nil {
+		t.Errorf("VerifyFLAC() = %v", err)
+	}
+
+	pcm[len(pcm)-1] ^= 1
+	sum = md5.Sum(pcm)
+	b = bytes.Join([][]byte{[]byte("fLaC"), flacBlock(streamInfoBlock, true, streamInfo, sum[:]), w.b}, nil)
+	if err := VerifyFLAC(bytes.NewReader(b)); !errors.Is(err, ErrBadChecksum) {
+		t.Errorf("VerifyFLAC() with the wrong MD5 = %v, expected ErrBadChecksum", err)
+	}
+}
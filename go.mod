@@ -2,6 +2,9 @@ module github.com/zeozeozeo/tag
 
 go 1.22
 
-require github.com/dhowden/itl v0.0.0-20170329215456-9fbe21093131
+require (
+	github.com/dhowden/itl v0.0.0-20170329215456-9fbe21093131
+	golang.org/x/text v0.21.0
+)
 
 require github.com/dhowden/plist v0.0.0-20141002110153-5db6e0d9931a // indirect
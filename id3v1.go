@@ -43,7 +43,9 @@ var ErrNotID3v1 = errors.New("invalid ID3v1 header")
 
 // ReadID3v1Tags reads ID3v1 tags from the io.ReadSeeker.  Returns ErrNotID3v1
 // if there are no ID3v1 tags, otherwise non-nil error if there was a problem.
-func ReadID3v1Tags(r io.ReadSeeker) (metadataID3v1, error) {
+// Text is decoded with the charset given by the Charset or DetectCharset options.
+func ReadID3v1Tags(r io.ReadSeeker, opts ...ReadOption) (metadataID3v1, error) {
+	o := newReadOptions(opts)
 	_, err := r.Seek(-128, io.SeekEnd)
 	if err != nil {
 		return nil, err
@@ -99,11 +101,12 @@ func ReadID3v1Tags(r io.ReadSeeker) (metadataID3v1, error) {
 	}
 
 	m := make(map[string]interface{})
-	m["title"] = trimString(title)
-	m["artist"] = trimString(artist)
-	m["album"] = trimString(album)
+	text := func(s string) string { return o.decodeID3v1([]byte(trimString(s))) }
+	m["title"] = text(title)
+	m["artist"] = text(artist)
+	m["album"] = text(album)
 	m["year"] = trimString(year)
-	m["comment"] = trimString(comment)
+	m["comment"] = text(comment)
 	m["track"] = track
 	m["genre"] = genre
 
@@ -137,6 +140,36 @@ func (m metadataID3v1) Year() int {
 
 func (m metadataID3v1) Track() (int, int) { return m["track"].(int), 0 }
 
+func (m metadataID3v1) Date() Date       { return Date{Year: m.Year()} }
+func (metadataID3v1) OriginalDate() Date { return Date{} }
+
+func (metadataID3v1) TitleSort() string       { return "" }
+func (metadataID3v1) AlbumSort() string       { return "" }
+func (metadataID3v1) ArtistSort() string      { return "" }
+func (metadataID3v1) AlbumArtistSort() string { return "" }
+func (metadataID3v1) BPM() int                { return 0 }
+func (metadataID3v1) Key() string             { return "" }
+func (metadataID3v1) ISRC() string            { return "" }
+func (metadataID3v1) Label() string           { return "" }
+func (metadataID3v1) CatalogNumber() string   { return "" }
+func (metadataID3v1) Copyright() string       { return "" }
+func (metadataID3v1) Encoder() string         { return "" }
+func (metadataID3v1) EncodedBy() string       { return "" }
+func (metadataID3v1) Grouping() string        { return "" }
+func (metadataID3v1) Conductor() string       { return "" }
+func (metadataID3v1) Lyricist() string        { return "" }
+func (metadataID3v1) Mood() string            { return "" }
+func (metadataID3v1) Compilation() bool       { return false }
+
+func (m metadataID3v1) Get(f Field) string { return getField(ID3v1, m, f) }
+
+func (metadataID3v1) Chapters() []Chapter       { return nil }
+func (metadataID3v1) SyncedLyrics() []LyricLine { return nil }
+func (metadataID3v1) ReplayGain() *ReplayGain   { return nil }
+func (metadataID3v1) Ratings() []Rating         { return nil }
+func (metadataID3v1) PlayCount() int            { return 0 }
+func (metadataID3v1) Audio() *Audio             { return nil }
+
 func (m metadataID3v1) AlbumArtist() string { return "" }
 func (m metadataID3v1) Composer() string    { return "" }
 func (metadataID3v1) Disc() (int, int)      { return 0, 0 }
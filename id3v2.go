@@ -5,8 +5,11 @@
 package tag
 
 import (
+	"bytes"
+	"compress/zlib"
 	"errors"
 	"fmt"
+	"hash/crc32"
 	"io"
 	"regexp"
 	"strconv"
@@ -62,6 +65,37 @@ type id3v2Header struct {
 	Experimental      bool
 	FooterPresent     bool
 	Size              uint
+	Start             int64 // offset of the tag from the start of the file
+
+	Extended           *ID3v2ExtendedHeader // nil if there is no extended header
+	ExtendedHeaderSize uint                 // including the extended header size bytes
+}
+
+// ID3v2ExtendedHeader is the extended header of an ID3v2.3 or ID3v2.4 tag.
+type ID3v2ExtendedHeader struct {
+	// Update is set if the tag is an update of a tag found earlier in the file (ID3v2.4 only).
+	Update bool
+
+	// HasCRC is set if the extended header contains a CRC-32 of the tag data.
+	HasCRC bool
+	CRC    uint32
+
+	// PaddingSize is the size of the padding at the end of the tag (ID3v2.3 only).
+	PaddingSize uint32
+
+	// Restrictions are the tag restrictions, or nil if there are none (ID3v2.4 only).
+	Restrictions *ID3v2Restrictions
+}
+
+// ID3v2Restrictions are the restrictions set in an ID3v2.4 extended header (see
+// http://id3.org/id3v2.4.0-structure sec 3.2).  Each field holds the raw value of the
+// restriction bits.
+type ID3v2Restrictions struct {
+	TagSize       byte // 0: 128 frames and 1MB, 1: 64 frames and 128KB, 2: 32 frames and 40KB, 3: 32 frames and 4KB
+	TextEncoding  byte // 0: no restrictions, 1: ISO-8859-1 or UTF-8 only
+	TextSize      byte // 0: no restrictions, 1: 1024 chars, 2: 128 chars, 3: 30 chars
+	ImageEncoding byte // 0: no restrictions, 1: PNG or JPEG only
+	ImageSize     byte // 0: no restrictions, 1: 256x256 or less, 2: 64x64 or less, 3: exactly 64x64
 }
 
 // readID3v2Header reads the ID3v2 header from the given io.Reader.
@@ -70,11 +104,11 @@ func readID3v2Header(r io.Reader) (h *id3v2Header, offset uint, err error) {
 	offset = 10
 	b, err := readBytes(r, offset)
 	if err != nil {
-		return nil, 0, fmt.Errorf("expected to read 10 bytes (ID3v2Header): %v", err)
+		return nil, 0, fmt.Errorf("expected to read 10 bytes (ID3v2Header): %w", err)
 	}
 
 	if string(b[0:3]) != "ID3" {
-		return nil, 0, fmt.Errorf("expected to read \"ID3\"")
+		return nil, 0, fmt.Errorf("%w: expected to read \"ID3\"", ErrMalformed)
 	}
 
 	b = b[3:]
@@ -89,7 +123,7 @@ func readID3v2Header(r io.Reader) (h *id3v2Header, offset uint, err error) {
 	case 0, 1:
 		fallthrough
 	default:
-		return nil, 0, fmt.Errorf("ID3 version: %v, expected: 2, 3 or 4", uint(b[0]))
+		return nil, 0, fmt.Errorf("%w: ID3 version: %v, expected: 2, 3 or 4", ErrUnsupportedVersion, uint(b[0]))
 	}
 
 	// NB: We ignore b[1] (the revision) as we don't currently rely on it.
@@ -107,26 +141,40 @@ func readID3v2Header(r io.Reader) (h *id3v2Header, offset uint, err error) {
 		case ID3v2_3:
 			b, err := readBytes(r, 4)
 			if err != nil {
-				return nil, 0, fmt.Errorf("expected to read 4 bytes (ID3v23 extended header len): %v", err)
+				return nil, 0, fmt.Errorf("expected to read 4 bytes (ID3v23 extended header len): %w", err)
 			}
-			// skip header, size is excluding len bytes
+			// size is excluding len bytes
 			extendedHeaderSize := uint(getInt(b))
-			_, err = readBytes(r, extendedHeaderSize)
+			b, err = readBytes(r, extendedHeaderSize)
+			if err != nil {
+				return nil, 0, fmt.Errorf("expected to read %d bytes (ID3v23 extended header): %w", extendedHeaderSize, err)
+			}
+			h.Extended, err = readID3v23ExtendedHeader(b)
 			if err != nil {
-				return nil, 0, fmt.Errorf("expected to read %d bytes (ID3v23 skip extended header): %v", extendedHeaderSize, err)
+				return nil, 0, err
 			}
+			h.ExtendedHeaderSize = extendedHeaderSize + 4
 			offset += extendedHeaderSize
 		case ID3v2_4:
 			b, err := readBytes(r, 4)
 			if err != nil {
-				return nil, 0, fmt.Errorf("expected to read 4 bytes (ID3v24 extended header len): %v", err)
+				return nil, 0, fmt.Errorf("expected to read 4 bytes (ID3v24 extended header len): %w", err)
+			}
+			// size is synchsafe int including len bytes
+			extendedHeaderSize := uint(get7BitChunkedInt(b))
+			if extendedHeaderSize < 6 {
+				return nil, 0, fmt.Errorf("%w: ID3v24 extended header size %d is less than 6", ErrMalformed, extendedHeaderSize)
 			}
-			// skip header, size is synchsafe int including len bytes
-			extendedHeaderSize := uint(get7BitChunkedInt(b)) - 4
-			_, err = readBytes(r, extendedHeaderSize)
+			extendedHeaderSize -= 4
+			b, err = readBytes(r, extendedHeaderSize)
 			if err != nil {
-				return nil, 0, fmt.Errorf("expected to read %d bytes (ID3v24 skip extended header): %v", extendedHeaderSize, err)
+				return nil, 0, fmt.Errorf("expected to read %d bytes (ID3v24 extended header): %w", extendedHeaderSize, err)
 			}
+			h.Extended, err = readID3v24ExtendedHeader(b)
+			if err != nil {
+				return nil, 0, err
+			}
+			h.ExtendedHeaderSize = extendedHeaderSize + 4
 			offset += extendedHeaderSize
 		default:
 			// nop, only 2.3 and 2.4 should have extended header
@@ -136,6 +184,108 @@ func readID3v2Header(r io.Reader) (h *id3v2Header, offset uint, err error) {
 	return h, offset, nil
 }
 
+// IDv2.3 (excluding the extended header size)
+// -- readID3v23ExtendedHeader
+// Extended Flags   $xx xx
+// Size of padding  $xx xx xx xx
+// Total frame CRC  $xx xx xx xx (if CRC data present flag is set)
+func readID3v23ExtendedHeader(b []byte) (*ID3v2ExtendedHeader, error) {
+	if len(b) < 6 {
+		return nil, fmt.Errorf("%w: expected at least 6 bytes for ID3v23 extended header, got %d", ErrTruncated, len(b))
+	}
+
+	x := &ID3v2ExtendedHeader{
+		HasCRC:      getBit(b[0], 7),
+		PaddingSize: uint32(getInt(b[2:6])),
+	}
+	if x.HasCRC {
+		if len(b) < 10 {
+			return nil, fmt.Errorf("%w: expected 4 bytes for ID3v23 extended header CRC", ErrTruncated)
+		}
+		x.CRC = uint32(getInt(b[6:10]))
+	}
+	return x, nil
+}
+
+// IDv2.4 (excluding the extended header size)
+// -- readID3v24ExtendedHeader
+// Number of flag bytes  $01
+// Extended Flags        %0bcd0000
+// Flag data             (for each set flag) length $xx, followed by the data:
+//
+//	b: tag is an update   $00
+//	c: CRC data present   5 x %0xxxxxxx (35 bit synchsafe integer)
+//	d: tag restrictions   %ppqrrstt
+func readID3v24ExtendedHeader(b []byte) (*ID3v2ExtendedHeader, error) {
+	if len(b) < 2 {
+		return nil, fmt.Errorf("%w: expected at least 2 bytes for ID3v24 extended header flags, got %d", ErrTruncated, len(b))
+	}
+	flags := b[1]
+	b = b[2:]
+
+	// flagData returns the data for a set flag, advancing b.
+	flagData := func() ([]byte, error) {
+		if len(b) < 1 || len(b) < 1+int(b[0]) {
+			return nil, fmt.Errorf("%w: ID3v24 extended header flag data", ErrTruncated)
+		}
+		d := b[1 : 1+int(b[0])]
+		b = b[1+int(b[0]):]
+		return d, nil
+	}
+
+	x := &ID3v2ExtendedHeader{}
+	if getBit(flags, 6) {
+		if _, err := flagData(); err != nil {
+			return nil, err
+		}
+		x.Update = true
+	}
+	if getBit(flags, 5) {
+		d, err := flagData()
+		if err != nil {
+			return nil, err
+		}
+		if len(d) != 5 {
+			return nil, fmt.Errorf("%w: expected 5 bytes for ID3v24 extended header CRC, got %d", ErrMalformed, len(d))
+		}
+		x.HasCRC = true
+		x.CRC = uint32(get7BitChunkedInt(d))
+	}
+	if getBit(flags, 4) {
+		d, err := flagData()
+		if err != nil {
+			return nil, err
+		}
+		if len(d) != 1 {
+			return nil, fmt.Errorf("%w: expected 1 byte for ID3v24 tag restrictions, got %d", ErrMalformed, len(d))
+		}
+		x.Restrictions = &ID3v2Restrictions{
+			TagSize:       d[0] >> 6,
+			TextEncoding:  d[0] >> 5 & 0x1,
+			TextSize:      d[0] >> 3 & 0x3,
+			ImageEncoding: d[0] >> 2 & 0x1,
+			ImageSize:     d[0] & 0x3,
+		}
+	}
+	return x, nil
+}
+
+// verifyID3v2CRC checks the CRC-32 given in the extended header against the tag data b
+// (everything after the extended header).
+func verifyID3v2CRC(h *id3v2Header, b []byte) error {
+	if h.Version == ID3v2_3 {
+		// The ID3v2.3 CRC excludes the padding.
+		if uint(h.Extended.PaddingSize) > uint(len(b)) {
+			return fmt.Errorf("%w: padding size %d exceeds tag size", ErrMalformed, h.Extended.PaddingSize)
+		}
+		b = b[:len(b)-int(h.Extended.PaddingSize)]
+	}
+	if crc := crc32.ChecksumIEEE(b); crc != h.Extended.CRC {
+		return fmt.Errorf("%w: expected crc %x != %x", ErrBadChecksum, h.Extended.CRC, crc)
+	}
+	return nil
+}
+
 // id3v2FrameFlags is a type which represents the flags which can be set on an ID3v2 frame.
 type id3v2FrameFlags struct {
 	// Message (ID3 2.3.0 and 2.4.0)
@@ -233,10 +383,19 @@ func readID3v2_4FrameHeader(r io.Reader) (name string, size uint, headerSize uin
 }
 
 // readID3v2Frames reads ID3v2 frames from the given reader using the ID3v2Header.
-func readID3v2Frames(r io.Reader, offset uint, h *id3v2Header) (map[string]interface{}, error) {
+// If lenient parsing is enabled, frames which cannot be decoded are skipped, and reading
+// stops at the first frame header which cannot be read.
+func readID3v2Frames(r io.Reader, offset uint, h *id3v2Header, o *readOptions) (map[string]interface{}, error) {
 	result := make(map[string]interface{})
 
 	for offset < h.Size {
+		frameOffset := h.Start + int64(offset)
+		frameError := func(name string, err error) error {
+			// Frames cannot be located after a bad frame header, so this ends the read (returning
+			// the frames read so far when lenient).
+			return o.warn(newParseError(string(h.Version), frameOffset, name, err))
+		}
+
 		var err error
 		var name string
 		var size, headerSize uint
@@ -249,7 +408,7 @@ func readID3v2Frames(r io.Reader, offset uint, h *id3v2Header) (map[string]inter
 		case ID3v2_3:
 			name, size, headerSize, err = readID3v2_3FrameHeader(r)
 			if err != nil {
-				return nil, err
+				return result, frameError(name, err)
 			}
 			flags, err = readID3v23FrameFlags(r)
 			headerSize += 2
@@ -257,14 +416,14 @@ func readID3v2Frames(r io.Reader, offset uint, h *id3v2Header) (map[string]inter
 		case ID3v2_4:
 			name, size, headerSize, err = readID3v2_4FrameHeader(r)
 			if err != nil {
-				return nil, err
+				return result, frameError(name, err)
 			}
 			flags, err = readID3v24FrameFlags(r)
 			headerSize += 2
 		}
 
 		if err != nil {
-			return nil, err
+			return result, frameError(name, err)
 		}
 
 		// FIXME: Do we still need this?
@@ -281,50 +440,66 @@ func readID3v2Frames(r io.Reader, offset uint, h *id3v2Header) (map[string]inter
 			break
 		}
 
+		// Data added to the frame by its format flags.
+		var dataLength uint
+		readFlagData := func(n uint) ([]byte, error) {
+			if n > size {
+				return nil, fmt.Errorf("%w: frame too small for its format flags", ErrMalformed)
+			}
+			size -= n
+			return readBytes(r, n)
+		}
+
 		if flags != nil {
-			if flags.Compression {
-				switch h.Version {
-				case ID3v2_3:
-					// No data length indicator defined.
-					if _, err := read7BitChunkedUint(r, 4); err != nil { // read 4
-						return nil, err
+			switch h.Version {
+			case ID3v2_3:
+				// Decompressed size, encryption method and group identifier, in that order.
+				if flags.Compression {
+					b, err := readFlagData(4)
+					if err != nil {
+						return result, frameError(name, err)
 					}
-					size -= 4
-
-				case ID3v2_4:
-					// Must have a data length indicator (to give the size) if compression is enabled.
-					if !flags.DataLengthIndicator {
-						return nil, errors.New("compression without data length indicator")
+					dataLength = uint(getInt(b))
+				}
+				if flags.Encryption {
+					if _, err := readFlagData(1); err != nil {
+						return result, frameError(name, err)
 					}
-
-				default:
-					return nil, fmt.Errorf("unsupported compression flag used in %v", h.Version)
 				}
-			}
-
-			if flags.DataLengthIndicator {
-				if h.Version == ID3v2_3 {
-					return nil, fmt.Errorf("data length indicator set but not defined for %v", ID3v2_3)
+				if flags.GroupIdentity {
+					if _, err := readFlagData(1); err != nil {
+						return result, frameError(name, err)
+					}
 				}
 
-				size, err = read7BitChunkedUint(r, 4)
-				if err != nil { // read 4
-					return nil, err
+			case ID3v2_4:
+				// Group identifier, encryption method and data length indicator, in that order.
+				if flags.GroupIdentity {
+					if _, err := readFlagData(1); err != nil {
+						return result, frameError(name, err)
+					}
 				}
-			}
-
-			if flags.Encryption {
-				_, err = readBytes(r, 1) // read 1 byte of encryption method
-				if err != nil {
-					return nil, err
+				if flags.Encryption {
+					if _, err := readFlagData(1); err != nil {
+						return result, frameError(name, err)
+					}
+				}
+				if flags.DataLengthIndicator {
+					b, err := readFlagData(4)
+					if err != nil {
+						return result, frameError(name, err)
+					}
+					dataLength = uint(get7BitChunkedInt(b))
+				} else if flags.Compression {
+					// Must have a data length indicator (to give the size) if compression is enabled.
+					return result, frameError(name, fmt.Errorf("%w: compression without data length indicator", ErrMalformed))
 				}
-				size--
 			}
 		}
 
 		b, err := readBytes(r, size)
 		if err != nil {
-			return nil, err
+			return result, frameError(name, err)
 		}
 
 		// There can be multiple tag with the same name. Append a number to the
@@ -337,68 +512,188 @@ func readID3v2Frames(r io.Reader, offset uint, h *id3v2Header) (map[string]inter
 			}
 		}
 
-		switch {
-		case name == "TXXX" || name == "TXX":
-			t, err := readTextWithDescrFrame(b, false, true) // no lang, but enc
-			if err != nil {
-				return nil, err
-			}
-			result[rawName] = t
-
-		case name[0] == 'T':
-			txt, err := readTFrame(b)
-			if err != nil {
-				return nil, err
+		var v interface{}
+		b, err = decodeID3v2FrameData(h, flags, dataLength, b)
+		if err == nil {
+			// offset is now the end of the frame, and size the size of its data.
+			v, err = readID3v2Frame(h, name, b, h.Start+int64(offset)-int64(size), o)
+			if err != nil && id3v2RawFallbackFrames[name] {
+				// Keep the raw data of frames which can't be decoded, as before they were.
+				o.warn(newParseError(string(h.Version), frameOffset, name, fmt.Errorf("could not decode %q (%q): %w", name, rawName, err)))
+				v, err = b, nil
 			}
-			result[rawName] = txt
-
-		case name == "UFID" || name == "UFI":
-			t, err := readUFID(b)
-			if err != nil {
+		}
+		if err != nil {
+			// The frame data has been consumed, so a bad frame can be skipped.
+			err = newParseError(string(h.Version), frameOffset, name, fmt.Errorf("could not read %q (%q): %w", name, rawName, err))
+			if err := o.warn(err); err != nil {
 				return nil, err
 			}
-			result[rawName] = t
+			continue
+		}
+		result[rawName] = v
+	}
+	return result, nil
+}
 
-		case name == "WXXX" || name == "WXX":
-			t, err := readTextWithDescrFrame(b, false, false) // no lang, no enc
-			if err != nil {
-				return nil, err
-			}
-			result[rawName] = t
+// decodeID3v2FrameData reverses the unsynchronisation and compression of the frame data b.
+// dataLength is the size of the decoded data given by the frame, if any.
+func decodeID3v2FrameData(h *id3v2Header, flags *id3v2FrameFlags, dataLength uint, b []byte) ([]byte, error) {
+	if flags == nil {
+		return b, nil
+	}
 
-		case name[0] == 'W':
-			txt, err := readWFrame(b)
-			if err != nil {
-				return nil, err
-			}
-			result[rawName] = txt
+	// In ID3v2.4 unsynchronisation is applied to each frame (the tag header flag means that it
+	// has been applied to all of them).
+	if h.Version == ID3v2_4 && (flags.Unsynchronisation || h.Unsynchronisation) {
+		b = removeUnsynchronisation(b)
+	}
 
-		case name == "COMM" || name == "COM" || name == "USLT" || name == "ULT":
-			t, err := readTextWithDescrFrame(b, true, true) // both lang and enc
-			if err != nil {
-				return nil, fmt.Errorf("could not read %q (%q): %v", name, rawName, err)
-			}
-			result[rawName] = t
+	if flags.Encryption {
+		// Encrypted data is returned as-is.
+		return b, nil
+	}
 
-		case name == "APIC":
-			p, err := readAPICFrame(b)
-			if err != nil {
-				return nil, err
-			}
-			result[rawName] = p
+	if flags.Compression {
+		zr, err := zlib.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, fmt.Errorf("%w: could not decompress frame: %v", ErrMalformed, err)
+		}
+		defer zr.Close()
 
-		case name == "PIC":
-			p, err := readPICFrame(b)
-			if err != nil {
-				return nil, err
-			}
-			result[rawName] = p
+		limit := int64(maxInflatedFrameSize)
+		if dataLength != 0 {
+			limit = int64(dataLength)
+		}
+		b, err = io.ReadAll(io.LimitReader(zr, limit+1))
+		if err != nil {
+			return nil, fmt.Errorf("%w: could not decompress frame: %v", ErrMalformed, err)
+		}
+		if int64(len(b)) > limit {
+			return nil, fmt.Errorf("%w: decompressed frame larger than %d bytes", ErrMalformed, limit)
+		}
+		if dataLength != 0 && uint(len(b)) != dataLength {
+			return nil, fmt.Errorf("%w: decompressed frame size %d, expected %d", ErrMalformed, len(b), dataLength)
+		}
+	}
+	return b, nil
+}
 
-		default:
-			result[rawName] = b
+// maxInflatedFrameSize is the limit on the size of a compressed frame when decompressed,
+// used when the frame doesn't give its decompressed size.
+const maxInflatedFrameSize = 16 << 20 // 16MB
+
+// removeUnsynchronisation removes the $00 bytes inserted after each $FF byte by the
+// unsynchronisation scheme.
+func removeUnsynchronisation(b []byte) []byte {
+	out := make([]byte, 0, len(b))
+	for i := 0; i < len(b); i++ {
+		out = append(out, b[i])
+		if b[i] == 0xff && i+1 < len(b) && b[i+1] == 0x00 {
+			i++
 		}
 	}
-	return result, nil
+	return out
+}
+
+// id3v2RawFallbackFrames are the frames whose raw data is kept if they can't be decoded.
+var id3v2RawFallbackFrames = map[string]bool{
+	"CHAP": true,
+	"CTOC": true,
+	"SYLT": true,
+	"SLT":  true,
+	"RVA2": true,
+	"POPM": true,
+	"POP":  true,
+	"PCNT": true,
+	"CNT":  true,
+	"PRIV": true,
+	"GEOB": true,
+	"GEO":  true,
+	"MCDI": true,
+	"MCI":  true,
+	"ETCO": true,
+	"ETC":  true,
+	"OWNE": true,
+	"COMR": true,
+}
+
+// readID3v2Frame decodes the data b of the frame with the given name, which is at offset in
+// the file.
+func readID3v2Frame(h *id3v2Header, name string, b []byte, offset int64, o *readOptions) (interface{}, error) {
+	switch {
+	case name == "CHAP":
+		return readCHAPFrame(b, offset, h, o)
+
+	case name == "CTOC":
+		return readCTOCFrame(b, offset, h, o)
+
+	case name == "SYLT" || name == "SLT":
+		return readSYLTFrame(b, o)
+
+	case name == "RVA2":
+		return readRVA2Frame(b)
+
+	case name == "POPM" || name == "POP":
+		return readPOPMFrame(b)
+
+	case name == "PCNT" || name == "CNT":
+		return readPCNTFrame(b)
+
+	case name == "PRIV":
+		return readPRIVFrame(b)
+
+	case name == "GEOB" || name == "GEO":
+		return readGEOBFrame(b, o)
+
+	case name == "MCDI" || name == "MCI":
+		return readMCDIFrame(b)
+
+	case name == "ETCO" || name == "ETC":
+		return readETCOFrame(b)
+
+	case name == "OWNE":
+		return readOWNEFrame(b, o)
+
+	case name == "COMR":
+		return readCOMRFrame(b, o)
+
+	case name == "TXXX" || name == "TXX":
+		return readTextWithDescrFrame(b, false, true, o) // no lang, but enc
+
+	case name[0] == 'T':
+		return readTFrame(b, o)
+
+	case name == "UFID" || name == "UFI":
+		return readUFID(b)
+
+	case name == "WXXX" || name == "WXX":
+		return readTextWithDescrFrame(b, false, false, o) // no lang, no enc
+
+	case name[0] == 'W':
+		return readWFrame(b)
+
+	case name == "COMM" || name == "COM" || name == "USLT" || name == "ULT":
+		return readTextWithDescrFrame(b, true, true, o) // both lang and enc
+
+	case name == "APIC":
+		return readAPICFrame(b, o)
+
+	case name == "PIC":
+		return readPICFrame(b, o)
+	}
+	return b, nil
+}
+
+// readEmbeddedID3v2Frames reads the frames embedded in a frame (e.g. CHAP) of the tag with
+// the given header, which start at offset in the file.
+func readEmbeddedID3v2Frames(b []byte, offset int64, h *id3v2Header, o *readOptions) (map[string]interface{}, error) {
+	sh := &id3v2Header{
+		Version: h.Version,
+		Size:    uint(len(b)),
+		Start:   offset,
+	}
+	return readID3v2Frames(bytes.NewReader(b), 0, sh, o)
 }
 
 type unsynchroniser struct {
@@ -426,19 +721,69 @@ func (r *unsynchroniser) Read(p []byte) (int, error) {
 }
 
 // ReadID3v2Tags parses ID3v2.{2,3,4} tags from the io.ReadSeeker into a Metadata, returning
-// non-nil error on failure.
-func ReadID3v2Tags(r io.ReadSeeker) (*metadataID3v2, error) {
-	h, offset, err := readID3v2Header(r)
+// non-nil error on failure.  If the tag contains a SEEK frame, the frames of the tag which it
+// points to are also included.
+func ReadID3v2Tags(r io.ReadSeeker, opts ...ReadOption) (*metadataID3v2, error) {
+	o := newReadOptions(opts)
+	m, err := readID3v2Tags(r, o)
 	if err != nil {
 		return nil, err
 	}
 
+	seen := map[int64]bool{m.header.Start: true}
+	for next := m; ; {
+		offset, ok := next.seekOffset()
+		if !ok || seen[offset] {
+			break
+		}
+		seen[offset] = true
+
+		next, err = readID3v2TagsAt(r, offset, o)
+		if err != nil {
+			if err := o.warn(err); err != nil {
+				return nil, err
+			}
+			break
+		}
+		if next == nil {
+			break
+		}
+		m.merge(next)
+	}
+	return m, nil
+}
+
+// readID3v2Tags reads a single ID3v2 tag from the current position of r.
+func readID3v2Tags(r io.ReadSeeker, o *readOptions) (*metadataID3v2, error) {
+	start := tell(r)
+	h, offset, err := readID3v2Header(r)
+	if err != nil {
+		return nil, newParseError("ID3v2", start, "", err)
+	}
+	h.Start = start
+
 	var ur io.Reader = r
-	if h.Unsynchronisation {
+	if h.Unsynchronisation && h.Version != ID3v2_4 {
 		ur = &unsynchroniser{Reader: r}
 	}
 
-	f, err := readID3v2Frames(ur, offset, h)
+	if x := h.Extended; x != nil && x.HasCRC {
+		if h.ExtendedHeaderSize > h.Size {
+			return nil, newParseError(string(h.Version), start, "", fmt.Errorf("%w: extended header size exceeds tag size", ErrMalformed))
+		}
+		b, err := readBytes(ur, h.Size-h.ExtendedHeaderSize)
+		if err != nil {
+			return nil, newParseError(string(h.Version), start, "", err)
+		}
+		if err := verifyID3v2CRC(h, b); err != nil {
+			if err := o.warn(newParseError(string(h.Version), start, "", err)); err != nil {
+				return nil, err
+			}
+		}
+		ur = bytes.NewReader(b)
+	}
+
+	f, err := readID3v2Frames(ur, offset, h, o)
 	if err != nil {
 		return nil, err
 	}
@@ -446,6 +791,53 @@ func ReadID3v2Tags(r io.ReadSeeker) (*metadataID3v2, error) {
 	return &metadataID3v2{header: h, frames: f}, nil
 }
 
+// readID3v2TagsAt reads the ID3v2 tag at offset.  Returns nil metadata (and nil error) if
+// there is no tag at offset.
+func readID3v2TagsAt(r io.ReadSeeker, offset int64, o *readOptions) (*metadataID3v2, error) {
+	if _, err := r.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if b, err := readBytes(r, 3); err != nil || string(b) != "ID3" {
+		return nil, nil
+	}
+	if _, err := r.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return readID3v2Tags(r, o)
+}
+
+// errNoID3v2Footer is returned by findID3v2Footer when there is no ID3v2 footer.
+var errNoID3v2Footer = errors.New("no ID3v2 footer")
+
+// findID3v2Footer looks for an ID3v2.4 tag footer ending at end (an offset from the start
+// of the file), returning the offset of the start of the tag.
+// -- Footer
+// File identifier  "3DI"
+// Version          $04 00
+// Flags            %abcd0000
+// Size             4 * %0xxxxxxx
+func findID3v2Footer(r io.ReadSeeker, end int64) (int64, error) {
+	if end < 20 {
+		return 0, errNoID3v2Footer
+	}
+	if _, err := r.Seek(end-10, io.SeekStart); err != nil {
+		return 0, err
+	}
+	b, err := readBytes(r, 10)
+	if err != nil {
+		return 0, err
+	}
+	if string(b[0:3]) != "3DI" {
+		return 0, errNoID3v2Footer
+	}
+
+	start := end - 20 - int64(get7BitChunkedInt(b[6:10]))
+	if start < 0 {
+		return 0, newParseError(string(ID3v2_4), end-10, "", fmt.Errorf("%w: tag size in footer exceeds file size", ErrMalformed))
+	}
+	return start, nil
+}
+
 var id3v2genreRe = regexp.MustCompile(`(.*[^(]|.* |^)\(([0-9]+)\) *(.*)$`)
 
 // id3v2genre parse a id3v2 genre tag and expand the numeric genres
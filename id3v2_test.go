@@ -6,6 +6,10 @@ package tag
 
 import (
 	"bytes"
+	"compress/zlib"
+	"errors"
+	"hash/crc32"
+	"os"
 	"reflect"
 	"testing"
 )
@@ -153,3 +157,191 @@ func TestGenreExpension(t *testing.T) {
 		}
 	}
 }
+
+// id3v24Tag builds an ID3v2.4 tag holding the given UTF-8 text frames, with an extended
+// header holding the CRC of the frames (and optionally a footer).
+func id3v24Tag(frames [][2]string, crc uint32, footer bool) []byte {
+	var body bytes.Buffer
+	for _, f := range frames {
+		n := len(f[1]) + 1
+		body.WriteString(f[0])
+		body.Write([]byte{byte(n >> 21 & 0x7f), byte(n >> 14 & 0x7f), byte(n >> 7 & 0x7f), byte(n & 0x7f), 0, 0, 3})
+		body.WriteString(f[1])
+	}
+	if crc == 0 {
+		crc = crc32.ChecksumIEEE(body.Bytes())
+	}
+	ext := []byte{0, 0, 0, 12, 1, 0x20, 5, byte(crc >> 28), byte(crc >> 21 & 0x7f), byte(crc >> 14 & 0x7f), byte(crc >> 7 & 0x7f), byte(crc & 0x7f)}
+
+	flags := byte(0x40)
+	if footer {
+		flags |= 0x10
+	}
+	n := len(ext) + body.Len()
+	size := []byte{byte(n >> 21 & 0x7f), byte(n >> 14 & 0x7f), byte(n >> 7 & 0x7f), byte(n & 0x7f)}
+
+	b := append([]byte{'I', 'D', '3', 4, 0, flags}, size...)
+	b = append(b, ext...)
+	b = append(b, body.Bytes()...)
+	if footer {
+		b = append(b, '3', 'D', 'I', 4, 0, flags)
+		b = append(b, size...)
+	}
+	return b
+}
+
+func TestID3v24ExtendedHeaderCRC(t *testing.T) {
+	frames := [][2]string{{"TIT2", "Title"}, {"TALB", "Album"}}
+
+	m, err := ReadID3v2Tags(bytes.NewReader(id3v24Tag(frames, 0, false)))
+	if err != nil {
+		t.Fatalf("ReadID3v2Tags() = %v", err)
+	}
+	if x := m.ExtendedHeader(); x == nil || !x.HasCRC {
+		t.Errorf("ExtendedHeader() = %+v, expected CRC", x)
+	}
+	if m.Title() != "Title" || m.Album() != "Album" {
+		t.Errorf("Title(), Album() = %q, %q, expected %q, %q", m.Title(), m.Album(), "Title", "Album")
+	}
+
+	b := id3v24Tag(frames, 1, false)
+	if _, err := ReadID3v2Tags(bytes.NewReader(b)); !errors.Is(err, ErrBadChecksum) {
+		t.Errorf("ReadID3v2Tags() = %v, expected ErrBadChecksum", err)
+	}
+
+	var warnings []error
+	m, err = ReadID3v2Tags(bytes.NewReader(b), Lenient(&warnings))
+	if err != nil {
+		t.Fatalf("ReadID3v2Tags() = %v", err)
+	}
+	if len(warnings) != 1 || m.Title() != "Title" {
+		t.Errorf("lenient read: warnings = %v, Title() = %q", warnings, m.Title())
+	}
+}
+
+func TestID3v2AppendedTag(t *testing.T) {
+	audio, err := os.ReadFile("testdata/without_tags/sample.mp3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := append(append([]byte{}, audio...), id3v24Tag([][2]string{{"TIT2", "Appended"}}, 0, true)...)
+	file = append(file, id3v1Tag("V1 Title", 1)...)
+
+	m, err := ReadFrom(bytes.NewReader(file))
+	if err != nil {
+		t.Fatalf("ReadFrom() = %v", err)
+	}
+	if m.Format() != ID3v2_4 || m.Title() != "Appended" {
+		t.Errorf("Format(), Title() = %v, %q, expected %v, %q", m.Format(), m.Title(), ID3v2_4, "Appended")
+	}
+	if m.Duration() == 0 {
+		t.Errorf("Duration() = 0")
+	}
+
+	blocks, err := ReadAll(bytes.NewReader(file))
+	if err != nil {
+		t.Fatalf("ReadAll() = %v", err)
+	}
+	if len(blocks) != 2 || blocks[0].Format != ID3v2_4 || blocks[0].Offset != int64(len(audio)) {
+		t.Errorf("ReadAll() = %+v", blocks)
+	}
+}
+
+func TestID3v2SeekFrame(t *testing.T) {
+	seek := func(offset uint32) []byte {
+		return id3v23Frame("SEEK", []byte{byte(offset >> 24), byte(offset >> 16), byte(offset >> 8), byte(offset)})
+	}
+	second := id3v2FramesTag(4, id3v23Frame("TIT2", []byte("\x00Second")), id3v23Frame("TALB", []byte("\x00Album")))
+	padding := []byte{0xff, 0xfb, 0x90, 0x00}
+
+	b := id3v2FramesTag(4, id3v23Frame("TIT2", []byte("\x00First")), seek(uint32(len(padding))))
+	b = append(append(b, padding...), second...)
+	m, err := ReadID3v2Tags(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("ReadID3v2Tags() = %v", err)
+	}
+	if m.Title() != "First" || m.Album() != "Album" {
+		t.Errorf("Title(), Album() = %q, %q, expected %q, %q", m.Title(), m.Album(), "First", "Album")
+	}
+
+	// An offset past the end of the file is ignored.
+	b = id3v2FramesTag(4, id3v23Frame("TIT2", []byte("\x00First")), seek(1<<30))
+	m, err = ReadID3v2Tags(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("ReadID3v2Tags() with out of range SEEK = %v", err)
+	}
+	if m.Title() != "First" || m.Album() != "" {
+		t.Errorf("Title(), Album() = %q, %q, expected %q, %q", m.Title(), m.Album(), "First", "")
+	}
+}
+
+func TestID3v2DamagedAppendedTag(t *testing.T) {
+	audio, err := os.ReadFile("testdata/without_tags/sample.mp3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag := id3v24Tag([][2]string{{"TIT2", "Appended"}}, 0, true)
+	tag[0] = 'X' // footer present, but no header where it points
+	file := append(append(append([]byte{}, audio...), tag...), id3v1Tag("V1 Title", 1)...)
+
+	var perr *ParseError
+	if _, err := ReadFrom(bytes.NewReader(file)); !errors.As(err, &perr) {
+		t.Errorf("ReadFrom() = %v, expected a ParseError", err)
+	}
+
+	var warnings []error
+	m, err := ReadFrom(bytes.NewReader(file), Lenient(&warnings))
+	if err != nil || len(warnings) != 1 {
+		t.Fatalf("lenient ReadFrom() = %v, warnings = %v", err, warnings)
+	}
+	if m.Format() != ID3v1 || m.Title() != "V1 Title" {
+		t.Errorf("Format(), Title() = %v, %q, expected %v, %q", m.Format(), m.Title(), ID3v1, "V1 Title")
+	}
+}
+
+func TestID3v2FrameDecoding(t *testing.T) {
+	text := []byte("\x00Compressed Title")
+	var z bytes.Buffer
+	zw := zlib.NewWriter(&z)
+	zw.Write(text)
+	zw.Close()
+
+	// ID3v2.3 compressed frame: decompressed size precedes the zlib data.
+	n := 4 + z.Len()
+	v23 := []byte{'T', 'I', 'T', '2', byte(n >> 24), byte(n >> 16), byte(n >> 8), byte(n), 0, 0x80, 0, 0, 0, byte(len(text))}
+	v23 = append(v23, z.Bytes()...)
+
+	// ID3v2.4 unsynchronised frame with a data length indicator.
+	data := []byte{0, 'A', 0xff, 0x00, 0xe0, 'B'}
+	v24 := []byte{'T', 'A', 'L', 'B', 0, 0, 0, byte(4 + len(data)), 0, 0x03, 0, 0, 0, 5}
+	v24 = append(v24, data...)
+
+	tag := func(version byte, frame []byte) []byte {
+		n := len(frame)
+		b := []byte{'I', 'D', '3', version, 0, 0, byte(n >> 21 & 0x7f), byte(n >> 14 & 0x7f), byte(n >> 7 & 0x7f), byte(n & 0x7f)}
+		return append(b, frame...)
+	}
+
+	m, err := ReadID3v2Tags(bytes.NewReader(tag(3, v23)))
+	if err != nil {
+		t.Fatalf("ReadID3v2Tags() = %v", err)
+	}
+	if got := m.Title(); got != "Compressed Title" {
+		t.Errorf("Title() = %q, expected %q", got, "Compressed Title")
+	}
+
+	m, err = ReadID3v2Tags(bytes.NewReader(tag(4, v24)))
+	if err != nil {
+		t.Fatalf("ReadID3v2Tags() = %v", err)
+	}
+	if got, want := m.Raw()["TALB"], "A\u00ff\u00e0B"; got != want {
+		t.Errorf("TALB = %q, expected %q", got, want)
+	}
+
+	// Decompression stops at the given size.
+	short := append([]byte{}, v23...)
+	short[13] = 5
+	if _, err := ReadID3v2Tags(bytes.NewReader(tag(3, short))); !errors.Is(err, ErrMalformed) {
+		t.Errorf("ReadID3v2Tags() with short data length = %v, expected ErrMalformed", err)
+	}
+}
@@ -7,14 +7,17 @@ package tag
 import (
 	"bytes"
 	"encoding/binary"
-	"errors"
 	"fmt"
 	"strings"
+	"time"
 	"unicode/utf16"
 )
 
 // DefaultUTF16WithBOMByteOrder is the byte order used when the "UTF16 with BOM" encoding
 // is specified without a corresponding BOM in the data.
+//
+// Deprecated: use the UTF16ByteOrder ReadOption, which is scoped to a single read.  This
+// variable is only used as the default.
 var DefaultUTF16WithBOMByteOrder binary.ByteOrder = binary.LittleEndian
 
 // ID3v2.2.0 frames (see http://id3.org/id3v2-00, sec 4).
@@ -100,8 +103,10 @@ var id3v22Frames = map[string]string{
 var id3v23Frames = map[string]string{
 	"AENC": "Audio encryption]",
 	"APIC": "Attached picture",
+	"CHAP": "Chapter",
 	"COMM": "Comments",
 	"COMR": "Commercial frame",
+	"CTOC": "Table of contents",
 	"ENCR": "Encryption method registration",
 	"EQUA": "Equalization",
 	"ETCO": "Event timing codes",
@@ -183,8 +188,10 @@ var id3v24Frames = map[string]string{
 	"APIC": "Attached picture",
 	"ASPI": "Audio seek point index",
 
+	"CHAP": "Chapter",
 	"COMM": "Comments",
 	"COMR": "Commercial frame",
+	"CTOC": "Table of contents",
 
 	"ENCR": "Encryption method registration",
 	"EQU2": "Equalisation (2)",
@@ -298,15 +305,15 @@ func validID3Frame(version Format, name string) bool {
 func readWFrame(b []byte) (string, error) {
 	// Frame text is always encoded in ISO-8859-1
 	b = append([]byte{0}, b...)
-	return readTFrame(b)
+	return readTFrame(b, nil)
 }
 
-func readTFrame(b []byte) (string, error) {
+func readTFrame(b []byte, o *readOptions) (string, error) {
 	if len(b) == 0 {
 		return "", nil
 	}
 
-	txt, err := decodeText(b[0], b[1:])
+	txt, err := decodeText(b[0], b[1:], o)
 	if err != nil {
 		return "", err
 	}
@@ -320,20 +327,20 @@ const (
 	encodingUTF8         byte = 3
 )
 
-func decodeText(enc byte, b []byte) (string, error) {
+func decodeText(enc byte, b []byte, o *readOptions) (string, error) {
 	if len(b) == 0 {
 		return "", nil
 	}
 
 	switch enc {
-	case encodingISO8859: // ISO-8859-1
-		return decodeISO8859(b), nil
+	case encodingISO8859: // ISO-8859-1, or the configured legacy charset
+		return o.decodeLegacy(b), nil
 
 	case encodingUTF16WithBOM: // UTF-16 with byte order marker
 		if len(b) == 1 {
 			return "", nil
 		}
-		return decodeUTF16WithBOM(b)
+		return decodeUTF16WithBOM(b, o.utf16ByteOrder())
 
 	case encodingUTF16: // UTF-16 without byte order (assuming BigEndian)
 		if len(b) == 1 {
@@ -385,12 +392,13 @@ func decodeISO8859(b []byte) string {
 	return string(r)
 }
 
-func decodeUTF16WithBOM(b []byte) (string, error) {
+// decodeUTF16WithBOM decodes UTF-16 text with a byte order mark, using the byte order bo
+// if the mark is missing.
+func decodeUTF16WithBOM(b []byte, bo binary.ByteOrder) (string, error) {
 	if len(b) < 2 {
-		return "", errors.New("invalid encoding: expected at least 2 bytes for UTF-16 byte order mark")
+		return "", fmt.Errorf("%w: expected at least 2 bytes for UTF-16 byte order mark", ErrInvalidEncoding)
 	}
 
-	var bo binary.ByteOrder
 	switch {
 	case b[0] == 0xFE && b[1] == 0xFF:
 		bo = binary.BigEndian
@@ -400,15 +408,13 @@ func decodeUTF16WithBOM(b []byte) (string, error) {
 		bo = binary.LittleEndian
 		b = b[2:]
 
-	default:
-		bo = DefaultUTF16WithBOMByteOrder
 	}
 	return decodeUTF16(b, bo)
 }
 
 func decodeUTF16(b []byte, bo binary.ByteOrder) (string, error) {
 	if len(b)%2 != 0 {
-		return "", errors.New("invalid encoding: expected even number of bytes for UTF-16 encoded text")
+		return "", fmt.Errorf("%w: expected even number of bytes for UTF-16 encoded text", ErrInvalidEncoding)
 	}
 	s := make([]uint16, 0, len(b)/2)
 	for i := 0; i < len(b); i += 2 {
@@ -451,9 +457,9 @@ func (t Comm) String() string {
 // Text encoding       $xx
 // Description         <text string according to encoding> $00 (00)
 // Value               <text string according to encoding>
-func readTextWithDescrFrame(b []byte, hasLang bool, encoded bool) (*Comm, error) {
+func readTextWithDescrFrame(b []byte, hasLang bool, encoded bool, o *readOptions) (*Comm, error) {
 	if len(b) == 0 {
-		return nil, errors.New("error decoding tag description text: invalid encoding")
+		return nil, fmt.Errorf("error decoding tag description text: %w", ErrInvalidEncoding)
 	}
 	enc := b[0]
 	b = b[1:]
@@ -461,7 +467,7 @@ func readTextWithDescrFrame(b []byte, hasLang bool, encoded bool) (*Comm, error)
 	c := &Comm{}
 	if hasLang {
 		if len(b) < 3 {
-			return nil, errors.New("hasLang set but not enough data for language information")
+			return nil, fmt.Errorf("%w: hasLang set but not enough data for language information", ErrTruncated)
 		}
 		c.Language = string(b[:3])
 		b = b[3:]
@@ -469,12 +475,12 @@ func readTextWithDescrFrame(b []byte, hasLang bool, encoded bool) (*Comm, error)
 
 	descTextSplit := dataSplit(b, enc)
 	if len(descTextSplit) == 0 {
-		return nil, errors.New("error decoding tag description text: invalid encoding")
+		return nil, fmt.Errorf("error decoding tag description text: %w", ErrInvalidEncoding)
 	}
 
-	desc, err := decodeText(enc, descTextSplit[0])
+	desc, err := decodeText(enc, descTextSplit[0], o)
 	if err != nil {
-		return nil, fmt.Errorf("error decoding tag description text: %v", err)
+		return nil, fmt.Errorf("error decoding tag description text: %w", err)
 	}
 	c.Description = desc
 
@@ -483,11 +489,12 @@ func readTextWithDescrFrame(b []byte, hasLang bool, encoded bool) (*Comm, error)
 	}
 
 	if !encoded {
-		enc = byte(0)
+		// URLs are always ISO-8859-1.
+		enc, o = byte(0), nil
 	}
-	text, err := decodeText(enc, descTextSplit[1])
+	text, err := decodeText(enc, descTextSplit[1], o)
 	if err != nil {
-		return nil, fmt.Errorf("error decoding tag text: %v", err)
+		return nil, fmt.Errorf("error decoding tag text: %w", err)
 	}
 	c.Text = text
 
@@ -508,7 +515,7 @@ func (u UFID) String() string {
 func readUFID(b []byte) (*UFID, error) {
 	result := bytes.SplitN(b, singleZero, 2)
 	if len(result) != 2 {
-		return nil, errors.New("expected to split UFID data into 2 pieces")
+		return nil, fmt.Errorf("%w: expected to split UFID data into 2 pieces", ErrMalformed)
 	}
 
 	return &UFID{
@@ -517,6 +524,631 @@ func readUFID(b []byte) (*UFID, error) {
 	}, nil
 }
 
+// SYLT timestamp formats.
+const (
+	SYLTMPEGFrames  byte = 1 // Timestamps are in MPEG frames.
+	SYLTMillisecond byte = 2 // Timestamps are in milliseconds.
+)
+
+// SYLT is a type which represents an ID3v2 synchronised lyrics/text frame (SYLT).
+type SYLT struct {
+	Language        string
+	TimestampFormat byte // SYLTMPEGFrames or SYLTMillisecond.
+	ContentType     byte // 0: other, 1: lyrics, 2: text transcription, 3: movement/part name, etc.
+	Descriptor      string
+	Lines           []SYLTLine
+}
+
+// SYLTLine is a line of text in a SYLT frame along with its timestamp.
+type SYLTLine struct {
+	Text      string
+	Timestamp uint32
+}
+
+// String returns a string representation of the underlying SYLT instance.
+func (t SYLT) String() string {
+	return fmt.Sprintf("SYLT{Lang: '%v', Descriptor: '%v', %v lines}", t.Language, t.Descriptor, len(t.Lines))
+}
+
+// cutTerminated splits b at the first null terminator for the text encoding (a 2-byte
+// aligned $00 00 for UTF-16), returning the text before it and the data after it.
+func cutTerminated(b []byte, enc byte) (text, rest []byte, ok bool) {
+	if enc != encodingUTF16 && enc != encodingUTF16WithBOM {
+		return bytes.Cut(b, singleZero)
+	}
+	for i := 0; i+1 < len(b); i += 2 {
+		if b[i] == 0 && b[i+1] == 0 {
+			return b[:i], b[i+2:], true
+		}
+	}
+	return b, nil, false
+}
+
+// IDv2.{3,4}
+// -- Header
+// <Header for 'Synchronised lyrics/text', ID: "SYLT">
+// -- readSYLTFrame
+// Text encoding       $xx
+// Language            $xx xx xx
+// Time stamp format   $xx
+// Content type        $xx
+// Content descriptor  <text string according to encoding> $00 (00)
+// -- for each line
+// Text                <text string according to encoding> $00 (00)
+// Time stamp          $xx xx xx xx
+func readSYLTFrame(b []byte, o *readOptions) (*SYLT, error) {
+	if len(b) < 6 {
+		return nil, fmt.Errorf("%w: expected at least 6 bytes for SYLT header, got %d", ErrTruncated, len(b))
+	}
+	enc := b[0]
+	t := &SYLT{
+		Language:        string(b[1:4]),
+		TimestampFormat: b[4],
+		ContentType:     b[5],
+	}
+
+	desc, b, ok := cutTerminated(b[6:], enc)
+	if !ok {
+		return nil, fmt.Errorf("%w: SYLT content descriptor is not terminated", ErrMalformed)
+	}
+	var err error
+	t.Descriptor, err = decodeText(enc, desc, o)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding SYLT content descriptor: %w", err)
+	}
+
+	for len(b) > 0 {
+		var text []byte
+		text, b, ok = cutTerminated(b, enc)
+		if !ok || len(b) < 4 {
+			return nil, fmt.Errorf("%w: SYLT line %d", ErrTruncated, len(t.Lines))
+		}
+		s, err := decodeText(enc, text, o)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding SYLT text: %w", err)
+		}
+		t.Lines = append(t.Lines, SYLTLine{
+			Text:      s,
+			Timestamp: binary.BigEndian.Uint32(b),
+		})
+		b = b[4:]
+	}
+	return t, nil
+}
+
+// RVA2 is a type which represents an ID3v2.4 relative volume adjustment frame (RVA2).
+type RVA2 struct {
+	Identification string // e.g. "track" or "album"
+	Channels       []RVA2Channel
+}
+
+// RVA2Channel is the volume adjustment of a channel in an RVA2 frame.
+type RVA2Channel struct {
+	Type       byte    // 0: other, 1: master volume, 2: front right, 3: front left, etc.
+	Adjustment float64 // Volume adjustment in dB.
+	Peak       float64 // Peak volume (1.0 is full scale), or 0 if not given.
+}
+
+// String returns a string representation of the underlying RVA2 instance.
+func (v RVA2) String() string {
+	return fmt.Sprintf("RVA2{%v, %v channels}", v.Identification, len(v.Channels))
+}
+
+// IDv2.4
+// -- Header
+// <Header for 'Relative volume adjustment (2)', ID: "RVA2">
+// -- readRVA2Frame
+// Identification          <text string> $00
+// -- for each channel
+// Type of channel         $xx
+// Volume adjustment       $xx xx
+// Bits representing peak  $xx
+// Peak volume             $xx (xx ...)
+func readRVA2Frame(b []byte) (*RVA2, error) {
+	id, b, err := readElementID(b)
+	if err != nil {
+		return nil, err
+	}
+	v := &RVA2{Identification: id}
+
+	for len(b) > 0 {
+		if len(b) < 4 {
+			return nil, fmt.Errorf("%w: RVA2 channel", ErrTruncated)
+		}
+		c := RVA2Channel{
+			Type:       b[0],
+			Adjustment: float64(int16(binary.BigEndian.Uint16(b[1:3]))) / 512,
+		}
+		bits := int(b[3])
+		n := (bits + 7) / 8
+		b = b[4:]
+		if len(b) < n {
+			return nil, fmt.Errorf("%w: RVA2 peak volume", ErrTruncated)
+		}
+		if bits > 0 && n <= 8 {
+			var peak uint64
+			for _, x := range b[:n] {
+				peak = peak<<8 | uint64(x)
+			}
+			c.Peak = float64(peak) / float64(uint64(1)<<(bits-1))
+		}
+		b = b[n:]
+		v.Channels = append(v.Channels, c)
+	}
+	return v, nil
+}
+
+// POPM is a type which represents an ID3v2 popularimeter frame (POPM).
+type POPM struct {
+	Email   string
+	Rating  byte   // 1 (worst) to 255 (best), 0 is unknown.
+	Counter uint64 // Play counter, 0 if omitted.
+}
+
+// String returns a string representation of the underlying POPM instance.
+func (p POPM) String() string {
+	return fmt.Sprintf("%v: rating %v, %v plays", p.Email, p.Rating, p.Counter)
+}
+
+// readCounter reads a play counter (at least 32 bits, big-endian).  Counters larger than
+// 64 bits are truncated to their low 64 bits.
+func readCounter(b []byte) uint64 {
+	var n uint64
+	for _, x := range b {
+		n = n<<8 | uint64(x)
+	}
+	return n
+}
+
+// IDv2.{3,4}
+// -- Header
+// <Header for 'Popularimeter', ID: "POPM">
+// -- readPOPMFrame
+// Email to user   <text string> $00
+// Rating          $xx
+// Counter         $xx xx xx xx (xx ...)
+func readPOPMFrame(b []byte) (*POPM, error) {
+	email, b, ok := bytes.Cut(b, singleZero)
+	if !ok || len(b) < 1 {
+		return nil, fmt.Errorf("%w: expected email and rating in POPM", ErrTruncated)
+	}
+	return &POPM{
+		Email:   decodeISO8859(email),
+		Rating:  b[0],
+		Counter: readCounter(b[1:]),
+	}, nil
+}
+
+// PCNT is a type which represents an ID3v2 play counter frame (PCNT).
+type PCNT struct {
+	Counter uint64
+}
+
+// String returns a string representation of the underlying PCNT instance.
+func (p PCNT) String() string {
+	return fmt.Sprintf("%v plays", p.Counter)
+}
+
+// IDv2.{3,4}
+// -- Header
+// <Header for 'Play counter', ID: "PCNT">
+// -- readPCNTFrame
+// Counter        $xx xx xx xx (xx ...)
+func readPCNTFrame(b []byte) (*PCNT, error) {
+	if len(b) < 4 {
+		return nil, fmt.Errorf("%w: expected at least 4 bytes for PCNT, got %d", ErrTruncated, len(b))
+	}
+	return &PCNT{Counter: readCounter(b)}, nil
+}
+
+// PRIV is a type which represents an ID3v2 private frame (PRIV).
+type PRIV struct {
+	Owner string // Owner identifier, e.g. "WM/MediaClassPrimaryID".
+	Data  []byte
+}
+
+// String returns a string representation of the underlying PRIV instance.
+func (p PRIV) String() string {
+	return fmt.Sprintf("%v (%v bytes)", p.Owner, len(p.Data))
+}
+
+// IDv2.{3,4}
+// -- Header
+// <Header for 'Private frame', ID: "PRIV">
+// -- readPRIVFrame
+// Owner identifier      <text string> $00
+// The private data      <binary data>
+func readPRIVFrame(b []byte) (*PRIV, error) {
+	owner, data, ok := bytes.Cut(b, singleZero)
+	if !ok {
+		return nil, fmt.Errorf("%w: PRIV owner identifier is not terminated", ErrMalformed)
+	}
+	return &PRIV{
+		Owner: decodeISO8859(owner),
+		Data:  data,
+	}, nil
+}
+
+// GEOB is a type which represents an ID3v2 general encapsulated object frame (GEOB).
+type GEOB struct {
+	MIMEType    string
+	Filename    string
+	Description string // Content description, e.g. "Serato Markers_".
+	Data        []byte
+}
+
+// String returns a string representation of the underlying GEOB instance.
+func (g GEOB) String() string {
+	return fmt.Sprintf("GEOB{%v, %v, %v bytes}", g.Description, g.MIMEType, len(g.Data))
+}
+
+// decodeTerminated decodes the terminated text at the start of b, returning the remaining data.
+func decodeTerminated(b []byte, enc byte, what string, o *readOptions) (string, []byte, error) {
+	text, rest, ok := cutTerminated(b, enc)
+	if !ok {
+		return "", nil, fmt.Errorf("%w: %v is not terminated", ErrMalformed, what)
+	}
+	s, err := decodeText(enc, text, o)
+	if err != nil {
+		return "", nil, fmt.Errorf("error decoding %v: %w", what, err)
+	}
+	return s, rest, nil
+}
+
+// IDv2.{3,4}
+// -- Header
+// <Header for 'General encapsulated object', ID: "GEOB">
+// -- readGEOBFrame
+// Text encoding          $xx
+// MIME type              <text string> $00
+// Filename               <text string according to encoding> $00 (00)
+// Content description    <text string according to encoding> $00 (00)
+// Encapsulated object    <binary data>
+func readGEOBFrame(b []byte, o *readOptions) (*GEOB, error) {
+	if len(b) < 1 {
+		return nil, fmt.Errorf("%w: GEOB text encoding", ErrTruncated)
+	}
+	enc := b[0]
+	mime, b, ok := bytes.Cut(b[1:], singleZero)
+	if !ok {
+		return nil, fmt.Errorf("%w: GEOB MIME type is not terminated", ErrMalformed)
+	}
+
+	g := &GEOB{MIMEType: decodeISO8859(mime)}
+	var err error
+	g.Filename, b, err = decodeTerminated(b, enc, "GEOB filename", o)
+	if err != nil {
+		return nil, err
+	}
+	g.Description, b, err = decodeTerminated(b, enc, "GEOB content description", o)
+	if err != nil {
+		return nil, err
+	}
+	g.Data = b
+	return g, nil
+}
+
+// MCDI is a type which represents an ID3v2 music CD identifier frame (MCDI), holding the
+// table of contents of the CD.
+type MCDI struct {
+	TOC        []byte      // Binary table of contents, as read from the CD.
+	FirstTrack byte        // First track number, if the TOC could be parsed.
+	LastTrack  byte        // Last track number, if the TOC could be parsed.
+	Tracks     []MCDITrack // Track descriptors (including the lead-out, track $AA).
+}
+
+// MCDITrack is a track descriptor in the table of contents of an MCDI frame.
+type MCDITrack struct {
+	Control byte   // ADR and control field.
+	Number  byte   // Track number ($AA for the lead-out).
+	Address uint32 // Start address (LBA).
+}
+
+// String returns a string representation of the underlying MCDI instance.
+func (m MCDI) String() string {
+	return fmt.Sprintf("MCDI{tracks %v-%v}", m.FirstTrack, m.LastTrack)
+}
+
+// IDv2.{3,4}
+// -- Header
+// <Header for 'Music CD identifier', ID: "MCDI">
+// -- readMCDIFrame
+// CD TOC                <binary data>
+// -- CD TOC (READ TOC format 0000b)
+// TOC data length       $xx xx
+// First track number    $xx
+// Last track number     $xx
+// -- for each track descriptor
+// Reserved              $xx
+// ADR/Control           $xx
+// Track number          $xx
+// Reserved              $xx
+// Track start address   $xx xx xx xx
+func readMCDIFrame(b []byte) (*MCDI, error) {
+	m := &MCDI{TOC: b}
+	if len(b) < 4 || (len(b)-4)%8 != 0 {
+		// Not in the READ TOC format; only the raw data is available.
+		return m, nil
+	}
+	m.FirstTrack, m.LastTrack = b[2], b[3]
+	for d := b[4:]; len(d) >= 8; d = d[8:] {
+		m.Tracks = append(m.Tracks, MCDITrack{
+			Control: d[1],
+			Number:  d[2],
+			Address: binary.BigEndian.Uint32(d[4:8]),
+		})
+	}
+	return m, nil
+}
+
+// ETCO is a type which represents an ID3v2 event timing codes frame (ETCO).
+type ETCO struct {
+	TimestampFormat byte // SYLTMPEGFrames or SYLTMillisecond.
+	Events          []ETCOEvent
+}
+
+// ETCOEvent is an event in an ETCO frame.
+type ETCOEvent struct {
+	Type      byte // e.g. $01: end of initial silence, $02: intro start, $03: main part start.
+	Timestamp uint32
+}
+
+// String returns a string representation of the underlying ETCO instance.
+func (e ETCO) String() string {
+	return fmt.Sprintf("ETCO{%v events}", len(e.Events))
+}
+
+// IDv2.{3,4}
+// -- Header
+// <Header for 'Event timing codes', ID: "ETCO">
+// -- readETCOFrame
+// Time stamp format    $xx
+// -- for each event
+// Type of event        $xx
+// Time stamp           $xx xx xx xx
+func readETCOFrame(b []byte) (*ETCO, error) {
+	if len(b) < 1 || (len(b)-1)%5 != 0 {
+		return nil, fmt.Errorf("%w: ETCO events", ErrTruncated)
+	}
+	e := &ETCO{TimestampFormat: b[0]}
+	for d := b[1:]; len(d) >= 5; d = d[5:] {
+		e.Events = append(e.Events, ETCOEvent{
+			Type:      d[0],
+			Timestamp: binary.BigEndian.Uint32(d[1:5]),
+		})
+	}
+	return e, nil
+}
+
+// OWNE is a type which represents an ID3v2 ownership frame (OWNE).
+type OWNE struct {
+	PricePaid    string // Currency code followed by the price, e.g. "USD9.99".
+	PurchaseDate string // YYYYMMDD
+	Seller       string
+}
+
+// String returns a string representation of the underlying OWNE instance.
+func (o OWNE) String() string {
+	return fmt.Sprintf("OWNE{%v, %v, %v}", o.Seller, o.PricePaid, o.PurchaseDate)
+}
+
+// IDv2.{3,4}
+// -- Header
+// <Header for 'Ownership frame', ID: "OWNE">
+// -- readOWNEFrame
+// Text encoding     $xx
+// Price paid        <text string> $00
+// Date of purch.    <text string> (8 characters, YYYYMMDD)
+// Seller            <text string according to encoding>
+func readOWNEFrame(b []byte, o *readOptions) (*OWNE, error) {
+	if len(b) < 1 {
+		return nil, fmt.Errorf("%w: OWNE text encoding", ErrTruncated)
+	}
+	enc := b[0]
+	price, b, ok := bytes.Cut(b[1:], singleZero)
+	if !ok || len(b) < 8 {
+		return nil, fmt.Errorf("%w: OWNE price and date of purchase", ErrTruncated)
+	}
+	seller, err := decodeText(enc, b[8:], o)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding OWNE seller: %w", err)
+	}
+	return &OWNE{
+		PricePaid:    decodeISO8859(price),
+		PurchaseDate: decodeISO8859(b[:8]),
+		Seller:       strings.TrimRight(seller, "\x00"),
+	}, nil
+}
+
+// COMR is a type which represents an ID3v2 commercial frame (COMR).
+type COMR struct {
+	Price       string // Currency code followed by the price, several prices separated by "/".
+	ValidUntil  string // YYYYMMDD
+	ContactURL  string
+	ReceivedAs  byte // 0: other, 1: standard CD album, 2: compressed audio on CD, 3: file over the Internet, etc.
+	Seller      string
+	Description string
+	Logo        *Picture // Seller logo, or nil if not present.
+}
+
+// String returns a string representation of the underlying COMR instance.
+func (c COMR) String() string {
+	return fmt.Sprintf("COMR{%v, %v, %v}", c.Seller, c.Price, c.Description)
+}
+
+// IDv2.{3,4}
+// -- Header
+// <Header for 'Commercial frame', ID: "COMR">
+// -- readCOMRFrame
+// Text encoding      $xx
+// Price string       <text string> $00
+// Valid until        <text string> (8 characters, YYYYMMDD)
+// Contact URL        <text string> $00
+// Received as        $xx
+// Name of seller     <text string according to encoding> $00 (00)
+// Description        <text string according to encoding> $00 (00)
+// Picture MIME type  <string> $00
+// Seller logo        <binary data>
+func readCOMRFrame(b []byte, o *readOptions) (*COMR, error) {
+	if len(b) < 1 {
+		return nil, fmt.Errorf("%w: COMR text encoding", ErrTruncated)
+	}
+	enc := b[0]
+	price, b, ok := bytes.Cut(b[1:], singleZero)
+	if !ok || len(b) < 8 {
+		return nil, fmt.Errorf("%w: COMR price and valid until date", ErrTruncated)
+	}
+	c := &COMR{
+		Price:      decodeISO8859(price),
+		ValidUntil: decodeISO8859(b[:8]),
+	}
+
+	url, b, ok := bytes.Cut(b[8:], singleZero)
+	if !ok || len(b) < 1 {
+		return nil, fmt.Errorf("%w: COMR contact URL and received as", ErrTruncated)
+	}
+	c.ContactURL = decodeISO8859(url)
+	c.ReceivedAs = b[0]
+
+	var err error
+	c.Seller, b, err = decodeTerminated(b[1:], enc, "COMR name of seller", o)
+	if err != nil {
+		return nil, err
+	}
+	c.Description, b, err = decodeTerminated(b, enc, "COMR description", o)
+	if err != nil {
+		return nil, err
+	}
+
+	// The seller logo is optional.
+	if mime, logo, ok := bytes.Cut(b, singleZero); ok && len(logo) > 0 {
+		c.Logo = &Picture{
+			MIMEType: decodeISO8859(mime),
+			Data:     logo,
+		}
+		if _, ext, ok := strings.Cut(c.Logo.MIMEType, "/"); ok {
+			c.Logo.Ext = ext
+		}
+	}
+	return c, nil
+}
+
+// Chap is a type which represents an ID3v2 chapter frame (CHAP).
+// See http://id3.org/id3v2-chapters-1.0
+type Chap struct {
+	ElementID   string
+	Start       time.Duration
+	End         time.Duration
+	StartOffset uint32                 // Byte offset of the start of the chapter, $FFFFFFFF if unused.
+	EndOffset   uint32                 // Byte offset of the end of the chapter, $FFFFFFFF if unused.
+	Frames      map[string]interface{} // Embedded frames, e.g. TIT2, WXXX, APIC.
+}
+
+// String returns a string representation of the underlying Chap instance.
+func (c Chap) String() string {
+	return fmt.Sprintf("%v (%v-%v)", c.ElementID, c.Start, c.End)
+}
+
+// CTOC is a type which represents an ID3v2 table of contents frame (CTOC).
+type CTOC struct {
+	ElementID string
+	TopLevel  bool
+	Ordered   bool
+	Children  []string               // Element IDs of the child CHAP and CTOC frames.
+	Frames    map[string]interface{} // Embedded frames, e.g. TIT2.
+}
+
+// String returns a string representation of the underlying CTOC instance.
+func (c CTOC) String() string {
+	return fmt.Sprintf("%v %v", c.ElementID, c.Children)
+}
+
+// readElementID reads a null-terminated element ID, returning the remaining data.
+func readElementID(b []byte) (string, []byte, error) {
+	i := bytes.IndexByte(b, 0)
+	if i < 0 {
+		return "", nil, fmt.Errorf("%w: expected null-terminated element ID", ErrMalformed)
+	}
+	return string(b[:i]), b[i+1:], nil
+}
+
+// IDv2.{3,4}
+// -- Header
+// <ID3v2.3 or ID3v2.4 frame header, ID: "CHAP">
+// -- readCHAPFrame
+// Element ID      <text string> $00
+// Start time      $xx xx xx xx
+// End time        $xx xx xx xx
+// Start offset    $xx xx xx xx
+// End offset      $xx xx xx xx
+// <Optional embedded sub-frames>
+func readCHAPFrame(b []byte, offset int64, h *id3v2Header, o *readOptions) (*Chap, error) {
+	id, t, err := readElementID(b)
+	if err != nil {
+		return nil, err
+	}
+	if len(t) < 16 {
+		return nil, fmt.Errorf("%w: expected at least 16 bytes for CHAP times, got %d", ErrTruncated, len(t))
+	}
+
+	sub := t[16:]
+	f, err := readEmbeddedID3v2Frames(sub, offset+int64(len(b)-len(sub)), h, o)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Chap{
+		ElementID:   id,
+		Start:       time.Duration(getInt(t[0:4])) * time.Millisecond,
+		End:         time.Duration(getInt(t[4:8])) * time.Millisecond,
+		StartOffset: binary.BigEndian.Uint32(t[8:12]),
+		EndOffset:   binary.BigEndian.Uint32(t[12:16]),
+		Frames:      f,
+	}, nil
+}
+
+// IDv2.{3,4}
+// -- Header
+// <ID3v2.3 or ID3v2.4 frame header, ID: "CTOC">
+// -- readCTOCFrame
+// Element ID      <text string> $00
+// CTOC flags      %000000ab
+// Entry count     $xx
+// Child element ID  <text string> $00 (repeated entry count times)
+// <Optional embedded sub-frames>
+func readCTOCFrame(b []byte, offset int64, h *id3v2Header, o *readOptions) (*CTOC, error) {
+	size := len(b)
+	id, b, err := readElementID(b)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < 2 {
+		return nil, fmt.Errorf("%w: expected at least 2 bytes for CTOC flags and entry count, got %d", ErrTruncated, len(b))
+	}
+
+	c := &CTOC{
+		ElementID: id,
+		TopLevel:  getBit(b[0], 1),
+		Ordered:   getBit(b[0], 0),
+	}
+	n := int(b[1])
+	b = b[2:]
+	for i := 0; i < n; i++ {
+		var child string
+		child, b, err = readElementID(b)
+		if err != nil {
+			return nil, err
+		}
+		c.Children = append(c.Children, child)
+	}
+
+	c.Frames, err = readEmbeddedID3v2Frames(b, offset+int64(size-len(b)), h, o)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
 var pictureTypes = map[byte]string{
 	0x00: "Other",
 	0x01: "32x32 pixels 'file icon' (PNG only)",
@@ -566,9 +1198,9 @@ func (p Picture) String() string {
 // Picture type       $xx
 // Description        <textstring> $00 (00)
 // Picture data       <binary data>
-func readPICFrame(b []byte) (*Picture, error) {
+func readPICFrame(b []byte, o *readOptions) (*Picture, error) {
 	if len(b) < 5 {
-		return nil, errors.New("invalid PIC frame")
+		return nil, fmt.Errorf("%w: invalid PIC frame", ErrTruncated)
 	}
 
 	enc := b[0]
@@ -577,11 +1209,11 @@ func readPICFrame(b []byte) (*Picture, error) {
 
 	descDataSplit := dataSplit(b[5:], enc)
 	if len(descDataSplit) != 2 {
-		return nil, errors.New("error decoding PIC description text: invalid encoding")
+		return nil, fmt.Errorf("error decoding PIC description text: %w", ErrInvalidEncoding)
 	}
-	desc, err := decodeText(enc, descDataSplit[0])
+	desc, err := decodeText(enc, descDataSplit[0], o)
 	if err != nil {
-		return nil, fmt.Errorf("error decoding PIC description text: %v", err)
+		return nil, fmt.Errorf("error decoding PIC description text: %w", err)
 	}
 
 	var mimeType string
@@ -610,31 +1242,31 @@ func readPICFrame(b []byte) (*Picture, error) {
 // Picture type    $xx
 // Description     <text string according to encoding> $00 (00)
 // Picture data    <binary data>
-func readAPICFrame(b []byte) (*Picture, error) {
+func readAPICFrame(b []byte, o *readOptions) (*Picture, error) {
 	if len(b) == 0 {
-		return nil, errors.New("error decoding APIC: invalid encoding")
+		return nil, fmt.Errorf("error decoding APIC: %w", ErrInvalidEncoding)
 	}
 	enc := b[0]
 	mimeDataSplit := bytes.SplitN(b[1:], singleZero, 2)
 	if len(mimeDataSplit) != 2 {
-		return nil, errors.New("error decoding APIC: invalid encoding")
+		return nil, fmt.Errorf("error decoding APIC: %w", ErrInvalidEncoding)
 	}
 
 	mimeType := string(mimeDataSplit[0])
 
 	b = mimeDataSplit[1]
 	if len(b) < 1 {
-		return nil, fmt.Errorf("error decoding APIC mimetype")
+		return nil, fmt.Errorf("error decoding APIC mimetype: %w", ErrTruncated)
 	}
 	picType := b[0]
 
 	descDataSplit := dataSplit(b[1:], enc)
 	if len(descDataSplit) != 2 {
-		return nil, errors.New("error decoding APIC description text: invalid encoding")
+		return nil, fmt.Errorf("error decoding APIC description text: %w", ErrInvalidEncoding)
 	}
-	desc, err := decodeText(enc, descDataSplit[0])
+	desc, err := decodeText(enc, descDataSplit[0], o)
 	if err != nil {
-		return nil, fmt.Errorf("error decoding APIC description text: %v", err)
+		return nil, fmt.Errorf("error decoding APIC description text: %w", err)
 	}
 
 	var ext string
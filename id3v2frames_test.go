@@ -0,0 +1,93 @@
+package tag
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestReadID3v2TypedFrames(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want interface{}
+	}{
+		{"POPM", "user@example.com\x00\xc4\x00\x00\x01\x00", &POPM{Email: "user@example.com", Rating: 196, Counter: 256}},
+		{"POPM", "\x00\x01", &POPM{Rating: 1}},
+		{"PCNT", "\x00\x00\x00\x2a", &PCNT{Counter: 42}},
+		{"PRIV", "WM/MediaClassPrimaryID\x00\xbc\x7d", &PRIV{Owner: "WM/MediaClassPrimaryID", Data: []byte{0xbc, 0x7d}}},
+		{
+			"GEOB",
+			"\x00application/octet-stream\x00\x00Serato Markers_\x00\x01\x01",
+			&GEOB{MIMEType: "application/octet-stream", Description: "Serato Markers_", Data: []byte{1, 1}},
+		},
+		{
+			"MCDI",
+			"\x00\x12\x01\x01\x00\x14\x01\x00\x00\x00\x00\x96\x00\x14\xaa\x00\x00\x00\x10\x00",
+			&MCDI{
+				TOC:        []byte("\x00\x12\x01\x01\x00\x14\x01\x00\x00\x00\x00\x96\x00\x14\xaa\x00\x00\x00\x10\x00"),
+				FirstTrack: 1,
+				LastTrack:  1,
+				Tracks:     []MCDITrack{{Control: 0x14, Number: 1, Address: 150}, {Control: 0x14, Number: 0xaa, Address: 4096}},
+			},
+		},
+		{"ETCO", "\x02\x03\x00\x00\x03\xe8", &ETCO{TimestampFormat: SYLTMillisecond, Events: []ETCOEvent{{Type: 3, Timestamp: 1000}}}},
+		{"OWNE", "\x00USD9.99\x0020240131Shop", &OWNE{PricePaid: "USD9.99", PurchaseDate: "20240131", Seller: "Shop"}},
+		{
+			"COMR",
+			"\x00EUR1.00\x0020301231http://example.com\x00\x03Shop\x00Album\x00image/png\x00\x89P",
+			&COMR{
+				Price:       "EUR1.00",
+				ValidUntil:  "20301231",
+				ContactURL:  "http://example.com",
+				ReceivedAs:  3,
+				Seller:      "Shop",
+				Description: "Album",
+				Logo:        &Picture{Ext: "png", MIMEType: "image/png", Data: []byte{0x89, 'P'}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := readID3v2Frame(&id3v2Header{Version: ID3v2_4}, tt.name, []byte(tt.in), 0, newReadOptions(nil))
+		if err != nil {
+			t.Errorf("readID3v2Frame(%q) = %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("readID3v2Frame(%q) = %#v, expected %#v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestReadID3v2TypedFramesTruncated(t *testing.T) {
+	for _, name := range []string{"POPM", "PCNT", "PRIV", "GEOB", "ETCO", "OWNE", "COMR"} {
+		if _, err := readID3v2Frame(&id3v2Header{Version: ID3v2_4}, name, []byte("xx"), 0, newReadOptions(nil)); err == nil {
+			t.Errorf("readID3v2Frame(%q) = nil error, expected error", name)
+		}
+	}
+}
+
+func TestID3v2UndecodableFrames(t *testing.T) {
+	// A POPM frame without the terminator after its email is kept as raw data.
+	b := id3v2FramesTag(3,
+		id3v23Frame("TIT2", []byte("\x00Title")),
+		id3v23Frame("POPM", []byte("user@example.com")),
+		id3v23Frame("TALB", []byte("\x00Album")),
+	)
+	m, err := ReadID3v2Tags(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("ReadID3v2Tags() = %v", err)
+	}
+	if m.Title() != "Title" || m.Album() != "Album" {
+		t.Errorf("Title(), Album() = %q, %q", m.Title(), m.Album())
+	}
+	if got, ok := m.Raw()["POPM"].([]byte); !ok || string(got) != "user@example.com" {
+		t.Errorf("Raw()[\"POPM\"] = %#v, expected raw data", m.Raw()["POPM"])
+	}
+
+	var warnings []error
+	if _, err := ReadID3v2Tags(bytes.NewReader(b), Lenient(&warnings)); err != nil || len(warnings) != 1 {
+		t.Errorf("lenient ReadID3v2Tags() = %v, warnings = %v", err, warnings)
+	}
+}
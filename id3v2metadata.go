@@ -5,52 +5,20 @@
 package tag
 
 import (
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 )
 
-type frameNames map[string][2]string
-
-func (f frameNames) Name(s string, fm Format) string {
-	l, ok := f[s]
-	if !ok {
-		return ""
-	}
-
-	switch fm {
-	case ID3v2_2:
-		return l[0]
-	case ID3v2_3:
-		return l[1]
-	case ID3v2_4:
-		if s == "year" {
-			return "TDRC"
-		}
-		return l[1]
-	}
-	return ""
-}
-
-var frames = frameNames(map[string][2]string{
-	"title":        [2]string{"TT2", "TIT2"},
-	"artist":       [2]string{"TP1", "TPE1"},
-	"album":        [2]string{"TAL", "TALB"},
-	"album_artist": [2]string{"TP2", "TPE2"},
-	"composer":     [2]string{"TCM", "TCOM"},
-	"year":         [2]string{"TYE", "TYER"},
-	"track":        [2]string{"TRK", "TRCK"},
-	"disc":         [2]string{"TPA", "TPOS"},
-	"genre":        [2]string{"TCO", "TCON"},
-	"picture":      [2]string{"PIC", "APIC"},
-	"lyrics":       [2]string{"", "USLT"},
-	"comment":      [2]string{"COM", "COMM"},
-})
-
 // metadataID3v2 is the implementation of Metadata used for ID3v2 tags.
 type metadataID3v2 struct {
 	header *id3v2Header
 	frames map[string]interface{}
+
+	// frameDuration is the duration of an MPEG audio frame, used for SYLT timestamps given in
+	// frames.  Set when the tag is read from an MP3 file.
+	frameDuration time.Duration
 }
 
 func (m metadataID3v2) getString(k string) string {
@@ -61,36 +29,71 @@ func (m metadataID3v2) getString(k string) string {
 	return v.(string)
 }
 
+// key returns the preferred frame name for the field.
+func (m metadataID3v2) key(f Field) string {
+	if keys := fieldKeys[f][m.Format()]; len(keys) > 0 {
+		return keys[0]
+	}
+	return ""
+}
+
+// seekOffset returns the offset of the next tag given by the SEEK frame, if any.
+func (m metadataID3v2) seekOffset() (int64, bool) {
+	b, ok := m.frames["SEEK"].([]byte)
+	if !ok || len(b) < 4 {
+		return 0, false
+	}
+	return m.header.Start + id3v2TagSize(m.header) + int64(getInt(b[:4])), true
+}
+
+// merge adds the frames of a later tag in the file.  Frames which are already present are
+// only replaced if the later tag is an update.
+func (m metadataID3v2) merge(next *metadataID3v2) {
+	update := next.header.Extended != nil && next.header.Extended.Update
+	for k, v := range next.frames {
+		if k == "SEEK" {
+			continue
+		}
+		if _, ok := m.frames[k]; !ok || update {
+			m.frames[k] = v
+		}
+	}
+}
+
+// ExtendedHeader returns the extended header of the tag, or nil if there is none.
+func (m metadataID3v2) ExtendedHeader() *ID3v2ExtendedHeader { return m.header.Extended }
+
 func (m metadataID3v2) Format() Format              { return m.header.Version }
 func (m metadataID3v2) FileType() FileType          { return MP3 }
 func (m metadataID3v2) Raw() map[string]interface{} { return m.frames }
+func (m metadataID3v2) Audio() *Audio               { return nil }
 
 func (m metadataID3v2) Title() string {
-	return m.getString(frames.Name("title", m.Format()))
+	return m.getString(m.key(FieldTitle))
 }
 
 func (m metadataID3v2) Artist() string {
-	return m.getString(frames.Name("artist", m.Format()))
+	return m.getString(m.key(FieldArtist))
 }
 
 func (m metadataID3v2) Album() string {
-	return m.getString(frames.Name("album", m.Format()))
+	return m.getString(m.key(FieldAlbum))
 }
 
 func (m metadataID3v2) AlbumArtist() string {
-	return m.getString(frames.Name("album_artist", m.Format()))
+	return m.getString(m.key(FieldAlbumArtist))
 }
 
 func (m metadataID3v2) Composer() string {
-	return m.getString(frames.Name("composer", m.Format()))
+	return m.getString(m.key(FieldComposer))
 }
 
 func (m metadataID3v2) Genre() string {
-	return id3v2genre(m.getString(frames.Name("genre", m.Format())))
+	return id3v2genre(m.getString(m.key(FieldGenre)))
 }
 
 func (m metadataID3v2) Year() int {
-	stringYear := m.getString(frames.Name("year", m.Format()))
+	stringYear := m.getString(m.key(FieldDate))
 
 	if year, err := strconv.Atoi(stringYear); err == nil {
 		return year
@@ -116,15 +119,15 @@ func parseXofN(s string) (x, n int) {
 }
 
 func (m metadataID3v2) Track() (int, int) {
-	return parseXofN(m.getString(frames.Name("track", m.Format())))
+	return parseXofN(m.getString(m.key(FieldTrack)))
 }
 
 func (m metadataID3v2) Disc() (int, int) {
-	return parseXofN(m.getString(frames.Name("disc", m.Format())))
+	return parseXofN(m.getString(m.key(FieldDisc)))
 }
 
 func (m metadataID3v2) Lyrics() string {
-	t, ok := m.frames[frames.Name("lyrics", m.Format())]
+	t, ok := m.frames[m.key(FieldLyrics)]
 	if !ok {
 		return ""
 	}
@@ -132,7 +135,7 @@ func (m metadataID3v2) Lyrics() string {
 }
 
 func (m metadataID3v2) Comment() string {
-	t, ok := m.frames[frames.Name("comment", m.Format())]
+	t, ok := m.frames[m.key(FieldComment)]
 	if !ok {
 		return ""
 	}
@@ -144,9 +147,282 @@ func (m metadataID3v2) Comment() string {
 }
 
 func (m metadataID3v2) Picture() *Picture {
-	v, ok := m.frames[frames.Name("picture", m.Format())]
+	v, ok := m.frames[m.key(FieldPicture)]
 	if !ok {
 		return nil
 	}
 	return v.(*Picture)
 }
+
+// Chapters returns the chapters given by the CHAP frames, in the order given by the
+// top-level CTOC frame (if any), otherwise by start time.
+func (m metadataID3v2) Chapters() []Chapter {
+	chaps := make(map[string]*Chap)
+	var toc *CTOC
+	for _, k := range sortedKeys(m.frames) {
+		switch v := m.frames[k].(type) {
+		case *Chap:
+			chaps[v.ElementID] = v
+		case *CTOC:
+			// Prefer the top-level CTOC, then the first by element ID.
+			if toc == nil || v.TopLevel && !toc.TopLevel || v.TopLevel == toc.TopLevel && v.ElementID < toc.ElementID {
+				toc = v
+			}
+		}
+	}
+	if len(chaps) == 0 {
+		return nil
+	}
+
+	var ordered []*Chap
+	if toc != nil && toc.Ordered {
+		for _, id := range toc.Children {
+			if c, ok := chaps[id]; ok {
+				ordered = append(ordered, c)
+				delete(chaps, id)
+			}
+		}
+	}
+	ids := make([]string, 0, len(chaps))
+	for id := range chaps {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	var rest []Chapter
+	for _, id := range ids {
+		rest = append(rest, id3v2Chapter(chaps[id]))
+	}
+	fillChapterEnds(rest, 0)
+
+	result := make([]Chapter, 0, len(ordered)+len(rest))
+	for _, c := range ordered {
+		result = append(result, id3v2Chapter(c))
+	}
+	return append(result, rest...)
+}
+
+// id3v2Chapter converts a CHAP frame (and its embedded frames) to a Chapter.
+func id3v2Chapter(c *Chap) Chapter {
+	ch := Chapter{
+		Start: c.Start,
+		End:   c.End,
+	}
+	// Frames are taken in order of their IDs, so the first URL frame is used.
+	for _, k := range sortedKeys(c.Frames) {
+		switch v := c.Frames[k].(type) {
+		case string:
+			switch {
+			case k == "TIT2":
+				ch.Title = v
+			case k[0] == 'W' && ch.URL == "":
+				ch.URL = v
+			}
+		case *Comm:
+			if strings.HasPrefix(k, "WXXX") && ch.URL == "" {
+				ch.URL = v.Text
+			}
+		case *Picture:
+			ch.Picture = v
+		}
+	}
+	return ch
+}
+
+// defaultMPEGFrameDuration is the duration of an MPEG-1 Layer III frame at 44.1 kHz, used
+// when the MPEG frame duration is unknown.
+const defaultMPEGFrameDuration = 1152 * time.Second / 44100
+
+// SyncedLyrics returns the lines of the SYLT frame (preferring the first with the lyrics
+// content type), or nil if there is none.
+func (m metadataID3v2) SyncedLyrics() []LyricLine {
+	var t *SYLT
+	for _, k := range sortedKeys(m.frames) {
+		if x, ok := m.frames[k].(*SYLT); ok && (t == nil || t.ContentType != 1 && x.ContentType == 1) {
+			t = x
+		}
+	}
+	if t == nil {
+		return nil
+	}
+
+	unit := time.Millisecond
+	if t.TimestampFormat == SYLTMPEGFrames {
+		unit = m.frameDuration
+		if unit == 0 {
+			unit = defaultMPEGFrameDuration
+		}
+	}
+
+	lines := make([]LyricLine, 0, len(t.Lines))
+	for _, l := range t.Lines {
+		lines = append(lines, LyricLine{
+			Time: time.Duration(l.Timestamp) * unit,
+			Text: l.Text,
+		})
+	}
+	return lines
+}
+
+// ReplayGain returns the gain given by REPLAYGAIN_* and R128_* TXXX frames, or otherwise
+// by the "track" and "album" RVA2 frames.
+func (m metadataID3v2) ReplayGain() *ReplayGain {
+	if g := replayGainFromFields(m.getTXXX); g != nil {
+		return g
+	}
+
+	var g *ReplayGain
+	for _, v := range m.frames {
+		rva2, ok := v.(*RVA2)
+		if !ok {
+			continue
+		}
+		for _, c := range rva2.Channels {
+			if c.Type != 1 {
+				// Only the master volume is used.
+				continue
+			}
+			if g == nil {
+				g = &ReplayGain{}
+			}
+			switch strings.ToLower(rva2.Identification) {
+			case "album":
+				g.AlbumGain, g.AlbumPeak = c.Adjustment, c.Peak
+			default:
+				g.TrackGain, g.TrackPeak = c.Adjustment, c.Peak
+			}
+		}
+	}
+	return g
+}
+
+// getTXXX returns the value of the TXXX frame with the given (case-insensitive) description.
+func (m metadataID3v2) getTXXX(desc string) string {
+	for k, v := range m.frames {
+		if c, ok := v.(*Comm); ok && strings.HasPrefix(k, "TXX") && strings.EqualFold(c.Description, desc) {
+			return c.Text
+		}
+	}
+	return ""
+}
+
+// Ratings returns the ratings of the POPM frames (one for each email), and the RATING and
+// FMPS_RATING TXXX frames.
+func (m metadataID3v2) Ratings() []Rating {
+	var ratings []Rating
+	for _, v := range m.frames {
+		if p, ok := v.(*POPM); ok {
+			if r, ok := popmRating(p.Rating); ok {
+				ratings = append(ratings, Rating{Value: r, Source: "POPM:" + p.Email})
+			}
+		}
+	}
+	sort.Slice(ratings, func(i, j int) bool {
+		return ratings[i].Source < ratings[j].Source
+	})
+	return append(ratings, ratingsFromFields(m.getTXXX)...)
+}
+
+// PlayCount returns the play count given by the PCNT frame, or otherwise the largest POPM
+// counter.
+func (m metadataID3v2) PlayCount() int {
+	for _, name := range []string{"PCNT", "CNT"} {
+		if p, ok := m.frames[name].(*PCNT); ok {
+			return clampCount(p.Counter)
+		}
+	}
+	var n uint64
+	for _, v := range m.frames {
+		if p, ok := v.(*POPM); ok && p.Counter > n {
+			n = p.Counter
+		}
+	}
+	if n > 0 {
+		return clampCount(n)
+	}
+	return playCountFromFields(m.getTXXX)
+}
+
+// Date returns the recording date (TDRC, or the release date TDRL) for ID3v2.4, and the
+// date given by TYER, TDAT and TIME for ID3v2.3 (TYE, TDA and TIM for ID3v2.2).
+func (m metadataID3v2) Date() Date {
+	if m.Format() == ID3v2_4 {
+		if d := parseDate(m.getString("TDRC")); !d.IsZero() {
+			return d
+		}
+		return parseDate(m.getString("TDRL"))
+	}
+
+	names := [3]string{"TYER", "TDAT", "TIME"}
+	if m.Format() == ID3v2_2 {
+		names = [3]string{"TYE", "TDA", "TIM"}
+	}
+	d := parseDate(m.getString(names[0]))
+	if d.IsZero() {
+		// Some taggers write ID3v2.4 frames in ID3v2.3 tags.
+		return parseDate(m.getString("TDRC"))
+	}
+
+	// TDAT is DDMM and TIME is HHMM.
+	dm := m.getString(names[1])
+	if len(dm) != 4 {
+		return d
+	}
+	day, ok := parseDateComponent(dm[0:2], 2, 1, 31)
+	month, ok2 := parseDateComponent(dm[2:4], 2, 1, 12)
+	if !ok || !ok2 {
+		return d
+	}
+	d.Month, d.Day = month, day
+
+	hm := m.getString(names[2])
+	if len(hm) != 4 {
+		return d
+	}
+	hour, ok := parseDateComponent(hm[0:2], 2, 0, 23)
+	minute, ok2 := parseDateComponent(hm[2:4], 2, 0, 59)
+	if ok && ok2 {
+		d.Hour, d.Minute, d.HasTime = hour, minute, true
+	}
+	return d
+}
+
+// OriginalDate returns the original release date given by TDOR (TORY for ID3v2.3, TOR for
+// ID3v2.2), or the ORIGINALDATE or ORIGINALYEAR TXXX frames.
+func (m metadataID3v2) OriginalDate() Date {
+	for _, name := range []string{"TDOR", "TORY", "TOR"} {
+		if d := parseDate(m.getString(name)); !d.IsZero() {
+			return d
+		}
+	}
+	if d := parseDate(m.getTXXX("originaldate")); !d.IsZero() {
+		return d
+	}
+	return parseDate(m.getTXXX("originalyear"))
+}
+
+// Get returns the text of the first frame (or TXXX frame) given for the field.
+func (m metadataID3v2) Get(f Field) string { return getField(m.Format(), m.frames, f) }
+
+func (m metadataID3v2) TitleSort() string       { return m.Get(FieldTitleSort) }
+func (m metadataID3v2) AlbumSort() string       { return m.Get(FieldAlbumSort) }
+func (m metadataID3v2) ArtistSort() string      { return m.Get(FieldArtistSort) }
+func (m metadataID3v2) AlbumArtistSort() string { return m.Get(FieldAlbumArtistSort) }
+func (m metadataID3v2) Key() string             { return m.Get(FieldKey) }
+func (m metadataID3v2) ISRC() string            { return m.Get(FieldISRC) }
+func (m metadataID3v2) Label() string           { return m.Get(FieldLabel) }
+func (m metadataID3v2) CatalogNumber() string   { return m.Get(FieldCatalogNumber) }
+func (m metadataID3v2) Copyright() string       { return m.Get(FieldCopyright) }
+func (m metadataID3v2) Encoder() string         { return m.Get(FieldEncoder) }
+func (m metadataID3v2) EncodedBy() string       { return m.Get(FieldEncodedBy) }
+func (m metadataID3v2) Grouping() string        { return m.Get(FieldGrouping) }
+func (m metadataID3v2) Conductor() string       { return m.Get(FieldConductor) }
+func (m metadataID3v2) Lyricist() string        { return m.Get(FieldLyricist) }
+func (m metadataID3v2) Mood() string            { return m.Get(FieldMood) }
+
+func (m metadataID3v2) BPM() int {
+	return parseBPM(m.Get(FieldBPM))
+}
+
+func (m metadataID3v2) Compilation() bool {
+	return parseBool(m.Get(FieldCompilation))
+}
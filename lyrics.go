@@ -0,0 +1,89 @@
+package tag
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LyricLine is a line of synchronised (timed) lyrics.
+type LyricLine struct {
+	Time time.Duration // Time at which the line starts.
+	Text string
+}
+
+// parseLRC parses lyrics in the LRC format, e.g. "[01:02.50]line of lyrics".  Lines with
+// several timestamps are repeated, word timestamps ("<01:02.80>") are removed and the
+// [offset:ms] tag is applied.  Returns nil if s contains no timestamped lines.
+func parseLRC(s string) []LyricLine {
+	var lines []LyricLine
+	var offset time.Duration
+	for _, l := range strings.Split(s, "\n") {
+		l = strings.TrimSpace(l)
+
+		var times []time.Duration
+		for strings.HasPrefix(l, "[") {
+			end := strings.IndexByte(l, ']')
+			if end < 0 {
+				break
+			}
+			tag := l[1:end]
+			l = l[end+1:]
+
+			if t, ok := parseClockTime(tag); ok && strings.Contains(tag, ":") {
+				times = append(times, t)
+				continue
+			}
+			if k, v, ok := strings.Cut(tag, ":"); ok && strings.EqualFold(strings.TrimSpace(k), "offset") {
+				// A positive offset shifts the lyrics up (i.e. earlier).
+				if ms, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+					offset = time.Duration(ms) * time.Millisecond
+				}
+			}
+		}
+
+		text := strings.TrimSpace(removeWordTimestamps(l))
+		for _, t := range times {
+			lines = append(lines, LyricLine{Time: t, Text: text})
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	for i := range lines {
+		lines[i].Time -= offset
+		if lines[i].Time < 0 {
+			lines[i].Time = 0
+		}
+	}
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].Time < lines[j].Time
+	})
+	return lines
+}
+
+// removeWordTimestamps removes enhanced LRC word timestamps ("<mm:ss.xx>") from s.
+func removeWordTimestamps(s string) string {
+	var b strings.Builder
+	for {
+		i := strings.IndexByte(s, '<')
+		if i < 0 {
+			break
+		}
+		j := strings.IndexByte(s[i:], '>')
+		if j < 0 {
+			break
+		}
+		if _, ok := parseClockTime(s[i+1 : i+j]); !ok {
+			b.WriteString(s[:i+j+1])
+			s = s[i+j+1:]
+			continue
+		}
+		b.WriteString(s[:i])
+		s = s[i+j+1:]
+	}
+	b.WriteString(s)
+	return b.String()
+}
@@ -0,0 +1,80 @@
+package tag
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseLRC(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []LyricLine
+	}{
+		{"no timestamps\nat all", nil},
+		{
+			"[ar:Artist]\n[00:01.50]First\n[00:10.00][01:00.00]Chorus\n[00:05.00]<00:05.00>Word <00:05.50>by word\n",
+			[]LyricLine{
+				{1500 * time.Millisecond, "First"},
+				{5 * time.Second, "Word by word"},
+				{10 * time.Second, "Chorus"},
+				{time.Minute, "Chorus"},
+			},
+		},
+		{
+			"[offset:+500]\n[00:01.00]Early",
+			[]LyricLine{{500 * time.Millisecond, "Early"}},
+		},
+	}
+
+	for ii, tt := range tests {
+		if got := parseLRC(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("[%d] parseLRC(%q) = %v, expected %v", ii, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSYLT(t *testing.T) {
+	data := []byte("\x00eng\x02\x01desc\x00")
+	data = append(data, "Hello\x00\x00\x00\x03\xe8"...)
+	data = append(data, "World\x00\x00\x00\x07\xd0"...)
+
+	m, err := ReadID3v2Tags(bytes.NewReader(id3v2FramesTag(3, id3v23Frame("SYLT", data))))
+	if err != nil {
+		t.Fatalf("ReadID3v2Tags() = %v", err)
+	}
+	want := []LyricLine{{time.Second, "Hello"}, {2 * time.Second, "World"}}
+	if got := m.SyncedLyrics(); !reflect.DeepEqual(got, want) {
+		t.Errorf("SyncedLyrics() = %v, expected %v", got, want)
+	}
+}
+
+func TestSYLTMultiple(t *testing.T) {
+	sylt := func(text string) []byte {
+		return id3v23Frame("SYLT", append([]byte("\x00eng\x02\x01\x00"), text+"\x00\x00\x00\x03\xe8"...))
+	}
+	b := id3v2FramesTag(3, sylt("First"), sylt("Second"), sylt("Third"))
+
+	// The first of the frames with the lyrics content type is used.
+	want := []LyricLine{{time.Second, "First"}}
+	for i := 0; i < 20; i++ {
+		m, err := ReadID3v2Tags(bytes.NewReader(b))
+		if err != nil {
+			t.Fatalf("ReadID3v2Tags() = %v", err)
+		}
+		if got := m.SyncedLyrics(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("SyncedLyrics() = %v, expected %v", got, want)
+		}
+	}
+}
+
+func TestVorbisSyncedLyrics(t *testing.T) {
+	m := newMetadataVorbis()
+	m.c["lyrics"] = "[00:02.00]Line"
+
+	want := []LyricLine{{2 * time.Second, "Line"}}
+	if got := m.SyncedLyrics(); !reflect.DeepEqual(got, want) {
+		t.Errorf("SyncedLyrics() = %v, expected %v", got, want)
+	}
+}
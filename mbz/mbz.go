@@ -96,8 +96,17 @@ func extractID3(m tag.Metadata) Info {
 func extractMP4Vorbis(m tag.Metadata) Info {
 	i := Info{}
 	for t, v := range m.Raw() {
-		if s, ok := v.(string); ok {
-			i.set(t, s)
+		// MP4 freeform atoms are named "----:mean:name".
+		if strings.HasPrefix(t, "----:") {
+			t = t[strings.LastIndexByte(t, ':')+1:]
+		}
+		switch v := v.(type) {
+		case string:
+			i.set(t, v)
+		case []string:
+			if len(v) > 0 {
+				i.set(t, v[0])
+			}
 		}
 	}
 	return i
@@ -0,0 +1,57 @@
+package mbz
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/zeozeozeo/tag"
+)
+
+// box builds an MP4 atom with the given body.
+func box(name string, body ...[]byte) []byte {
+	b := bytes.Join(body, nil)
+	return append(binary.BigEndian.AppendUint32(nil, uint32(len(b)+8)), append([]byte(name), b...)...)
+}
+
+// freeform builds an iTunes freeform (----) atom with a data atom for each value.
+func freeform(name string, values ...string) []byte {
+	atoms := [][]byte{
+		box("mean", make([]byte, 4), []byte("com.apple.iTunes")),
+		box("name", make([]byte, 4), []byte(name)),
+	}
+	for _, v := range values {
+		atoms = append(atoms, box("data", []byte{0, 0, 0, 1}, make([]byte, 4), []byte(v)))
+	}
+	return box("----", atoms...)
+}
+
+func TestExtractMP4(t *testing.T) {
+	hdlr := box("hdlr", make([]byte, 8), []byte("mdir"), make([]byte, 13))
+	ilst := box("ilst",
+		freeform("MusicBrainz Album Id", "f5e2ca1a-2e86-4b7e-9c4e-4a1e2a5b6c7d"),
+		freeform("MusicBrainz Artist Id", "b10bbbfc-cf9e-42e0-be17-e2c3e1d2600d", "ba550d0e-adac-4864-b88b-407cab5e76af"),
+		freeform("MusicBrainz Track Id", "0d3e4b5c-6f7a-4b8c-9d0e-1f2a3b4c5d6e"),
+	)
+	moov := box("moov", box("udta", box("meta", make([]byte, 4), hdlr, ilst)))
+	b := append(box("ftyp", []byte("M4A "), make([]byte, 4)), moov...)
+
+	m, err := tag.ReadFrom(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("ReadFrom() = %v", err)
+	}
+	want := Info{
+		Album:     "f5e2ca1a-2e86-4b7e-9c4e-4a1e2a5b6c7d",
+		Artist:    "b10bbbfc-cf9e-42e0-be17-e2c3e1d2600d",
+		Recording: "0d3e4b5c-6f7a-4b8c-9d0e-1f2a3b4c5d6e",
+	}
+	got := Extract(m)
+	if len(got) != len(want) {
+		t.Errorf("Extract() = %v, expected %v", got, want)
+	}
+	for k, v := range want {
+		if got.Get(k) != v {
+			t.Errorf("Extract().Get(%q) = %q, expected %q", k, got.Get(k), v)
+		}
+	}
+}
@@ -96,14 +96,68 @@ var (
 	}
 )
 
+var mpegCodecs = [layerMax]Codec{layer1: CodecMP1, layer2: CodecMP2, layer3: CodecMP3}
+
 type metadataV2MP3 struct {
 	*metadataID3v2
 	duration time.Duration
+	lame     *ReplayGain // ReplayGain from the LAME header
+	audio    *Audio
 }
 
 type metadataV1MP3 struct {
 	*metadataID3v1
 	duration time.Duration
+	lame     *ReplayGain // ReplayGain from the LAME header
+	audio    *Audio
+}
+
+// getMP3Audio returns the format of the MPEG frame with the given header, or nil if the
+// header is not valid.
+func getMP3Audio(header []byte) *Audio {
+	version, err := cutBits(header, 11, 2)
+	if err != nil {
+		return nil
+	}
+	layer, err := cutBits(header, 13, 2)
+	if err != nil || layer == uint64(layerReserved) {
+		return nil
+	}
+	samplerateIndex, err := cutBits(header, 20, 2)
+	if err != nil || samplerateIndex > 2 || sampleRates[version][samplerateIndex] == 0 {
+		return nil
+	}
+	channelMode, err := cutBits(header, 24, 2)
+	if err != nil {
+		return nil
+	}
+
+	a := &Audio{
+		Codec:      mpegCodecs[layer],
+		SampleRate: sampleRates[version][samplerateIndex],
+		Channels:   2,
+	}
+	if channelMode == 3 {
+		a.Channels = 1
+	}
+	return a
+}
+
+// getMP3FrameDuration returns the duration of the MPEG frame with the given header.
+func getMP3FrameDuration(header []byte) time.Duration {
+	version, err := cutBits(header, 11, 2)
+	if err != nil {
+		return 0
+	}
+	layer, err := cutBits(header, 13, 2)
+	if err != nil {
+		return 0
+	}
+	samplerateIndex, err := cutBits(header, 20, 2)
+	if err != nil || samplerateIndex > 2 || sampleRates[version][samplerateIndex] == 0 {
+		return 0
+	}
+	return time.Duration(samplesPerFrame[version][layer]) * time.Second / time.Duration(sampleRates[version][samplerateIndex])
 }
 
 func getMP3Duration(header []byte, strippedSize int64) (time.Duration, error) {
@@ -131,6 +185,9 @@ func getMP3Duration(header []byte, strippedSize int64) (time.Duration, error) {
 	if err != nil {
 		return 0, fmt.Errorf("reading mpeg padding bit: %w", err)
 	}
+	if samplerateIndex > 2 || sampleRates[version][samplerateIndex] == 0 {
+		return 0, nil
+	}
 	frameSampleNum := samplesPerFrame[version][layer]
 	frameDuration := float64(frameSampleNum) / float64(sampleRates[version][samplerateIndex])
 	frameSize := math.Floor(((frameDuration * float64(bitrates[version][layer][bitrateIndex])) * 1000) / 8)
@@ -142,22 +199,19 @@ func getMP3Duration(header []byte, strippedSize int64) (time.Duration, error) {
 	}
 	// add the header length
 	frameSize += 4
-	duration := time.Second * time.Duration(math.Round((float64(strippedSize)/float64(frameSize))*frameDuration))
+	duration := time.Duration(math.Round(float64(strippedSize) / frameSize * frameDuration * float64(time.Second)))
 
 	return duration, nil
 }
 
-func ReadV2MP3Meta(r io.ReadSeeker, size int64) (Metadata, error) {
-	tagMeta, err := ReadID3v2Tags(r)
+func ReadV2MP3Meta(r io.ReadSeeker, size int64, opts ...ReadOption) (Metadata, error) {
+	tagMeta, err := ReadID3v2Tags(r, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("reading id3v2 tags: %w", err)
 	}
 
-	id3Size := tagMeta.header.Size + 10
-	if tagMeta.header.FooterPresent {
-		id3Size += 10
-	}
-	_, err = r.Seek(int64(id3Size), io.SeekStart)
+	id3Size := id3v2TagSize(tagMeta.header)
+	_, err = r.Seek(id3Size, io.SeekStart)
 	if err != nil {
 		return nil, fmt.Errorf("seeking to skip id3v2: %w", err)
 
@@ -169,20 +223,23 @@ func ReadV2MP3Meta(r io.ReadSeeker, size int64) (Metadata, error) {
 		return nil, fmt.Errorf("reading first frame header: %w", err)
 	}
 
-	duration, err := getMP3Duration(header, size-int64(id3Size))
+	duration, err := getMP3Duration(header, size-id3Size)
 	if err != nil {
 		return nil, fmt.Errorf("reading the mp3 duration: %w", err)
 	}
+	tagMeta.frameDuration = getMP3FrameDuration(header)
 
 	return &metadataV2MP3{
 		metadataID3v2: tagMeta,
 		duration:      duration,
+		lame:          readLAMEReplayGain(r, id3Size),
+		audio:         getMP3Audio(header),
 	}, nil
 
 }
 
-func ReadV1MP3Meta(r io.ReadSeeker, size int64) (Metadata, error) {
-	tagMeta, err := ReadID3v1Tags(r)
+func ReadV1MP3Meta(r io.ReadSeeker, size int64, opts ...ReadOption) (Metadata, error) {
+	tagMeta, err := ReadID3v1Tags(r, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("reading id3v2 tags: %w", err)
 	}
@@ -207,10 +264,63 @@ func ReadV1MP3Meta(r io.ReadSeeker, size int64) (Metadata, error) {
 	return &metadataV1MP3{
 		metadataID3v1: &tagMeta,
 		duration:      duration,
+		lame:          readLAMEReplayGain(r, 0),
+		audio:         getMP3Audio(header),
 	}, nil
 
 }
 
+// readAppendedV2MP3Meta reads an ID3v2.4 tag appended to the end of an MP3 file, which is
+// located using its footer (before any ID3v1 tag).  Returns errNoID3v2Footer if there is
+// no appended tag.
+func readAppendedV2MP3Meta(r io.ReadSeeker, size int64, opts []ReadOption) (Metadata, error) {
+	end := size
+	if b, err := readBytesAt(r, size-128, 3); err == nil && string(b) == "TAG" {
+		end -= 128
+	}
+	start, err := findID3v2Footer(r, end)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := r.Seek(start, io.SeekStart); err != nil {
+		return nil, err
+	}
+	tagMeta, err := ReadID3v2Tags(r, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("reading id3v2 tags: %w", err)
+	}
+
+	header, err := readBytesAt(r, 0, 4)
+	if err != nil {
+		return nil, fmt.Errorf("reading first frame header: %w", err)
+	}
+
+	duration, err := getMP3Duration(header, start)
+	if err != nil {
+		return nil, fmt.Errorf("reading the mp3 duration: %w", err)
+	}
+	tagMeta.frameDuration = getMP3FrameDuration(header)
+
+	return &metadataV2MP3{
+		metadataID3v2: tagMeta,
+		duration:      duration,
+		lame:          readLAMEReplayGain(r, 0),
+		audio:         getMP3Audio(header),
+	}, nil
+}
+
+// readBytesAt reads n bytes at offset from the start of r.
+func readBytesAt(r io.ReadSeeker, offset int64, n uint) ([]byte, error) {
+	if offset < 0 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if _, err := r.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return readBytes(r, n)
+}
+
 func (m *metadataV2MP3) Duration() time.Duration {
 	return m.duration
 }
@@ -218,3 +328,24 @@ func (m *metadataV2MP3) Duration() time.Duration {
 func (m *metadataV1MP3) Duration() time.Duration {
 	return m.duration
 }
+
+// ReplayGain returns the gain given by the tag, or otherwise by the LAME header.
+func (m *metadataV2MP3) ReplayGain() *ReplayGain {
+	if g := m.metadataID3v2.ReplayGain(); g != nil {
+		return g
+	}
+	return m.lame
+}
+
+func (m *metadataV1MP3) ReplayGain() *ReplayGain {
+	return m.lame
+}
+
+// Audio returns the format of the first MPEG frame.
+func (m *metadataV2MP3) Audio() *Audio {
+	return m.audio
+}
+
+func (m *metadataV1MP3) Audio() *Audio {
+	return m.audio
+}
@@ -7,300 +7,579 @@ package tag
 import (
 	"bytes"
 	"encoding/binary"
-	"errors"
 	"fmt"
 	"io"
+	"math"
 	"strconv"
 	"strings"
 	"time"
+
+	"golang.org/x/text/encoding/japanese"
+)
+
+// Well-known type classes of data atoms.
+const (
+	mp4Implicit  = 0 // binary, or given by the atom name
+	mp4UTF8      = 1 // text
+	mp4UTF16     = 2 // big-endian text
+	mp4SJIS      = 3 // Shift JIS text
+	mp4UTF8Sort  = 4 // text for sorting
+	mp4UTF16Sort = 5 // big-endian text for sorting
+	mp4GIF       = 12
+	mp4JPEG      = 13
+	mp4PNG       = 14
+	mp4Int       = 21 // big-endian signed integer (1 to 8 bytes)
+	mp4Uint      = 22 // big-endian unsigned integer (1 to 8 bytes)
+	mp4Float32   = 23
+	mp4Float64   = 24
+	mp4BMP       = 27
 )
 
-var atomTypes = map[int]string{
-	0:  "implicit", // automatic based on atom name
-	1:  "text",
-	13: "jpeg",
-	14: "png",
-	21: "uint8",
-}
-
-// NB: atoms does not include "----", this is handled separately
-var atoms = atomNames(map[string]string{
-	"\xa9alb": "album",
-	"\xa9art": "artist",
-	"\xa9ART": "artist",
-	"aART":    "album_artist",
-	"\xa9day": "year",
-	"\xa9nam": "title",
-	"\xa9gen": "genre",
-	"trkn":    "track",
-	"\xa9wrt": "composer",
-	"\xa9too": "encoder",
-	"cprt":    "copyright",
-	"covr":    "picture",
-	"\xa9grp": "grouping",
-	"keyw":    "keyword",
-	"\xa9lyr": "lyrics",
-	"\xa9cmt": "comment",
-	"tmpo":    "tempo",
-	"cpil":    "compilation",
-	"disk":    "disc",
-})
-
-var means = map[string]bool{
-	"com.apple.iTunes":          true,
-	"com.mixedinkey.mixedinkey": true,
-	"com.serato.dj":             true,
+// mp4FixedInts are the type classes of fixed width integers, giving their width in bytes and
+// whether they are signed.
+var mp4FixedInts = map[int]struct {
+	width  int
+	signed bool
+}{
+	65: {1, true}, 66: {2, true}, 67: {4, true}, 74: {8, true},
+	75: {1, false}, 76: {2, false}, 77: {4, false}, 78: {8, false},
+}
+
+// mp4IntAtoms are atoms which hold integers, used when their data has the implicit class.
+// The values of some are:
+//   - stik (media kind): 1 music, 2 audiobook, 6 music video, 9 movie, 10 TV show, 11
+//     booklet, 14 ringtone, 21 podcast
+//   - rtng (content advisory): 0 none, 1 or 4 explicit, 2 clean
+//   - gnre: an ID3v1 genre index plus one
+var mp4IntAtoms = map[string]bool{
+	"tmpo": true, "cpil": true, "pgap": true, "pcst": true, "stik": true, "rtng": true,
+	"gnre": true, "hdvd": true, "tvsn": true, "tves": true, "shwm": true, "akID": true,
+	"cnID": true, "atID": true, "plID": true, "geID": true, "sfID": true, "cmID": true,
 }
 
 // Detect PNG image if "implicit" class is used
 var pngHeader = []byte{137, 80, 78, 71, 13, 10, 26, 10}
 
-type atomNames map[string]string
-
-func (f atomNames) Name(n string) []string {
-	res := make([]string, 1)
-	for k, v := range f {
-		if v == n {
-			res = append(res, k)
-		}
-	}
-	return res
-}
-
 // metadataMP4 is the implementation of Metadata for MP4 tag (atom) data.
 type metadataMP4 struct {
 	fileType FileType
 	data     map[string]interface{}
 	duration time.Duration
+	audio    *Audio
+	tracks   []*mp4Track
+	chapters []Chapter
+
+	trackData map[string]interface{} // items of track-level ilst atoms, see ReadAtoms
+	timescale uint32                 // from mvhd
+	fragments mp4Fragments
 }
 
 // ReadAtoms reads MP4 metadata atoms from the io.ReadSeeker into a Metadata, returning
 // non-nil error if there was a problem.
-func ReadAtoms(r io.ReadSeeker) (Metadata, error) {
+func ReadAtoms(r io.ReadSeeker, opts ...ReadOption) (Metadata, error) {
 	m := metadataMP4{
 		data:     make(map[string]interface{}),
 		fileType: UnknownFileType,
 	}
-	err := m.readAtoms(r)
-	return m, err
+	o := newReadOptions(opts)
+	err := m.readAtoms(r, "", -1, o)
+
+	// Track-level items only fill in those missing from the movie-level ilst.
+	for k, v := range m.trackData {
+		if _, ok := m.data[k]; !ok {
+			m.data[k] = v
+		}
+	}
+	if err != nil {
+		return m, err
+	}
+
+	for _, t := range m.tracks {
+		if t.audio != nil {
+			m.audio = t.audio
+			m.audio.Samples = m.fragments.trackSamples(t)
+			// The mdhd timescale of the sound track is usually its sample rate, which is more
+			// precise than the mvhd timescale.
+			if t.duration > 0 && t.timescale > 0 {
+				m.duration = mp4Duration(t.duration, t.timescale)
+			}
+			break
+		}
+	}
+	if m.duration == 0 {
+		m.duration = m.fragments.fragmentedDuration(m.timescale, m.tracks)
+	}
+	if m.audio != nil && m.audio.Codec == CodecALAC && (m.fileType == UnknownFileType || m.fileType == M4A) {
+		m.fileType = ALAC
+	}
+
+	chapters, err := m.readChapterTrack(r)
+	if err != nil {
+		if err := o.warn(newParseError(string(MP4), -1, "chap", err)); err != nil {
+			return m, err
+		}
+	}
+	if chapters != nil {
+		m.chapters = chapters
+	}
+	fillChapterEnds(m.chapters, m.duration)
+	return m, nil
 }
 
-func (m *metadataMP4) readAtoms(r io.ReadSeeker) error {
-	for {
-		name, size, err := readAtomHeader(r)
+// readAtoms reads the atoms in r up to end, the end of the enclosing atom (or -1 for the
+// end of the file).  If lenient parsing is enabled, atoms which cannot be read are skipped.
+func (m *metadataMP4) readAtoms(r io.ReadSeeker, parent string, end int64, o *readOptions) error {
+	for end < 0 || tell(r) < end {
+		offset := tell(r)
+		if end >= 0 && end-offset < 8 {
+			// Padding, e.g. the 32-bit terminator at the end of QuickTime udta atoms.
+			_, err := r.Seek(end, io.SeekStart)
+			return err
+		}
+
+		name, size, n, err := readAtomHeader(r)
 		if err != nil {
-			if err == io.EOF {
+			if err == io.EOF && end < 0 {
 				return nil
 			}
-			return err
+			return o.warn(newParseError(string(MP4), offset, name, err))
 		}
 
-		switch name {
-		case "meta":
-			// next_item_id (int32)
-			_, err := readBytes(r, 4)
-			if err != nil {
-				return err
+		switch {
+		case size == 0:
+			// The atom extends to the end of the enclosing atom or file.
+			atomEnd := end
+			if atomEnd < 0 {
+				if atomEnd, err = r.Seek(0, io.SeekEnd); err != nil {
+					return err
+				}
+				if _, err := r.Seek(offset+n, io.SeekStart); err != nil {
+					return err
+				}
 			}
-			fallthrough
+			size = atomEnd - offset
 
-		case "moov", "udta", "ilst":
-			return m.readAtoms(r)
-		case "mvhd":
-			_, err = r.Seek(12, io.SeekCurrent)
-			if err != nil {
-				return err
-			}
-			sampleRate, err := readUint32BigEndian(r)
-			if err != nil {
-				return err
-			}
-			sampleNum, err := readUint32BigEndian(r)
-			if err != nil {
-				return err
-			}
-			m.duration = time.Second * (time.Duration(sampleNum) / time.Duration(sampleRate))
+		case size < n:
+			err := fmt.Errorf("%w: invalid size %d", ErrMalformed, size)
+			return o.warn(newParseError(string(MP4), offset, name, err))
 
-			_, err = r.Seek(int64(size-8-12-8), io.SeekCurrent)
-			if err != nil {
+		case end >= 0 && offset+size > end:
+			err := fmt.Errorf("%w: size %d exceeds %q atom", ErrMalformed, size, parent)
+			if err := o.warn(newParseError(string(MP4), offset, name, err)); err != nil {
 				return err
 			}
-			continue
+			size = end - offset
 		}
 
-		_, ok := atoms[name]
-		var data []string
-		if name == "----" {
-			name, data, err = readCustomAtom(r, size)
-			if err != nil {
+		err = m.readAtom(r, parent, name, size-n, o)
+		if err != nil {
+			if err := o.warn(newParseError(string(MP4), offset, name, err)); err != nil {
 				return err
 			}
+		}
 
-			if name != "----" {
-				ok = true
-				size = 0 // already read data
-			}
+		// Skip anything left in the atom (or the rest of a bad atom).
+		if _, err := r.Seek(offset+size, io.SeekStart); err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		if !ok {
-			_, err := r.Seek(int64(size-8), io.SeekCurrent)
-			if err != nil {
-				return err
-			}
-			continue
+// readAtom reads the body of the atom with the given name and (body) size, found in the
+// parent atom.
+func (m *metadataMP4) readAtom(r io.ReadSeeker, parent, name string, size int64, o *readOptions) error {
+	if parent == "ilst" {
+		return m.readItemAtom(r, name, size)
+	}
+
+	start := tell(r)
+	switch name {
+	case "meta":
+		// meta is a full box (version and flags), except in QuickTime files.
+		b, err := readBytes(r, uint(min(size, 8)))
+		if err != nil {
+			return err
+		}
+		end := start + size
+		if len(b) < 8 || string(b[4:8]) != "hdlr" {
+			start += 4
+		}
+		if _, err := r.Seek(start, io.SeekStart); err != nil {
+			return err
 		}
+		return m.readAtoms(r, name, end, o)
+
+	case "moov", "udta", "ilst":
+		return m.readAtoms(r, name, start+size, o)
 
-		err = m.readAtomData(r, name, size-8, data)
+	case "trak":
+		b, err := readBytes(r, uint(size))
 		if err != nil {
 			return err
 		}
-	}
-}
+		// A bad track only loses its audio details and chapters, not the tags.
+		t, err := readMP4Track(b)
+		if err != nil {
+			o.warn(newParseError(string(MP4), start, name, err))
+		} else {
+			m.tracks = append(m.tracks, t)
+		}
+
+		// Track-level metadata (trak/udta/meta/ilst), kept apart from the movie-level items.
+		// The first track with an item gives its value.
+		if _, err := r.Seek(start, io.SeekStart); err != nil {
+			return err
+		}
+		data := m.data
+		m.data = make(map[string]interface{})
+		err = m.readAtoms(r, name, start+size, o)
+		if m.trackData == nil {
+			m.trackData = make(map[string]interface{})
+		}
+		for k, v := range m.data {
+			if _, ok := m.trackData[k]; !ok {
+				m.trackData[k] = v
+			}
+		}
+		m.data = data
+		return err
 
-func (m *metadataMP4) readAtomData(r io.ReadSeeker, name string, size uint32, processedData []string) error {
-	var b []byte
-	var err error
-	var contentType string
-	if len(processedData) > 0 {
-		b = []byte(strings.Join(processedData, ";")) // add delimiter if multiple data fields
-		contentType = "text"
-	} else {
-		// read the data
-		b, err = readBytes(r, uint(size))
+	case "ftyp":
+		b, err := readBytes(r, uint(size))
 		if err != nil {
 			return err
 		}
-		if len(b) < 8 {
-			return fmt.Errorf("invalid encoding: expected at least %d bytes, got %d", 8, len(b))
+		m.fileType = mp4FileType(b)
+
+	case "mvex":
+		b, err := readBytes(r, uint(size))
+		if err != nil {
+			return err
 		}
+		return m.fragments.readMvexAtom(b)
 
-		// "data" + size (4 bytes each)
-		b = b[8:]
+	case "moof":
+		b, err := readBytes(r, uint(size))
+		if err != nil {
+			return err
+		}
+		return m.fragments.readMoofAtom(b)
 
-		if len(b) < 4 {
-			return fmt.Errorf("invalid encoding: expected at least %d bytes, for class, got %d", 4, len(b))
+	case "sidx":
+		b, err := readBytes(r, uint(size))
+		if err != nil {
+			return err
 		}
-		class := getInt(b[1:4])
-		var ok bool
-		contentType, ok = atomTypes[class]
-		if !ok {
-			return fmt.Errorf("invalid content type: %v (%x) (%x)", class, b[1:4], b)
+		d, err := readSidxAtom(b)
+		m.fragments.sidx += d
+		return err
+
+	case "chpl":
+		b, err := readBytes(r, uint(size))
+		if err != nil {
+			return err
 		}
+		m.chapters, err = readChplAtom(b)
+		return err
 
-		// 4: atom version (1 byte) + atom flags (3 bytes)
-		// 4: NULL (usually locale indicator)
-		if len(b) < 8 {
-			return fmt.Errorf("invalid encoding: expected at least %d bytes, for atom version and flags, got %d", 8, len(b))
+	case "mvhd":
+		// version (1 byte), flags (3 bytes), creation and modification times (4 or 8 bytes
+		// each), timescale (4 bytes), duration (4 or 8 bytes)
+		b, err := readBytes(r, uint(min(size, 32)))
+		if err != nil {
+			return err
 		}
-		b = b[8:]
+		n := 12
+		if len(b) > 0 && b[0] == 1 {
+			n = 20
+		}
+		if len(b) < n+8 || (b[0] == 1 && len(b) < n+12) {
+			return fmt.Errorf("%w: mvhd", ErrTruncated)
+		}
+		timescale := binary.BigEndian.Uint32(b[n:])
+		duration := uint64(binary.BigEndian.Uint32(b[n+4:]))
+		if b[0] == 1 {
+			duration = binary.BigEndian.Uint64(b[n+4:])
+		}
+		m.timescale = timescale
+		m.duration = mp4Duration(duration, timescale)
 	}
+	return nil
+}
 
-	if name == "trkn" || name == "disk" {
-		if len(b) < 6 {
-			return fmt.Errorf("invalid encoding: expected at least %d bytes, for track and disk numbers, got %d", 6, len(b))
+// mp4FileType returns the file type given by the major brand, or else the compatible brands,
+// of an ftyp atom.
+func mp4FileType(b []byte) FileType {
+	// major brand (4 bytes), minor version (4 bytes), compatible brands (4 bytes each)
+	brands := make([]string, 0, len(b)/4)
+	for i := 0; i+4 <= len(b); i += 4 {
+		if i != 4 {
+			brands = append(brands, string(b[i:i+4]))
 		}
+	}
+	for _, brand := range brands {
+		switch brand {
+		case "M4A ":
+			return M4A
+		case "M4B ":
+			return M4B
+		case "M4P ":
+			return M4P
+		}
+	}
+	return UnknownFileType
+}
 
-		m.data[name] = int(b[3])
-		m.data[name+"_count"] = int(b[5])
+// readItemAtom reads the body of an ilst item atom.
+func (m *metadataMP4) readItemAtom(r io.ReadSeeker, name string, size int64) error {
+	if name == "----" {
+		key, values, err := readCustomAtom(r, size)
+		if err != nil || len(values) == 0 {
+			return err
+		}
+		m.data[key] = itemValue(values)
 		return nil
 	}
+	return m.readAtomData(r, name, size)
+}
 
-	if contentType == "implicit" {
-		if name == "covr" {
-			if bytes.HasPrefix(b, pngHeader) {
-				contentType = "png"
-			}
-			// TODO(dhowden): Detect JPEG formats too (harder).
+// itemValue returns the raw value of an item atom: the value of its data atom, or if there is
+// more than one, a []string of their text (or []interface{} if they aren't all text).
+func itemValue(values []interface{}) interface{} {
+	if len(values) == 1 {
+		return values[0]
+	}
+	text := make([]string, 0, len(values))
+	for _, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			return values
 		}
+		text = append(text, s)
 	}
+	return text
+}
 
-	var data interface{}
-	switch contentType {
-	case "implicit":
-		if _, ok := atoms[name]; ok {
-			return fmt.Errorf("unhandled implicit content type for required atom: %q", name)
-		}
-		return nil
+// readAtomData reads the data atoms of the item atom with the given name.  Multiple pictures
+// (covr) are named covr, covr_0, covr_1 and so on.
+func (m *metadataMP4) readAtomData(r io.ReadSeeker, name string, size int64) error {
+	b, err := readBytes(r, uint(size))
+	if err != nil {
+		return err
+	}
 
-	case "text":
-		data = string(b)
+	var values []interface{}
+	err = readMP4Boxes(b, func(box string, b []byte) error {
+		if box != "data" {
+			return nil
+		}
+		// 4: atom version (1 byte) + type class (3 bytes)
+		// 4: NULL (usually locale indicator)
+		if len(b) < 8 {
+			return fmt.Errorf("%w: expected at least %d bytes, for atom version and flags, got %d", ErrTruncated, 8, len(b))
+		}
+		class := getInt(b[1:4])
+		b = b[8:]
 
-	case "uint8":
-		if len(b) < 1 {
-			return fmt.Errorf("invalid encoding: expected at least %d bytes, for integer tag data, got %d", 1, len(b))
+		if name == "trkn" || name == "disk" {
+			// reserved (2 bytes), number (2 bytes), total (2 bytes), [reserved (2 bytes)]
+			if len(b) < 6 {
+				return fmt.Errorf("%w: expected at least %d bytes, for track and disk numbers, got %d", ErrTruncated, 6, len(b))
+			}
+			values = append(values, int(binary.BigEndian.Uint16(b[2:])), int(binary.BigEndian.Uint16(b[4:])))
+			return nil
 		}
-		data = getInt(b[:1])
 
-	case "jpeg", "png":
-		data = &Picture{
-			Ext:      contentType,
-			MIMEType: "image/" + contentType,
-			Data:     b,
+		if class == mp4Implicit {
+			switch {
+			case name == "covr":
+				class = imageClass(b)
+			case mp4IntAtoms[name]:
+				class = mp4Uint
+			case strings.HasPrefix(name, "\xa9"):
+				// The \xa9 (copyright sign) atoms hold text.
+				class = mp4UTF8
+			}
+		}
+		v, err := mp4DataValue(class, b)
+		if err != nil {
+			// Items which aren't in the field registry are kept as they are.
+			if _, ok := FieldFor(MP4, name); ok {
+				return err
+			}
+			v = b
 		}
+		values = append(values, v)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(values) == 0 {
+		return nil
 	}
-	m.data[name] = data
 
+	switch name {
+	case "trkn", "disk":
+		m.data[name] = values[0]
+		m.data[name+"_count"] = values[1]
+	case "covr":
+		m.data[name] = values[0]
+		for i, v := range values[1:] {
+			m.data[name+"_"+strconv.Itoa(i)] = v
+		}
+	default:
+		m.data[name] = itemValue(values)
+	}
 	return nil
 }
 
-func readAtomHeader(r io.ReadSeeker) (name string, size uint32, err error) {
-	err = binary.Read(r, binary.BigEndian, &size)
+// imageClass returns the type class of the image in b, detected from its signature.
+func imageClass(b []byte) int {
+	switch {
+	case bytes.HasPrefix(b, pngHeader):
+		return mp4PNG
+	case bytes.HasPrefix(b, []byte{0xff, 0xd8, 0xff}):
+		return mp4JPEG
+	case bytes.HasPrefix(b, []byte("GIF8")):
+		return mp4GIF
+	case bytes.HasPrefix(b, []byte("BM")):
+		return mp4BMP
+	}
+	return mp4Implicit
+}
+
+// readAtomHeader reads an atom header, returning the name, the size of the atom and the size
+// of the header.  A 32-bit size of 1 is followed by a 64-bit size, and a size of 0 means the
+// atom extends to the end of the enclosing atom or file.
+func readAtomHeader(r io.ReadSeeker) (name string, size, headerSize int64, err error) {
+	var size32 uint32
+	err = binary.Read(r, binary.BigEndian, &size32)
 	if err != nil {
 		return
 	}
 	name, err = readString(r, 4)
-	return
+	if err != nil || size32 != 1 {
+		return name, int64(size32), 8, err
+	}
+
+	var size64 uint64
+	err = binary.Read(r, binary.BigEndian, &size64)
+	if err == nil && size64 > math.MaxInt64 {
+		err = fmt.Errorf("%w: invalid size %d", ErrMalformed, size64)
+	}
+	return name, int64(size64), 16, err
 }
 
-// Generic atom.
-// Should have 3 sub atoms : mean, name and data.
-// We check that mean is "com.apple.iTunes" or others and we use the subname as
-// the name, and move to the data atom.
-// Data atom could have multiple data values, each with a header.
-// If anything goes wrong, we jump at the end of the "----" atom.
-func readCustomAtom(r io.ReadSeeker, size uint32) (_ string, data []string, _ error) {
+// readCustomAtom reads the body of a freeform (----) atom, which has mean, name and data
+// atoms (possibly more than one of the latter).  Returns the key "----:mean:name" and the
+// value of each data atom, or no values if the name is missing.
+func readCustomAtom(r io.ReadSeeker, size int64) (key string, values []interface{}, _ error) {
 	subNames := make(map[string]string)
 
-	for size > 8 {
-		subName, subSize, err := readAtomHeader(r)
+	for size > 0 {
+		subName, subSize, n, err := readAtomHeader(r)
 		if err != nil {
 			return "", nil, err
 		}
 
 		// Remove the size of the atom from the size counter
-		if size >= subSize {
+		if subSize >= n && size >= subSize {
 			size -= subSize
 		} else {
-			return "", nil, errors.New("--- invalid size")
+			return "", nil, fmt.Errorf("%w: ---- invalid size", ErrMalformed)
 		}
 
-		b, err := readBytes(r, uint(subSize-8))
+		b, err := readBytes(r, uint(subSize-n))
 		if err != nil {
 			return "", nil, err
 		}
 
 		if len(b) < 4 {
-			return "", nil, fmt.Errorf("invalid encoding: expected at least %d bytes, got %d", 4, len(b))
+			return "", nil, fmt.Errorf("%w: expected at least %d bytes, got %d", ErrTruncated, 4, len(b))
 		}
 		switch subName {
 		case "mean", "name":
 			subNames[subName] = string(b[4:])
 		case "data":
-			data = append(data, string(b[4:]))
+			// type (4 bytes), locale (4 bytes)
+			if len(b) < 8 {
+				return "", nil, fmt.Errorf("%w: expected at least %d bytes, got %d", ErrTruncated, 8, len(b))
+			}
+			v, err := mp4DataValue(getInt(b[1:4]), b[8:])
+			if err != nil {
+				return "", nil, err
+			}
+			values = append(values, v)
 		}
 	}
 
-	// there should remain only the header size
-	if size != 8 {
-		err := errors.New("---- atom out of bounds")
-		return "", nil, err
+	if subNames["name"] == "" {
+		return "", nil, nil
 	}
+	return "----:" + subNames["mean"] + ":" + subNames["name"], values, nil
+}
+
+// mp4DataValue returns the value of data with the given type class: a string for text, an int
+// for integers, a float64 for floating point numbers, a *Picture for images, or else the bytes.
+func mp4DataValue(class int, b []byte) (interface{}, error) {
+	switch class {
+	case mp4UTF8, mp4UTF8Sort:
+		return string(b), nil
+
+	case mp4UTF16, mp4UTF16Sort:
+		return decodeUTF16(b, binary.BigEndian)
 
-	if !means[subNames["mean"]] || subNames["name"] == "" || len(data) == 0 {
-		return "----", nil, nil
+	case mp4SJIS:
+		s, err := japanese.ShiftJIS.NewDecoder().Bytes(b)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidEncoding, err)
+		}
+		return string(s), nil
+
+	case mp4Int, mp4Uint:
+		if len(b) == 0 || len(b) > 8 {
+			return nil, fmt.Errorf("%w: %d byte integer", ErrInvalidEncoding, len(b))
+		}
+		n := getInt(b)
+		if class == mp4Int && len(b) < 8 && b[0]&0x80 != 0 {
+			n -= 1 << (8 * len(b))
+		}
+		return n, nil
+
+	case mp4Float32:
+		if len(b) != 4 {
+			return nil, fmt.Errorf("%w: %d byte float", ErrInvalidEncoding, len(b))
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), nil
+
+	case mp4Float64:
+		if len(b) != 8 {
+			return nil, fmt.Errorf("%w: %d byte float", ErrInvalidEncoding, len(b))
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+
+	case mp4GIF, mp4JPEG, mp4PNG, mp4BMP:
+		ext := map[int]string{mp4GIF: "gif", mp4JPEG: "jpeg", mp4PNG: "png", mp4BMP: "bmp"}[class]
+		return &Picture{
+			Ext:      ext,
+			MIMEType: "image/" + ext,
+			Data:     b,
+		}, nil
 	}
 
-	return subNames["name"], data, nil
+	if x, ok := mp4FixedInts[class]; ok {
+		if len(b) != x.width {
+			return nil, fmt.Errorf("%w: %d byte integer, expected %d", ErrInvalidEncoding, len(b), x.width)
+		}
+		class = mp4Uint
+		if x.signed {
+			class = mp4Int
+		}
+		return mp4DataValue(class, b)
+	}
+	return b, nil
 }
 
 func (metadataMP4) Format() Format       { return MP4 }
@@ -308,10 +587,16 @@ func (m metadataMP4) FileType() FileType { return m.fileType }
 
 func (m metadataMP4) Raw() map[string]interface{} { return m.data }
 
+// rawValues returns each value of the item atom k, if it has more than one.
+func (m metadataMP4) rawValues(k string) []string {
+	v, _ := m.data[k].([]string)
+	return v
+}
+
 func (m metadataMP4) getString(n []string) string {
 	for _, k := range n {
 		if x, ok := m.data[k]; ok {
-			return x.(string)
+			return textValue(x)
 		}
 	}
 	return ""
@@ -319,39 +604,47 @@ func (m metadataMP4) getString(n []string) string {
 
 func (m metadataMP4) getInt(n []string) int {
 	for _, k := range n {
-		if x, ok := m.data[k]; ok {
-			return x.(int)
+		if x, ok := m.data[k].(int); ok {
+			return x
 		}
 	}
 	return 0
 }
 
 func (m metadataMP4) Title() string {
-	return m.getString(atoms.Name("title"))
+	return m.getString(fieldKeys[FieldTitle][MP4])
 }
 
 func (m metadataMP4) Artist() string {
-	return m.getString(atoms.Name("artist"))
+	return m.getString(fieldKeys[FieldArtist][MP4])
 }
 
 func (m metadataMP4) Album() string {
-	return m.getString(atoms.Name("album"))
+	return m.getString(fieldKeys[FieldAlbum][MP4])
 }
 
 func (m metadataMP4) AlbumArtist() string {
-	return m.getString(atoms.Name("album_artist"))
+	return m.getString(fieldKeys[FieldAlbumArtist][MP4])
 }
 
 func (m metadataMP4) Composer() string {
-	return m.getString(atoms.Name("composer"))
+	return m.getString(fieldKeys[FieldComposer][MP4])
 }
 
+// Genre returns the genre given by the \xa9gen atom, or else the ID3v1 genre given by the
+// gnre atom.
 func (m metadataMP4) Genre() string {
-	return m.getString(atoms.Name("genre"))
+	if g := m.getString(fieldKeys[FieldGenre][MP4]); g != "" {
+		return g
+	}
+	if n, ok := m.data["gnre"].(int); ok && n > 0 && n <= len(id3v1Genres) {
+		return id3v1Genres[n-1]
+	}
+	return ""
 }
 
 func (m metadataMP4) Year() int {
-	date := m.getString(atoms.Name("year"))
+	date := m.getString(fieldKeys[FieldDate][MP4])
 	if len(date) >= 4 {
 		year, _ := strconv.Atoi(date[:4])
 		return year
@@ -359,36 +652,98 @@ func (m metadataMP4) Year() int {
 	return 0
 }
 
-func (m metadataMP4) Track() (int, int) {
-	x := m.getInt([]string{"trkn"})
-	if n, ok := m.data["trkn_count"]; ok {
-		return x, n.(int)
+// Date returns the date given by the \xa9day atom.
+func (m metadataMP4) Date() Date {
+	return parseDate(m.getString(fieldKeys[FieldDate][MP4]))
+}
+
+// OriginalDate returns the date given by the ORIGINALDATE or ORIGINALYEAR freeform atoms.
+func (m metadataMP4) OriginalDate() Date {
+	if d := parseDate(m.getFreeform("originaldate")); !d.IsZero() {
+		return d
 	}
-	return x, 0
+	return parseDate(m.getFreeform("originalyear"))
+}
+
+func (m metadataMP4) Track() (int, int) {
+	return m.getInt([]string{"trkn"}), m.getInt([]string{"trkn_count"})
 }
 
 func (m metadataMP4) Disc() (int, int) {
-	x := m.getInt([]string{"disk"})
-	if n, ok := m.data["disk_count"]; ok {
-		return x, n.(int)
-	}
-	return x, 0
+	return m.getInt([]string{"disk"}), m.getInt([]string{"disk_count"})
 }
 
 func (m metadataMP4) Lyrics() string {
-	t, ok := m.data["\xa9lyr"]
-	if !ok {
-		return ""
+	return m.getString(fieldKeys[FieldLyrics][MP4])
+}
+
+// SyncedLyrics returns the lyrics if they are in the LRC format.
+func (m metadataMP4) SyncedLyrics() []LyricLine {
+	return parseLRC(m.Lyrics())
+}
+
+// ReplayGain returns the gain given by ----:com.apple.iTunes:replaygain_* atoms.
+func (m metadataMP4) ReplayGain() *ReplayGain {
+	return replayGainFromFields(m.getFreeform)
+}
+
+// getFreeform returns the text value of the freeform (----) atom with the given
+// (case-insensitive) name and any mean.
+func (m metadataMP4) getFreeform(name string) string {
+	for k, v := range m.data {
+		if strings.HasPrefix(k, "----:") && strings.EqualFold(k[strings.LastIndexByte(k, ':')+1:], name) {
+			if s := textValue(v); s != "" {
+				return s
+			}
+		}
 	}
-	return t.(string)
+	return ""
 }
 
-func (m metadataMP4) Comment() string {
-	t, ok := m.data["\xa9cmt"]
-	if !ok {
-		return ""
+// Ratings returns the rating given by the rate atom (0–100), and the RATING and FMPS_RATING
+// freeform atoms.  NB: the rtng atom is a content advisory (explicit/clean), not a rating.
+func (m metadataMP4) Ratings() []Rating {
+	var ratings []Rating
+	switch v := m.data["rate"].(type) {
+	case int:
+		ratings = append(ratings, Rating{Value: min(v, 100), Source: "rate"})
+	case string:
+		if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil && n >= 0 && n <= 100 {
+			ratings = append(ratings, Rating{Value: n, Source: "rate"})
+		}
 	}
-	return t.(string)
+	return append(ratings, ratingsFromFields(m.getFreeform)...)
+}
+
+// PlayCount returns the play count given by freeform atoms.
+func (m metadataMP4) PlayCount() int {
+	return playCountFromFields(m.getFreeform)
+}
+
+// Get returns the text of the first atom (or freeform atom) given for the field.
+func (m metadataMP4) Get(f Field) string { return getField(MP4, m.data, f) }
+
+func (m metadataMP4) TitleSort() string       { return m.Get(FieldTitleSort) }
+func (m metadataMP4) AlbumSort() string       { return m.Get(FieldAlbumSort) }
+func (m metadataMP4) ArtistSort() string      { return m.Get(FieldArtistSort) }
+func (m metadataMP4) AlbumArtistSort() string { return m.Get(FieldAlbumArtistSort) }
+func (m metadataMP4) Key() string             { return m.Get(FieldKey) }
+func (m metadataMP4) ISRC() string            { return m.Get(FieldISRC) }
+func (m metadataMP4) Label() string           { return m.Get(FieldLabel) }
+func (m metadataMP4) CatalogNumber() string   { return m.Get(FieldCatalogNumber) }
+func (m metadataMP4) Copyright() string       { return m.Get(FieldCopyright) }
+func (m metadataMP4) Encoder() string         { return m.Get(FieldEncoder) }
+func (m metadataMP4) EncodedBy() string       { return m.Get(FieldEncodedBy) }
+func (m metadataMP4) Grouping() string        { return m.Get(FieldGrouping) }
+func (m metadataMP4) Conductor() string       { return m.Get(FieldConductor) }
+func (m metadataMP4) Lyricist() string        { return m.Get(FieldLyricist) }
+func (m metadataMP4) Mood() string            { return m.Get(FieldMood) }
+
+func (m metadataMP4) BPM() int          { return parseBPM(m.Get(FieldBPM)) }
+func (m metadataMP4) Compilation() bool { return parseBool(m.Get(FieldCompilation)) }
+
+func (m metadataMP4) Comment() string {
+	return m.getString(fieldKeys[FieldComment][MP4])
 }
 
 func (m metadataMP4) Picture() *Picture {
@@ -400,6 +755,11 @@ func (m metadataMP4) Picture() *Picture {
 	return p
 }
 
+// Audio returns the format of the first sound track.
+func (m metadataMP4) Audio() *Audio {
+	return m.audio
+}
+
 func (m metadataMP4) Duration() time.Duration {
 	return m.duration
 }
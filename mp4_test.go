@@ -0,0 +1,484 @@
+package tag
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// mp4Text builds an ilst item atom with a text data atom.
+func mp4Text(name, value string) []byte {
+	return mp4Box(name, mp4Box("data", u32s(1, 0), []byte(value)))
+}
+
+// mp4Meta builds a meta atom (full box) holding an ilst atom with the given items.
+func mp4Meta(items ...[]byte) []byte {
+	hdlr := mp4Box("hdlr", u32s(0, 0), []byte("mdir"), make([]byte, 13))
+	return mp4Box("meta", u32s(0), hdlr, mp4Box("ilst", items...))
+}
+
+func TestMP4AtomTree(t *testing.T) {
+	ftyp := mp4Box("ftyp", []byte("M4A "), u32s(0))
+	mvhd := mp4Box("mvhd", []byte{1, 0, 0, 0}, make([]byte, 16), u32s(1000), binary.BigEndian.AppendUint64(nil, 5500), make([]byte, 80))
+	trak := mp4Box("trak",
+		mp4Box("tkhd", u32s(0, 0, 0, 1)),
+		mp4Box("udta", mp4Meta(mp4Text("\xa9cmt", "Track comment"))),
+	)
+	udta := mp4Box("udta",
+		mp4Box("free", make([]byte, 16)),
+		mp4Meta(mp4Text("\xa9nam", "Title"), mp4Text("\xa9ART", "Artist")),
+		u32s(0), // QuickTime terminator
+	)
+	moov := mp4Box("moov", mvhd, udta, trak)
+
+	// mdat with a 64-bit size, followed by another atom.
+	mdat := append(u32s(1), []byte("mdat")...)
+	mdat = binary.BigEndian.AppendUint64(mdat, 16+4)
+	mdat = append(mdat, "data"...)
+	free := mp4Box("free")
+
+	tests := []struct {
+		name string
+		b    [][]byte
+	}{
+		{"moov first", [][]byte{ftyp, moov, mdat, free}},
+		{"moov last", [][]byte{ftyp, mdat, free, moov}},
+		{"mdat to end of file", [][]byte{ftyp, moov, append(u32s(0), "mdatdata"...)}},
+	}
+	for _, tt := range tests {
+		m, err := ReadAtoms(bytes.NewReader(bytes.Join(tt.b, nil)))
+		if err != nil {
+			t.Fatalf("%v: ReadAtoms() = %v", tt.name, err)
+		}
+		if got := m.Title(); got != "Title" {
+			t.Errorf("%v: Title() = %q, expected %q", tt.name, got, "Title")
+		}
+		if got := m.Artist(); got != "Artist" {
+			t.Errorf("%v: Artist() = %q, expected %q", tt.name, got, "Artist")
+		}
+		if got := m.Comment(); got != "Track comment" {
+			t.Errorf("%v: Comment() = %q, expected %q", tt.name, got, "Track comment")
+		}
+		if got := m.Duration(); got != 5500*time.Millisecond {
+			t.Errorf("%v: Duration() = %v, expected %v", tt.name, got, 5500*time.Millisecond)
+		}
+	}
+}
+
+func TestMP4QuickTimeMeta(t *testing.T) {
+	// QuickTime meta atoms have no version and flags.
+	hdlr := mp4Box("hdlr", u32s(0, 0), []byte("mdta"), make([]byte, 13))
+	moov := mp4Box("moov", mp4Box("meta", hdlr, mp4Box("ilst", mp4Text("\xa9nam", "Title"))))
+	m, err := ReadAtoms(bytes.NewReader(append(mp4Box("ftyp", []byte("qt  "), u32s(0)), moov...)))
+	if err != nil {
+		t.Fatalf("ReadAtoms() = %v", err)
+	}
+	if got := m.Title(); got != "Title" {
+		t.Errorf("Title() = %q, expected %q", got, "Title")
+	}
+}
+
+func TestMP4AtomOutOfBounds(t *testing.T) {
+	bad := mp4Text("\xa9ART", "Artist")
+	binary.BigEndian.PutUint32(bad, 100)
+	udta := mp4Box("udta", mp4Meta(mp4Text("\xa9nam", "Title"), bad))
+	b := append(mp4Box("ftyp", []byte("M4A "), u32s(0)), mp4Box("moov", udta)...)
+
+	_, err := ReadAtoms(bytes.NewReader(b))
+	if !errors.Is(err, ErrMalformed) {
+		t.Fatalf("ReadAtoms() = %v, expected ErrMalformed", err)
+	}
+
+	var warnings []error
+	m, err := ReadAtoms(bytes.NewReader(b), Lenient(&warnings))
+	if err != nil {
+		t.Fatalf("ReadAtoms() with Lenient = %v", err)
+	}
+	if len(warnings) == 0 {
+		t.Errorf("expected a warning")
+	}
+	if got := m.Title(); got != "Title" {
+		t.Errorf("Title() = %q, expected %q", got, "Title")
+	}
+}
+
+func TestMP4TrackTags(t *testing.T) {
+	track := func(title, comment string) []byte {
+		return mp4Box("trak", mp4Box("udta", mp4Meta(mp4Text("\xa9nam", title), mp4Text("\xa9cmt", comment))))
+	}
+	udta := mp4Box("udta", mp4Meta(mp4Text("\xa9nam", "Title")))
+	for _, moov := range [][]byte{
+		mp4Box("moov", udta, track("Track 1", "Comment 1"), track("Track 2", "Comment 2")),
+		mp4Box("moov", track("Track 1", "Comment 1"), track("Track 2", "Comment 2"), udta),
+	} {
+		m, err := ReadAtoms(bytes.NewReader(append(mp4Box("ftyp", []byte("M4A "), u32s(0)), moov...)))
+		if err != nil {
+			t.Fatalf("ReadAtoms() = %v", err)
+		}
+		// Movie-level items take precedence, then those of the first track.
+		if m.Title() != "Title" || m.Comment() != "Comment 1" {
+			t.Errorf("Title(), Comment() = %q, %q, expected %q, %q", m.Title(), m.Comment(), "Title", "Comment 1")
+		}
+	}
+}
+
+func TestMP4BadTrack(t *testing.T) {
+	trak := mp4Box("trak",
+		mp4Box("tkhd", u32s(0)),
+		mp4Box("udta", mp4Meta(mp4Text("\xa9nam", "Title"))),
+	)
+	b := append(mp4Box("ftyp", []byte("M4A "), u32s(0)), mp4Box("moov", trak)...)
+
+	m, err := ReadAtoms(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("ReadAtoms() = %v", err)
+	}
+	if got := m.Title(); got != "Title" {
+		t.Errorf("Title() = %q, expected %q", got, "Title")
+	}
+
+	var warnings []error
+	if _, err := ReadAtoms(bytes.NewReader(b), Lenient(&warnings)); err != nil || len(warnings) != 1 || !errors.Is(warnings[0], ErrTruncated) {
+		t.Errorf("ReadAtoms() with Lenient = %v, warnings = %v", err, warnings)
+	}
+}
+
+// mp4Sound builds a trak atom for a sound track with the given sample entry.
+func mp4Sound(entry []byte) []byte {
+	hdlr := mp4Box("hdlr", u32s(0, 0), []byte("soun"), make([]byte, 13))
+	stsd := mp4Box("stsd", u32s(0, 1), entry)
+	return mp4Box("trak", mp4Box("mdia", hdlr, mp4Box("minf", mp4Box("stbl", stsd))))
+}
+
+// mp4AudioEntry builds an audio sample entry with the given child atoms.
+func mp4AudioEntry(name string, channels, sampleSize uint16, sampleRate uint32, children ...[]byte) []byte {
+	b := make([]byte, 28)
+	binary.BigEndian.PutUint16(b[6:], 1)
+	binary.BigEndian.PutUint16(b[16:], channels)
+	binary.BigEndian.PutUint16(b[18:], sampleSize)
+	binary.BigEndian.PutUint32(b[24:], sampleRate<<16)
+	return mp4Box(name, append([][]byte{b}, children...)...)
+}
+
+// mp4ESDS builds an esds atom for MPEG-4 audio with the given AudioSpecificConfig.
+func mp4ESDS(asc ...byte) []byte {
+	dsi := append([]byte{0x05, byte(len(asc))}, asc...)
+	dc := append([]byte{0x04, byte(13 + len(dsi)), 0x40, 0x15}, make([]byte, 11)...)
+	dc = append(dc, dsi...)
+	// The ES descriptor uses a 4 byte size, as written by many encoders.
+	es := append([]byte{0x03, 0x80, 0x80, 0x80, byte(3 + len(dc)), 0, 1, 0}, dc...)
+	return mp4Box("esds", u32s(0), es)
+}
+
+func TestMP4Audio(t *testing.T) {
+	alac := mp4Box("alac", u32s(0, 4096), []byte{0, 24, 40, 10, 14, 2, 0, 255}, u32s(0, 0, 96000))
+	dfLa := mp4Box("dfLa", u32s(0), []byte{0x80, 0, 0, 34}, make([]byte, 10), []byte{0x2e, 0xe0, 0x01, 0x70}, make([]byte, 20))
+
+	tests := []struct {
+		name     string
+		brands   string // major brand and compatible brands
+		entry    []byte
+		fileType FileType
+		want     Audio
+	}{
+		{
+			"AAC-LC", "M4A isom",
+			mp4AudioEntry("mp4a", 2, 16, 44100, mp4ESDS(0x12, 0x10)),
+			M4A, Audio{Codec: CodecAACLC, SampleRate: 44100, Channels: 2},
+		},
+		{
+			"HE-AAC explicit", "M4A isom",
+			mp4AudioEntry("mp4a", 2, 16, 24000, mp4ESDS(0x2b, 0x11, 0x88, 0x00)), // SBR, 24kHz -> 48kHz, AAC-LC
+			M4A, Audio{Codec: CodecHEAAC, SampleRate: 48000, Channels: 2},
+		},
+		{
+			"HE-AAC implicit", "M4A isom",
+			mp4AudioEntry("mp4a", 2, 16, 22050, mp4ESDS(0x13, 0x90, 0x56, 0xe5, 0xa5, 0x48, 0x80)), // LC 22.05kHz, SBR 44.1kHz, PS
+			M4A, Audio{Codec: CodecHEAACv2, SampleRate: 44100, Channels: 2},
+		},
+		{
+			"ALAC", "M4A isom",
+			mp4AudioEntry("alac", 2, 16, 0, alac),
+			ALAC, Audio{Codec: CodecALAC, SampleRate: 96000, Channels: 2, BitDepth: 24},
+		},
+		{
+			"audiobook", "isomM4B ",
+			mp4AudioEntry("mp4a", 1, 16, 22050, mp4ESDS(0x13, 0x88)),
+			M4B, Audio{Codec: CodecAACLC, SampleRate: 22050, Channels: 1},
+		},
+		{
+			"AC-3", "isomiso2",
+			mp4AudioEntry("ac-3", 6, 16, 48000),
+			UnknownFileType, Audio{Codec: CodecAC3, SampleRate: 48000, Channels: 6},
+		},
+		{
+			"E-AC-3", "isomiso2",
+			mp4AudioEntry("ec-3", 2, 16, 48000),
+			UnknownFileType, Audio{Codec: CodecEAC3, SampleRate: 48000, Channels: 2},
+		},
+		{
+			"Opus", "iso2isom",
+			mp4AudioEntry("Opus", 2, 16, 48000, mp4Box("dOps", []byte{0, 2, 0x01, 0x38}, u32s(48000), []byte{0, 0, 0})),
+			UnknownFileType, Audio{Codec: CodecOpus, SampleRate: 48000, Channels: 2},
+		},
+		{
+			"FLAC", "iso2isom",
+			mp4AudioEntry("fLaC", 2, 16, 0, dfLa),
+			UnknownFileType, Audio{Codec: CodecFLAC, SampleRate: 192000, Channels: 1, BitDepth: 24},
+		},
+	}
+	for _, tt := range tests {
+		ftyp := mp4Box("ftyp", []byte(tt.brands[:4]), u32s(0), []byte(tt.brands[4:]))
+		b := append(ftyp, mp4Box("moov", mp4Sound(tt.entry))...)
+		m, err := ReadAtoms(bytes.NewReader(b))
+		if err != nil {
+			t.Fatalf("%v: ReadAtoms() = %v", tt.name, err)
+		}
+		if got := m.FileType(); got != tt.fileType {
+			t.Errorf("%v: FileType() = %q, expected %q", tt.name, got, tt.fileType)
+		}
+		if got := m.Audio(); got == nil || *got != tt.want {
+			t.Errorf("%v: Audio() = %+v, expected %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// mp4Freeform builds a freeform (----) atom with the given data atoms.
+func mp4Freeform(mean, name string, data ...[]byte) []byte {
+	return mp4Box("----", append([][]byte{
+		mp4Box("mean", u32s(0), []byte(mean)),
+		mp4Box("name", u32s(0), []byte(name)),
+	}, data...)...)
+}
+
+// mp4Data builds a data atom with the given type class.
+func mp4Data(class uint32, b []byte) []byte {
+	return mp4Box("data", u32s(class, 0), b)
+}
+
+func TestMP4Freeform(t *testing.T) {
+	ilst := mp4Meta(
+		mp4Freeform("com.apple.iTunes", "ISRC", mp4Data(1, []byte("GBAYE0601498"))),
+		mp4Freeform("com.apple.iTunes", "ARTISTS", mp4Data(1, []byte("John Lennon")), mp4Data(1, []byte("Paul McCartney"))),
+		mp4Freeform("com.apple.iTunes", "UTF16", mp4Data(2, []byte("\x00H\x00i"))),
+		mp4Freeform("com.mixedinkey.mixedinkey", "key", mp4Data(1, []byte("8A"))),
+		mp4Freeform("org.example", "count", mp4Data(21, []byte{0xff, 0xfe})),
+		mp4Freeform("org.example", "unsigned", mp4Data(22, []byte{0xff, 0xfe})),
+		mp4Freeform("com.serato.dj", "markersv2", mp4Data(0, []byte{1, 2, 3})),
+		mp4Freeform("org.example", "mixed", mp4Data(1, []byte("a")), mp4Data(21, []byte{1})),
+	)
+	b := append(mp4Box("ftyp", []byte("M4A "), u32s(0)), mp4Box("moov", mp4Box("udta", ilst))...)
+	m, err := ReadAtoms(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("ReadAtoms() = %v", err)
+	}
+
+	want := map[string]interface{}{
+		"----:com.apple.iTunes:ISRC":         "GBAYE0601498",
+		"----:com.apple.iTunes:ARTISTS":      []string{"John Lennon", "Paul McCartney"},
+		"----:com.apple.iTunes:UTF16":        "Hi",
+		"----:com.mixedinkey.mixedinkey:key": "8A",
+		"----:org.example:count":             -2,
+		"----:org.example:unsigned":          0xfffe,
+		"----:com.serato.dj:markersv2":       []byte{1, 2, 3},
+		"----:org.example:mixed":             []interface{}{"a", 1},
+	}
+	if got := m.Raw(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Raw() = %#v, expected %#v", got, want)
+	}
+	if got := m.ISRC(); got != "GBAYE0601498" {
+		t.Errorf("ISRC() = %q, expected %q", got, "GBAYE0601498")
+	}
+	if got := m.Key(); got != "8A" {
+		t.Errorf("Key() = %q, expected %q", got, "8A")
+	}
+	if f, ok := FieldFor(MP4, "----:com.apple.iTunes:isrc"); !ok || f != FieldISRC {
+		t.Errorf("FieldFor() = %q, %v, expected %q", f, ok, FieldISRC)
+	}
+}
+
+func TestMP4DataClasses(t *testing.T) {
+	png := append(append([]byte(nil), pngHeader...), "data"...)
+	bmp := []byte("BM\x00\x00")
+	ilst := mp4Meta(
+		mp4Box("tmpo", mp4Data(21, []byte{0x01, 0x2c})),
+		mp4Box("gnre", mp4Data(0, []byte{0, 9})),
+		mp4Box("stik", mp4Data(21, []byte{2})),
+		mp4Box("pgap", mp4Data(0, []byte{1})),
+		mp4Box("rtng", mp4Data(21, []byte{4})),
+		mp4Box("pcst", mp4Data(21, []byte{1})),
+		mp4Box("purl", mp4Data(0, []byte("http://example.com/feed"))),
+		mp4Box("tvsh", mp4Data(1, []byte("Show"))),
+		mp4Box("tves", mp4Data(21, []byte{0, 0, 0, 5})),
+		mp4Box("tvsn", mp4Data(76, []byte{0, 2})),
+		mp4Box("plID", mp4Data(21, []byte{0, 0, 0, 0, 0, 0, 1, 0})),
+		mp4Box("sfID", mp4Data(77, []byte{0, 0, 0x8f, 0x5c})),
+		mp4Box("\xa9nam", mp4Data(2, []byte("\x00T\x00i\x00t\x00l\x00e"))),
+		mp4Box("xtra", mp4Data(23, []byte{0x3f, 0xc0, 0, 0})),
+		mp4Box("xint", mp4Data(21, make([]byte, 9))), // not in the registry, kept as is
+		mp4Box("covr", mp4Data(13, []byte{0xff, 0xd8, 0xff}), mp4Data(0, png), mp4Data(27, bmp)),
+	)
+	b := append(mp4Box("ftyp", []byte("M4A "), u32s(0)), mp4Box("moov", mp4Box("udta", ilst))...)
+	m, err := ReadAtoms(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("ReadAtoms() = %v", err)
+	}
+
+	want := map[string]interface{}{
+		"tmpo":    300,
+		"gnre":    9,
+		"stik":    2,
+		"pgap":    1,
+		"rtng":    4,
+		"pcst":    1,
+		"purl":    []byte("http://example.com/feed"),
+		"tvsh":    "Show",
+		"tves":    5,
+		"tvsn":    2,
+		"plID":    256,
+		"sfID":    0x8f5c,
+		"\xa9nam": "Title",
+		"xtra":    1.5,
+		"xint":    make([]byte, 9),
+		"covr":    &Picture{Ext: "jpeg", MIMEType: "image/jpeg", Data: []byte{0xff, 0xd8, 0xff}},
+		"covr_0":  &Picture{Ext: "png", MIMEType: "image/png", Data: png},
+		"covr_1":  &Picture{Ext: "bmp", MIMEType: "image/bmp", Data: bmp},
+	}
+	if got := m.Raw(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Raw() = %#v, expected %#v", got, want)
+	}
+	if got := m.BPM(); got != 300 {
+		t.Errorf("BPM() = %v, expected %v", got, 300)
+	}
+	if got := m.Genre(); got != "Jazz" {
+		t.Errorf("Genre() = %q, expected %q", got, "Jazz")
+	}
+	if got := m.Picture(); got == nil || got.MIMEType != "image/jpeg" {
+		t.Errorf("Picture() = %v, expected the JPEG", got)
+	}
+}
+
+func TestMP4LyricsAndComment(t *testing.T) {
+	ilst := mp4Meta(
+		mp4Box("\xa9lyr", mp4Data(1, []byte("First")), mp4Data(1, []byte("Second"))),
+		mp4Box("\xa9cmt", mp4Data(0, []byte("Implicit"))),
+	)
+	b := append(mp4Box("ftyp", []byte("M4A "), u32s(0)), mp4Box("moov", mp4Box("udta", ilst))...)
+	m, err := ReadAtoms(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("ReadAtoms() = %v", err)
+	}
+	if got := m.Lyrics(); got != "First" {
+		t.Errorf("Lyrics() = %q, expected %q", got, "First")
+	}
+	if got := m.Comment(); got != "Implicit" {
+		t.Errorf("Comment() = %q, expected %q", got, "Implicit")
+	}
+
+	// Values of other types don't panic.
+	m = metadataMP4{data: map[string]interface{}{"\xa9lyr": []byte("x"), "\xa9cmt": 1, "trkn": "1", "trkn_count": []byte{2}}}
+	if m.Lyrics() != "" || m.Comment() != "1" {
+		t.Errorf("Lyrics(), Comment() = %q, %q", m.Lyrics(), m.Comment())
+	}
+	if n, total := m.Track(); n != 0 || total != 0 {
+		t.Errorf("Track() = %v, %v, expected 0, 0", n, total)
+	}
+}
+
+func TestMP4TrackNumbers(t *testing.T) {
+	ilst := mp4Meta(
+		mp4Box("trkn", mp4Data(0, []byte{0, 0, 0x01, 0x2c, 0x01, 0x90, 0, 0})), // 300/400
+		mp4Box("disk", mp4Data(0, []byte{0, 0, 0x01, 0x00, 0x01, 0x01})),       // 256/257
+	)
+	b := append(mp4Box("ftyp", []byte("M4A "), u32s(0)), mp4Box("moov", mp4Box("udta", ilst))...)
+	m, err := ReadAtoms(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("ReadAtoms() = %v", err)
+	}
+	if n, total := m.Track(); n != 300 || total != 400 {
+		t.Errorf("Track() = %v, %v, expected 300, 400", n, total)
+	}
+	if n, total := m.Disc(); n != 256 || total != 257 {
+		t.Errorf("Disc() = %v, %v, expected 256, 257", n, total)
+	}
+
+	c, err := Convert(m, MP4)
+	if err != nil {
+		t.Fatalf("Convert() = %v", err)
+	}
+	if !bytes.Contains(c.Data, []byte("trkn\x00\x00\x00\x18data\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x2c\x01\x90\x00\x00")) {
+		t.Errorf("converted trkn atom does not hold 300/400")
+	}
+	if n, total := readConverted(t, c).Disc(); n != 256 || total != 257 {
+		t.Errorf("converted Disc() = %v, %v, expected 256, 257", n, total)
+	}
+}
+
+func TestMP4Fragmented(t *testing.T) {
+	// track builds a trak atom with the given ID and mdhd timescale, holding sound if audio
+	// is set.
+	track := func(id, timescale uint32, audio bool) []byte {
+		handler := "vide"
+		if audio {
+			handler = "soun"
+		}
+		hdlr := mp4Box("hdlr", u32s(0, 0), []byte(handler), make([]byte, 13))
+		mdhd := mp4Box("mdhd", u32s(0, 0, 0, timescale, 0), make([]byte, 4))
+		stsd := mp4Box("stsd", u32s(0, 1), mp4AudioEntry("mp4a", 2, 16, 48000))
+		return mp4Box("trak", mp4Box("tkhd", u32s(0, 0, 0, id), make([]byte, 68)), mp4Box("mdia", mdhd, hdlr, mp4Box("minf", mp4Box("stbl", stsd))))
+	}
+	mvhd := mp4Box("mvhd", u32s(0, 0, 0, 1000, 0), make([]byte, 80))
+	trex := mp4Box("trex", u32s(0, 1, 1, 1024, 0, 0))
+	tracks := append(track(2, 90000, false), track(1, 48000, true)...)
+	// moof builds a moof atom with a fragment of track 1.
+	moof := func(tfhd, trun []byte) []byte {
+		return mp4Box("moof", mp4Box("mfhd", u32s(0, 1)), mp4Box("traf", mp4Box("tfhd", tfhd), mp4Box("trun", trun)))
+	}
+	video := mp4Box("moof", mp4Box("traf", mp4Box("tfhd", u32s(0x08, 2, 9000000)), mp4Box("trun", u32s(0, 1))))
+
+	tests := []struct {
+		name string
+		b    [][]byte
+		want time.Duration
+	}{
+		{
+			"mehd",
+			[][]byte{mp4Box("moov", mvhd, mp4Box("mvex", mp4Box("mehd", []byte{1, 0, 0, 0}, binary.BigEndian.AppendUint64(nil, 2500)), trex), tracks)},
+			2500 * time.Millisecond,
+		},
+		{
+			"trun",
+			[][]byte{
+				mp4Box("moov", mvhd, mp4Box("mvex", trex), tracks),
+				video,
+				moof(u32s(0, 1), u32s(0, 24)), // 24 samples with the trex default
+				moof(u32s(0x0a, 1, 1, 2048), u32s(0x01, 12, 0)),             // tfhd default
+				moof(u32s(0, 1), u32s(0x301, 2, 0, 12000, 100, 24000, 100)), // durations and sizes
+			},
+			(24*1024 + 12*2048 + 36000) * time.Second / 48000,
+		},
+		{
+			"sidx",
+			[][]byte{
+				mp4Box("moov", mvhd, tracks),
+				mp4Box("sidx", u32s(0, 1, 48000, 0, 0, 2), u32s(100, 96000, 0), u32s(100, 48000, 0)),
+				mp4Box("sidx", []byte{1, 0, 0, 0}, u32s(1, 1000), make([]byte, 16), u32s(1), u32s(100, 500, 0)),
+			},
+			3500 * time.Millisecond,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := ReadAtoms(bytes.NewReader(bytes.Join(tt.b, nil)))
+			if err != nil {
+				t.Fatalf("ReadAtoms() = %v", err)
+			}
+			if got := m.Duration(); got != tt.want {
+				t.Errorf("Duration() = %v, expected %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,302 @@
+package tag
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+	"unicode/utf16"
+)
+
+// mp4Track holds the parts of a trak atom needed to read chapters and the audio format.
+type mp4Track struct {
+	id         uint32
+	handler    string   // handler type from hdlr, e.g. "soun", "text"
+	timescale  uint32   // from mdhd
+	duration   uint64   // from mdhd, in the timescale
+	chapterIDs []uint32 // track IDs from tref/chap
+	audio      *Audio   // from the first stsd sample entry of sound tracks
+
+	// Sample tables (only kept for text tracks).
+	stts []uint32 // sample count, sample delta pairs
+	stsc []uint32 // first chunk, samples per chunk, sample description index triples
+	stsz []uint32 // sample sizes
+	stco []int64  // chunk offsets
+}
+
+// readMP4Boxes calls f for each box in b.  Boxes may have 64-bit sizes, or extend to the end
+// of b (size 0).
+func readMP4Boxes(b []byte, f func(name string, body []byte) error) error {
+	for len(b) >= 8 {
+		size := uint64(binary.BigEndian.Uint32(b))
+		name := string(b[4:8])
+		n := uint64(8)
+		switch size {
+		case 0:
+			size = uint64(len(b))
+		case 1:
+			if len(b) < 16 {
+				return fmt.Errorf("%w: %q box size", ErrTruncated, name)
+			}
+			size, n = binary.BigEndian.Uint64(b[8:]), 16
+		}
+		if size < n || size > uint64(len(b)) {
+			return fmt.Errorf("%w: invalid size %d for %q box", ErrMalformed, size, name)
+		}
+		if err := f(name, b[n:size]); err != nil {
+			return err
+		}
+		b = b[size:]
+	}
+	return nil
+}
+
+// readMP4Track reads the body of a trak atom.
+func readMP4Track(b []byte) (*mp4Track, error) {
+	t := &mp4Track{}
+
+	var walk func(name string, b []byte) error
+	walk = func(name string, b []byte) error {
+		switch name {
+		case "mdia", "minf", "stbl", "tref":
+			return readMP4Boxes(b, walk)
+
+		case "tkhd":
+			// version (1 byte), flags (3 bytes), creation and modification times (4 or 8 bytes each)
+			n := 12
+			if len(b) > 0 && b[0] == 1 {
+				n = 20
+			}
+			if len(b) < n+4 {
+				return fmt.Errorf("%w: tkhd", ErrTruncated)
+			}
+			t.id = binary.BigEndian.Uint32(b[n:])
+
+		case "mdhd":
+			n := 12
+			if len(b) > 0 && b[0] == 1 {
+				n = 20
+			}
+			// timescale (4 bytes), duration (4 or 8 bytes)
+			if len(b) < n+8 || (b[0] == 1 && len(b) < n+12) {
+				return fmt.Errorf("%w: mdhd", ErrTruncated)
+			}
+			t.timescale = binary.BigEndian.Uint32(b[n:])
+			t.duration = uint64(binary.BigEndian.Uint32(b[n+4:]))
+			if b[0] == 1 {
+				t.duration = binary.BigEndian.Uint64(b[n+4:])
+			}
+
+		case "hdlr":
+			// version and flags (4 bytes), pre-defined (4 bytes), handler type (4 bytes)
+			if len(b) < 12 {
+				return fmt.Errorf("%w: hdlr", ErrTruncated)
+			}
+			t.handler = string(b[8:12])
+
+		case "chap":
+			t.chapterIDs = readUint32s(b)
+
+		case "stsd":
+			if t.handler != "soun" {
+				return nil
+			}
+			// version and flags (4 bytes), entry count (4 bytes)
+			if len(b) < 8 {
+				return fmt.Errorf("%w: stsd", ErrTruncated)
+			}
+			return readMP4Boxes(b[8:], func(name string, b []byte) error {
+				if t.audio != nil {
+					return nil
+				}
+				var err error
+				t.audio, err = readMP4SampleEntry(name, b)
+				return err
+			})
+
+		case "stts", "stsc", "stsz", "stco", "co64":
+			if t.handler != "text" && t.handler != "sbtl" {
+				return nil
+			}
+			// version and flags (4 bytes), [sample size (4 bytes) for stsz], entry count (4 bytes)
+			if len(b) < 8 {
+				return fmt.Errorf("%w: %v", ErrTruncated, name)
+			}
+			switch name {
+			case "stts":
+				t.stts = readUint32s(b[8:])
+			case "stsc":
+				t.stsc = readUint32s(b[8:])
+			case "stsz":
+				if len(b) < 12 {
+					return fmt.Errorf("%w: %v", ErrTruncated, name)
+				}
+				n := binary.BigEndian.Uint32(b[8:])
+				if size := binary.BigEndian.Uint32(b[4:]); size != 0 {
+					// All samples have the same size.
+					t.stsz = make([]uint32, min(n, uint32(len(b))))
+					for i := range t.stsz {
+						t.stsz[i] = size
+					}
+				} else {
+					t.stsz = readUint32s(b[12:])
+				}
+			case "stco":
+				for _, x := range readUint32s(b[8:]) {
+					t.stco = append(t.stco, int64(x))
+				}
+			case "co64":
+				for b = b[8:]; len(b) >= 8; b = b[8:] {
+					t.stco = append(t.stco, int64(binary.BigEndian.Uint64(b)))
+				}
+			}
+		}
+		return nil
+	}
+
+	if err := readMP4Boxes(b, walk); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func readUint32s(b []byte) []uint32 {
+	x := make([]uint32, 0, len(b)/4)
+	for ; len(b) >= 4; b = b[4:] {
+		x = append(x, binary.BigEndian.Uint32(b))
+	}
+	return x
+}
+
+// readChapterTrack reads the chapters from the text track referenced by a chap track
+// reference.  Returns nil if there is no chapter track.
+func (m *metadataMP4) readChapterTrack(r io.ReadSeeker) ([]Chapter, error) {
+	var t *mp4Track
+	for _, x := range m.tracks {
+		for _, id := range x.chapterIDs {
+			for _, y := range m.tracks {
+				if y.id == id && y.stsz != nil {
+					t = y
+				}
+			}
+		}
+	}
+	if t == nil || t.timescale == 0 {
+		return nil, nil
+	}
+
+	// Sample offsets, from the chunk offsets and sample-to-chunk table.
+	offsets := make([]int64, 0, len(t.stsz))
+	for c := range t.stco {
+		var perChunk uint32
+		for i := 0; i+2 < len(t.stsc); i += 3 {
+			if t.stsc[i] <= uint32(c+1) {
+				perChunk = t.stsc[i+1]
+			}
+		}
+		offset := t.stco[c]
+		for i := uint32(0); i < perChunk && len(offsets) < len(t.stsz); i++ {
+			offsets = append(offsets, offset)
+			offset += int64(t.stsz[len(offsets)-1])
+		}
+	}
+
+	chapters := make([]Chapter, 0, len(offsets))
+	var start uint64
+	sample := 0
+	for i := 0; i+1 < len(t.stts) && sample < len(offsets); i += 2 {
+		for n := uint32(0); n < t.stts[i] && sample < len(offsets); n++ {
+			title, err := readChapterSample(r, offsets[sample], t.stsz[sample])
+			if err != nil {
+				return nil, err
+			}
+			end := start + uint64(t.stts[i+1])
+			chapters = append(chapters, Chapter{
+				Start: mp4Duration(start, t.timescale),
+				End:   mp4Duration(end, t.timescale),
+				Title: title,
+			})
+			start = end
+			sample++
+		}
+	}
+	return chapters, nil
+}
+
+func mp4Duration(n uint64, timescale uint32) time.Duration {
+	return samplesDuration(n, timescale)
+}
+
+// readChapterSample reads a text sample: a 16-bit length followed by UTF-8 (or UTF-16 with
+// BOM) text, and optional atoms (e.g. encd).
+func readChapterSample(r io.ReadSeeker, offset int64, size uint32) (string, error) {
+	if _, err := r.Seek(offset, io.SeekStart); err != nil {
+		return "", err
+	}
+	b, err := readBytes(r, uint(size))
+	if err != nil {
+		return "", err
+	}
+	if len(b) < 2 {
+		return "", fmt.Errorf("%w: chapter sample", ErrTruncated)
+	}
+	n := int(binary.BigEndian.Uint16(b))
+	if n > len(b)-2 {
+		return "", fmt.Errorf("%w: chapter sample text", ErrTruncated)
+	}
+	b = b[2 : 2+n]
+
+	if len(b) >= 2 && b[0] == 0xfe && b[1] == 0xff {
+		u := make([]uint16, 0, len(b)/2)
+		for b = b[2:]; len(b) >= 2; b = b[2:] {
+			u = append(u, binary.BigEndian.Uint16(b))
+		}
+		return string(utf16.Decode(u)), nil
+	}
+	return string(b), nil
+}
+
+// readChplAtom reads a Nero chapter list (chpl atom).
+// -- chpl
+// Version          $xx
+// Flags            $xx xx xx
+// Reserved         $xx xx xx xx (version 1 only)
+// Chapter count    $xx
+// -- for each chapter
+// Start time       $xx xx xx xx xx xx xx xx (100ns units)
+// Title length     $xx
+// Title            <UTF-8 text>
+func readChplAtom(b []byte) ([]Chapter, error) {
+	if len(b) < 5 {
+		return nil, fmt.Errorf("%w: chpl", ErrTruncated)
+	}
+	n := 4
+	if b[0] == 1 {
+		n += 4
+	}
+	if len(b) < n+1 {
+		return nil, fmt.Errorf("%w: chpl", ErrTruncated)
+	}
+	count := int(b[n])
+	b = b[n+1:]
+
+	chapters := make([]Chapter, 0, count)
+	for i := 0; i < count; i++ {
+		if len(b) < 9 || len(b) < 9+int(b[8]) {
+			return nil, fmt.Errorf("%w: chpl chapter %d", ErrTruncated, i)
+		}
+		l := int(b[8])
+		chapters = append(chapters, Chapter{
+			Start: time.Duration(binary.BigEndian.Uint64(b)) * 100,
+			Title: string(b[9 : 9+l]),
+		})
+		b = b[9+l:]
+	}
+	return chapters, nil
+}
+
+// Chapters returns the chapters from the chapter track if there is one, otherwise those
+// from the Nero chapter list (chpl atom).
+func (m metadataMP4) Chapters() []Chapter {
+	return m.chapters
+}
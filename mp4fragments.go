@@ -0,0 +1,228 @@
+package tag
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/bits"
+	"time"
+)
+
+// mp4Fragments holds the parts of a fragmented MP4 file needed to work out its duration,
+// which is not given by mvhd.
+type mp4Fragments struct {
+	duration uint64            // from mehd, in the mvhd timescale
+	defaults map[uint32]uint32 // default sample duration of each track, from trex
+	tracks   map[uint32]*mp4FragmentedTrack
+	sidx     time.Duration // total of the sidx subsegment durations
+}
+
+// mp4FragmentedTrack is the total duration of the samples of a track given by trun atoms.
+type mp4FragmentedTrack struct {
+	duration uint64 // in the mdhd timescale
+	samples  uint64 // samples with the default duration given by trex
+}
+
+// readMvexAtom reads the body of an mvex atom.
+func (f *mp4Fragments) readMvexAtom(b []byte) error {
+	return readMP4Boxes(b, func(name string, b []byte) error {
+		switch name {
+		case "mehd":
+			// version (1 byte), flags (3 bytes), fragment duration (4 or 8 bytes)
+			if len(b) < 8 || (b[0] == 1 && len(b) < 12) {
+				return fmt.Errorf("%w: mehd", ErrTruncated)
+			}
+			f.duration = uint64(binary.BigEndian.Uint32(b[4:]))
+			if b[0] == 1 {
+				f.duration = binary.BigEndian.Uint64(b[4:])
+			}
+
+		case "trex":
+			// version and flags (4 bytes), track ID (4 bytes), default sample description
+			// index (4 bytes), default sample duration (4 bytes), ...
+			if len(b) < 16 {
+				return fmt.Errorf("%w: trex", ErrTruncated)
+			}
+			if f.defaults == nil {
+				f.defaults = make(map[uint32]uint32)
+			}
+			f.defaults[binary.BigEndian.Uint32(b[4:])] = binary.BigEndian.Uint32(b[12:])
+		}
+		return nil
+	})
+}
+
+// readMoofAtom reads the sample durations from the body of a moof atom.
+func (f *mp4Fragments) readMoofAtom(b []byte) error {
+	return readMP4Boxes(b, func(name string, b []byte) error {
+		if name != "traf" {
+			return nil
+		}
+
+		var t *mp4FragmentedTrack
+		var defaultDuration uint32
+		hasDefault := false
+		return readMP4Boxes(b, func(name string, b []byte) error {
+			switch name {
+			case "tfhd":
+				// version (1 byte), flags (3 bytes), track ID (4 bytes), optional fields
+				if len(b) < 8 {
+					return fmt.Errorf("%w: tfhd", ErrTruncated)
+				}
+				flags := binary.BigEndian.Uint32(b) & 0xffffff
+				id := binary.BigEndian.Uint32(b[4:])
+				if f.tracks == nil {
+					f.tracks = make(map[uint32]*mp4FragmentedTrack)
+				}
+				if t = f.tracks[id]; t == nil {
+					t = &mp4FragmentedTrack{}
+					f.tracks[id] = t
+				}
+
+				if flags&0x08 == 0 {
+					return nil
+				}
+				// base data offset (8 bytes), sample description index (4 bytes), default
+				// sample duration (4 bytes)
+				n := 8
+				if flags&0x01 != 0 {
+					n += 8
+				}
+				if flags&0x02 != 0 {
+					n += 4
+				}
+				if len(b) < n+4 {
+					return fmt.Errorf("%w: tfhd", ErrTruncated)
+				}
+				defaultDuration, hasDefault = binary.BigEndian.Uint32(b[n:]), true
+
+			case "trun":
+				if t == nil {
+					return fmt.Errorf("%w: trun before tfhd", ErrMalformed)
+				}
+				// version (1 byte), flags (3 bytes), sample count (4 bytes), optional fields,
+				// then for each sample optional duration, size, flags and composition time
+				// offset (4 bytes each)
+				if len(b) < 8 {
+					return fmt.Errorf("%w: trun", ErrTruncated)
+				}
+				flags := binary.BigEndian.Uint32(b) & 0xffffff
+				count := uint64(binary.BigEndian.Uint32(b[4:]))
+				if flags&0x100 == 0 {
+					if hasDefault {
+						t.duration += count * uint64(defaultDuration)
+					} else {
+						t.samples += count
+					}
+					return nil
+				}
+
+				n := 8
+				if flags&0x01 != 0 {
+					n += 4 // data offset
+				}
+				if flags&0x04 != 0 {
+					n += 4 // first sample flags
+				}
+				stride := 4
+				for _, bit := range []uint32{0x200, 0x400, 0x800} {
+					if flags&bit != 0 {
+						stride += 4
+					}
+				}
+				if uint64(len(b)-min(n, len(b)))/uint64(stride) < count {
+					return fmt.Errorf("%w: trun", ErrTruncated)
+				}
+				for i := uint64(0); i < count; i++ {
+					t.duration += uint64(binary.BigEndian.Uint32(b[n:]))
+					n += stride
+				}
+			}
+			return nil
+		})
+	})
+}
+
+// readSidxAtom reads the total duration of the subsegments given by the body of a sidx atom.
+func readSidxAtom(b []byte) (time.Duration, error) {
+	// version (1 byte), flags (3 bytes), reference ID (4 bytes), timescale (4 bytes), earliest
+	// presentation time and first offset (4 or 8 bytes each), reserved (2 bytes), reference
+	// count (2 bytes), then for each reference: type and size (4 bytes), subsegment duration
+	// (4 bytes), SAP (4 bytes)
+	n := 20
+	if len(b) > 0 && b[0] == 1 {
+		n = 28
+	}
+	if len(b) < n+4 {
+		return 0, fmt.Errorf("%w: sidx", ErrTruncated)
+	}
+	timescale := binary.BigEndian.Uint32(b[8:])
+	count := int(binary.BigEndian.Uint16(b[n+2:]))
+	b = b[n+4:]
+	if len(b) < count*12 {
+		return 0, fmt.Errorf("%w: sidx references", ErrTruncated)
+	}
+	if timescale == 0 {
+		return 0, nil
+	}
+
+	var duration uint64
+	for i := 0; i < count; i++ {
+		duration += uint64(binary.BigEndian.Uint32(b[i*12+4:]))
+	}
+	return mp4Duration(duration, timescale), nil
+}
+
+// fragmentedDuration returns the duration of a fragmented file: from mehd, or else the total
+// duration of the samples of the sound track (or the longest track), or else the total duration
+// given by sidx atoms.  timescale is from mvhd.
+func (f *mp4Fragments) fragmentedDuration(timescale uint32, tracks []*mp4Track) time.Duration {
+	if f.duration > 0 && timescale > 0 {
+		return mp4Duration(f.duration, timescale)
+	}
+
+	var d time.Duration
+	for _, t := range tracks {
+		if f.tracks[t.id] == nil || t.timescale == 0 {
+			continue
+		}
+		x := mp4Duration(f.trackDuration(t), t.timescale)
+		if t.audio != nil {
+			return x
+		}
+		d = max(d, x)
+	}
+	if d > 0 {
+		return d
+	}
+	return f.sidx
+}
+
+// trackDuration returns the total duration of the samples of a track given by trun atoms, in
+// the mdhd timescale.
+func (f *mp4Fragments) trackDuration(t *mp4Track) uint64 {
+	ft := f.tracks[t.id]
+	if ft == nil {
+		return 0
+	}
+	return ft.duration + ft.samples*uint64(f.defaults[t.id])
+}
+
+// trackSamples returns the number of samples of the sound track t, from its mdhd duration or
+// else (in fragmented files) its trun atoms.
+func (f *mp4Fragments) trackSamples(t *mp4Track) int64 {
+	n := t.duration
+	if n == 0 {
+		n = f.trackDuration(t)
+	}
+	if t.audio == nil || t.timescale == 0 || t.audio.SampleRate <= 0 {
+		return 0
+	}
+	// The timescale is usually the sample rate, otherwise scale n to it.
+	hi, lo := bits.Mul64(n, uint64(t.audio.SampleRate))
+	if hi >= uint64(t.timescale) {
+		return 0
+	}
+	q, _ := bits.Div64(hi, lo, uint64(t.timescale))
+	return int64(min(q, math.MaxInt64))
+}
@@ -17,6 +17,7 @@ var (
 	vorbisIdentificationPrefix = []byte("\x01vorbis")
 	vorbisCommentPrefix        = []byte("\x03vorbis")
 	opusTagsPrefix             = []byte("OpusTags")
+	opusHeadPrefix             = []byte("OpusHead")
 )
 
 var oggCRC32Poly04c11db7 = oggCRCTable(0x04c11db7)
@@ -60,26 +61,37 @@ type oggPageHeader struct {
 }
 
 type oggDemuxer struct {
+	opts       *readOptions
 	packetBufs map[uint32]*bytes.Buffer
+	offset     int64 // offset of the next page
+	pageOffset int64 // offset of the last page read
 }
 
 // Read ogg packets, can return empty slice of packets and nil err
 // if more data is needed
 func (o *oggDemuxer) Read(r io.Reader) ([][]byte, int, error) {
+	o.pageOffset = o.offset
+	pageError := func(err error) error {
+		return newParseError(string(OGG), o.pageOffset, "", err)
+	}
+
 	headerBuf := &bytes.Buffer{}
 	var oh oggPageHeader
 	if err := binary.Read(io.TeeReader(r, headerBuf), binary.LittleEndian, &oh); err != nil {
-		return nil, 0, err
+		if err == io.EOF {
+			return nil, 0, err
+		}
+		return nil, 0, pageError(err)
 	}
 
 	if !bytes.Equal(oh.Magic[:], []byte("OggS")) {
 		// TODO: seek for syncword?
-		return nil, 0, errors.New("expected 'OggS'")
+		return nil, 0, pageError(fmt.Errorf("%w: expected 'OggS'", ErrMalformed))
 	}
 
 	segmentTable := make([]byte, oh.Segments)
 	if _, err := io.ReadFull(r, segmentTable); err != nil {
-		return nil, 0, err
+		return nil, 0, pageError(err)
 	}
 	var segmentsSize int64
 	for _, s := range segmentTable {
@@ -87,8 +99,9 @@ func (o *oggDemuxer) Read(r io.Reader) ([][]byte, int, error) {
 	}
 	segmentsData := make([]byte, segmentsSize)
 	if _, err := io.ReadFull(r, segmentsData); err != nil {
-		return nil, 0, err
+		return nil, 0, pageError(err)
 	}
+	o.offset += int64(headerBuf.Len()) + int64(len(segmentTable)) + segmentsSize
 
 	headerBytes := headerBuf.Bytes()
 	// reset CRC to zero in header before checksum
@@ -100,7 +113,10 @@ func (o *oggDemuxer) Read(r io.Reader) ([][]byte, int, error) {
 	crc = oggCRCUpdate(crc, oggCRC32Poly04c11db7, segmentTable)
 	crc = oggCRCUpdate(crc, oggCRC32Poly04c11db7, segmentsData)
 	if crc != oh.CRC {
-		return nil, 0, fmt.Errorf("expected crc %x != %x", oh.CRC, crc)
+		// When lenient, pages with a bad checksum are still used.
+		if err := o.opts.warn(pageError(fmt.Errorf("%w: expected crc %x != %x", ErrBadChecksum, oh.CRC, crc))); err != nil {
+			return nil, 0, err
+		}
 	}
 
 	if o.packetBufs == nil {
@@ -113,7 +129,7 @@ func (o *oggDemuxer) Read(r io.Reader) ([][]byte, int, error) {
 		if b, ok := o.packetBufs[oh.SerialNumber]; ok {
 			packetBuf = b
 		} else {
-			return nil, 0, fmt.Errorf("could not find continued packet %d", oh.SerialNumber)
+			return nil, 0, pageError(fmt.Errorf("%w: could not find continued packet %d", ErrMalformed, oh.SerialNumber))
 		}
 	} else {
 		packetBuf = &bytes.Buffer{}
@@ -140,8 +156,9 @@ func (o *oggDemuxer) Read(r io.Reader) ([][]byte, int, error) {
 // See http://www.xiph.org/vorbis/doc/Vorbis_I_spec.html
 // and http://www.xiph.org/ogg/doc/framing.html for details.
 // For Opus see https://tools.ietf.org/html/rfc7845
-func ReadOGGMeta(r io.Reader) (Metadata, error) {
-	od := &oggDemuxer{}
+func ReadOGGMeta(r io.Reader, opts ...ReadOption) (Metadata, error) {
+	o := newReadOptions(opts)
+	od := &oggDemuxer{opts: o}
 	metaExtracted := false
 	m := &metadataOGG{
 		metadataVorbis: newMetadataVorbis(),
@@ -150,32 +167,48 @@ func ReadOGGMeta(r io.Reader) (Metadata, error) {
 	for {
 		bs, pos, err := od.Read(r)
 		if err != nil && !errors.Is(err, io.EOF) {
-			return nil, err
+			// When lenient, a damaged stream ends the read once tags have been found.
+			if !o.lenient || !metaExtracted {
+				return nil, err
+			}
+			o.warn(err)
+			err = io.EOF
 		}
 		if errors.Is(err, io.EOF) {
 			if !metaExtracted {
 				return nil, ErrNoTagsFound
 			}
-			if m.sampleRate > 0 {
-				m.duration = time.Second * (time.Duration(prevPos) / time.Duration(m.sampleRate))
+			// The granule position of the last page is the number of samples, including the
+			// Opus pre-skip.
+			samples := max(prevPos-int(m.preSkip), 0)
+			m.duration = samplesDuration(uint64(samples), m.sampleRate)
+			if m.audio != nil {
+				m.audio.Samples = int64(samples)
 			}
 			return m, nil
 		}
-		prevPos = pos
+		if pos >= 0 {
+			prevPos = pos // -1 on pages where no packet ends
+		}
 
+		warn := func(err error) error {
+			return o.warn(newParseError(string(OGG), od.pageOffset, "", err))
+		}
 		for _, b := range bs {
 			switch {
 			case bytes.HasPrefix(b, vorbisCommentPrefix):
 				metaExtracted = true
-				err = m.readVorbisComment(bytes.NewReader(b[len(vorbisCommentPrefix):]))
+				err = m.readVorbisComment(bytes.NewReader(b[len(vorbisCommentPrefix):]), warn)
 			case bytes.HasPrefix(b, opusTagsPrefix):
 				metaExtracted = true
-				err = m.readVorbisComment(bytes.NewReader(b[len(opusTagsPrefix):]))
+				err = m.readVorbisComment(bytes.NewReader(b[len(opusTagsPrefix):]), warn)
 				m.sampleRate = 48000
+			case bytes.HasPrefix(b, opusHeadPrefix):
+				err = m.readOpusHead(b[len(opusHeadPrefix):])
 			case bytes.HasPrefix(b, vorbisIdentificationPrefix):
 				err = m.readVorbisIdentification(bytes.NewReader(b[len(vorbisIdentificationPrefix):]))
 			}
-			if err != nil {
+			if err := warn(err); err != nil {
 				return m, err
 			}
 		}
@@ -186,6 +219,9 @@ type metadataOGG struct {
 	*metadataVorbis
 	sampleRate uint32
 	duration   time.Duration
+	outputGain int16  // Opus output gain (Q7.8 dB)
+	preSkip    uint16 // Opus samples to discard from the start of the stream
+	audio      *Audio
 }
 
 func (m *metadataOGG) FileType() FileType {
@@ -197,7 +233,12 @@ func (m *metadataOGG) Duration() time.Duration {
 }
 
 func (m *metadataOGG) readVorbisIdentification(r io.ReadSeeker) error {
-	_, err := r.Seek(5, io.SeekCurrent)
+	// version (4 bytes), channels (1 byte), sample rate (4 bytes)
+	_, err := r.Seek(4, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	channels, err := readBytes(r, 1)
 	if err != nil {
 		return err
 	}
@@ -205,5 +246,38 @@ func (m *metadataOGG) readVorbisIdentification(r io.ReadSeeker) error {
 	if err != nil {
 		return err
 	}
+	m.audio = &Audio{Codec: CodecVorbis, SampleRate: int(m.sampleRate), Channels: int(channels[0])}
 	return nil
 }
+
+// readOpusHead reads the Opus identification header (after the magic signature).
+// See https://tools.ietf.org/html/rfc7845#section-5.1
+func (m *metadataOGG) readOpusHead(b []byte) error {
+	// version (1 byte), channel count (1 byte), pre-skip (2 bytes), input sample rate (4 bytes),
+	// output gain (2 bytes)
+	if len(b) < 10 {
+		return fmt.Errorf("%w: expected at least 10 bytes for OpusHead, got %d", ErrTruncated, len(b))
+	}
+	m.preSkip = binary.LittleEndian.Uint16(b[2:4])
+	m.outputGain = int16(binary.LittleEndian.Uint16(b[8:10]))
+	// Opus is always decoded at 48kHz.
+	m.audio = &Audio{Codec: CodecOpus, SampleRate: 48000, Channels: int(b[1])}
+	return nil
+}
+
+func (m *metadataOGG) Audio() *Audio {
+	return m.audio
+}
+
+// ReplayGain returns the gain given by the comments, along with the Opus output gain.
+func (m *metadataOGG) ReplayGain() *ReplayGain {
+	g := m.metadataVorbis.ReplayGain()
+	if m.outputGain == 0 {
+		return g
+	}
+	if g == nil {
+		g = &ReplayGain{}
+	}
+	g.OutputGain = float64(m.outputGain) / 256
+	return g
+}
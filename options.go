@@ -0,0 +1,52 @@
+package tag
+
+import (
+	"encoding/binary"
+
+	"golang.org/x/text/encoding"
+)
+
+// ReadOption is an option which configures how tags are read.
+type ReadOption func(*readOptions)
+
+// readOptions holds the configuration for a single read.
+type readOptions struct {
+	lenient  bool
+	warnings *[]error
+
+	charset    encoding.Encoding   // legacy charset, see Charset
+	detect     bool                // whether to detect the legacy charset, see DetectCharset
+	candidates []encoding.Encoding // charsets tried when detecting
+	byteOrder  binary.ByteOrder    // see UTF16ByteOrder
+}
+
+func newReadOptions(opts []ReadOption) *readOptions {
+	o := &readOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Lenient enables lenient parsing: frames, atoms, blocks and comments which cannot be parsed
+// are skipped (and parsing stops early on truncated data) so that a usable Metadata is
+// returned with everything that could be read.  The errors encountered are appended to
+// warnings if it is non-nil.
+func Lenient(warnings *[]error) ReadOption {
+	return func(o *readOptions) {
+		o.lenient = true
+		o.warnings = warnings
+	}
+}
+
+// warn records err as a warning and returns nil if lenient parsing is enabled, otherwise
+// it returns err.
+func (o *readOptions) warn(err error) error {
+	if err == nil || !o.lenient {
+		return err
+	}
+	if o.warnings != nil {
+		*o.warnings = append(*o.warnings, err)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package tag
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestLenientID3v2(t *testing.T) {
+	b := id3v23Tag(map[string]string{
+		"TIT2": "Test Title",
+		"COMM": "e", // too short for the language field
+	})
+
+	if _, err := ReadID3v2Tags(bytes.NewReader(b)); err == nil {
+		t.Fatal("ReadID3v2Tags() error = nil, expected error for bad COMM frame")
+	}
+
+	var warnings []error
+	m, err := ReadID3v2Tags(bytes.NewReader(b), Lenient(&warnings))
+	if err != nil {
+		t.Fatalf("ReadID3v2Tags() with Lenient error = %v", err)
+	}
+	testValue(t, "Test Title", m.Title())
+
+	if len(warnings) != 1 {
+		t.Fatalf("got %d warnings, expected 1", len(warnings))
+	}
+	var pe *ParseError
+	if !errors.As(warnings[0], &pe) {
+		t.Fatalf("warning = %v, expected *ParseError", warnings[0])
+	}
+	testValue(t, "COMM", pe.Name)
+	if !errors.Is(pe, ErrTruncated) {
+		t.Errorf("warning kind = %v, expected %v", pe.Kind, ErrTruncated)
+	}
+}
+
+func TestLenientVorbis(t *testing.T) {
+	b, err := os.ReadFile("testdata/with_tags/sample.flac")
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := bytes.Index(b, []byte("ALBUM="))
+	if i < 0 {
+		t.Fatal("could not find ALBUM comment")
+	}
+	b[i+len("ALBUM")] = '_'
+
+	if _, err := ReadFrom(bytes.NewReader(b)); err == nil {
+		t.Fatal("ReadFrom() error = nil, expected error for bad comment")
+	}
+
+	var warnings []error
+	m, err := ReadFrom(bytes.NewReader(b), Lenient(&warnings))
+	if err != nil {
+		t.Fatalf("ReadFrom() with Lenient error = %v", err)
+	}
+	testValue(t, "", m.Album())
+	testValue(t, "Test Title", m.Title())
+	testValue(t, 1, len(warnings))
+}
@@ -0,0 +1,90 @@
+package tag
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestParseRating(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+		ok   bool
+	}{
+		{"0.8", 80, true},
+		{"4", 80, true},
+		{"3.5", 70, true},
+		{"60", 60, true},
+		{"196", 80, true},
+		{"-1", 0, false},
+		{"300", 0, false},
+		{"five", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseRating(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseRating(%q) = %v, %v, expected %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestID3v2Ratings(t *testing.T) {
+	b := id3v2FramesTag(3,
+		id3v23Frame("POPM", []byte("b@example.com\x00\xff\x00\x00\x00\x07")),
+		id3v23Frame("POPM", []byte("a@example.com\x00\x40\x00\x00\x00\x03")),
+		id3v23Frame("TXXX", []byte("\x00FMPS_Rating\x000.5")),
+	)
+	m, err := ReadID3v2Tags(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("ReadID3v2Tags() = %v", err)
+	}
+
+	want := []Rating{
+		{Value: 40, Source: "POPM:a@example.com"},
+		{Value: 100, Source: "POPM:b@example.com"},
+		{Value: 50, Source: "FMPS_RATING"},
+	}
+	if got := m.Ratings(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Ratings() = %v, expected %v", got, want)
+	}
+	if got := m.PlayCount(); got != 7 {
+		t.Errorf("PlayCount() = %v, expected 7", got)
+	}
+
+	// Counters larger than an int are clamped.
+	b = id3v2FramesTag(3, id3v23Frame("PCNT", []byte("\xff\xff\xff\xff\xff\xff\xff\xff")))
+	if m, err = ReadID3v2Tags(bytes.NewReader(b)); err != nil {
+		t.Fatalf("ReadID3v2Tags() = %v", err)
+	}
+	if got := m.PlayCount(); got != math.MaxInt {
+		t.Errorf("PlayCount() = %v, expected %v", got, math.MaxInt)
+	}
+	b = id3v2FramesTag(3, id3v23Frame("POPM", []byte("a@example.com\x00\x40\xff\xff\xff\xff\xff\xff\xff\xff")))
+	if m, err = ReadID3v2Tags(bytes.NewReader(b)); err != nil {
+		t.Fatalf("ReadID3v2Tags() = %v", err)
+	}
+	if got := m.PlayCount(); got != math.MaxInt {
+		t.Errorf("PlayCount() = %v, expected %v", got, math.MaxInt)
+	}
+}
+
+func TestVorbisRatings(t *testing.T) {
+	m := newMetadataVorbis()
+	m.c["rating"] = "80"
+	m.c["rating:user@example.com"] = "0.6"
+	m.c["fmps_playcount"] = "12"
+
+	want := []Rating{{Value: 80, Source: "RATING"}, {Value: 60, Source: "RATING:user@example.com"}}
+	if got := m.Ratings(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Ratings() = %v, expected %v", got, want)
+	}
+	if got := m.PlayCount(); got != 12 {
+		t.Errorf("PlayCount() = %v, expected 12", got)
+	}
+	if got := want[0].Stars(); got != 4 {
+		t.Errorf("Stars() = %v, expected 4", got)
+	}
+}
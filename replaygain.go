@@ -0,0 +1,145 @@
+package tag
+
+import (
+	"encoding/binary"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ReplayGain holds loudness normalisation data.  Fields which are not set in the
+// metadata are zero.
+type ReplayGain struct {
+	TrackGain float64 // ReplayGain track gain in dB.
+	TrackPeak float64 // ReplayGain track peak (1.0 is full scale).
+	AlbumGain float64 // ReplayGain album gain in dB.
+	AlbumPeak float64 // ReplayGain album peak (1.0 is full scale).
+
+	R128TrackGain float64 // EBU R128 track gain in dB (relative to the Opus output gain).
+	R128AlbumGain float64 // EBU R128 album gain in dB (relative to the Opus output gain).
+
+	OutputGain float64 // Opus output gain in dB (from OpusHead), applied by all decoders.
+}
+
+// replayGainFromFields returns the ReplayGain given by the REPLAYGAIN_* and R128_* fields,
+// using get to look up (lower case) field names.  Returns nil if none of the fields are set.
+func replayGainFromFields(get func(name string) string) *ReplayGain {
+	var g ReplayGain
+	found := false
+	gain := func(name string, v *float64) {
+		if f, ok := parseGain(get(name)); ok {
+			*v = f
+			found = true
+		}
+	}
+	gain("replaygain_track_gain", &g.TrackGain)
+	gain("replaygain_track_peak", &g.TrackPeak)
+	gain("replaygain_album_gain", &g.AlbumGain)
+	gain("replaygain_album_peak", &g.AlbumPeak)
+
+	r128 := func(name string, v *float64) {
+		// Q7.8 fixed point (see https://tools.ietf.org/html/rfc7845#section-5.2.1).
+		if n, err := strconv.Atoi(strings.TrimSpace(get(name))); err == nil {
+			*v = float64(n) / 256
+			found = true
+		}
+	}
+	r128("r128_track_gain", &g.R128TrackGain)
+	r128("r128_album_gain", &g.R128AlbumGain)
+
+	if !found {
+		return nil
+	}
+	return &g
+}
+
+// parseGain parses a gain or peak value such as "-6.48 dB" or "0.988129".
+func parseGain(s string) (float64, bool) {
+	s = strings.TrimSpace(s)
+	if len(s) > 2 && strings.EqualFold(s[len(s)-2:], "dB") {
+		s = strings.TrimSpace(s[:len(s)-2])
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	return f, err == nil
+}
+
+// readLAMEReplayGain reads the ReplayGain stored in the LAME header of the MPEG frame at
+// offset.  Returns nil if there is no LAME header or it holds no gain.
+// See http://gabriel.mp3-tech.org/mp3infotag.html
+func readLAMEReplayGain(r io.ReadSeeker, offset int64) *ReplayGain {
+	if _, err := r.Seek(offset, io.SeekStart); err != nil {
+		return nil
+	}
+	b := make([]byte, 512)
+	n, _ := io.ReadFull(r, b)
+	b = b[:n]
+	if len(b) < 4 || b[0] != 0xff || b[1]&0xe0 != 0xe0 {
+		return nil
+	}
+
+	// The Xing/Info header follows the side information, whose size depends on the MPEG
+	// version and channel mode.
+	mpeg1 := b[1]>>3&0x3 == 3
+	mono := b[3]>>6 == 3
+	i := 4
+	if b[1]&0x1 == 0 {
+		// CRC follows the header.
+		i += 2
+	}
+	switch {
+	case mpeg1 && !mono:
+		i += 32
+	case mpeg1 || !mono:
+		i += 17
+	default:
+		i += 9
+	}
+	if len(b) < i+8 || (string(b[i:i+4]) != "Xing" && string(b[i:i+4]) != "Info") {
+		return nil
+	}
+	flags := binary.BigEndian.Uint32(b[i+4:])
+	i += 8
+	for _, f := range []struct {
+		bit  uint32
+		size int
+	}{{1, 4}, {2, 4}, {4, 100}, {8, 4}} {
+		if flags&f.bit != 0 {
+			i += f.size
+		}
+	}
+
+	// LAME extension: encoder (9), revision/VBR method (1), lowpass (1), peak (4),
+	// radio gain (2), audiophile gain (2).
+	if len(b) < i+19 || string(b[i:i+4]) != "LAME" {
+		return nil
+	}
+	b = b[i:]
+
+	var g ReplayGain
+	found := false
+	if peak := binary.BigEndian.Uint32(b[11:]); peak != 0 {
+		// Fixed point with 1.0 at 1<<23.
+		g.TrackPeak = float64(peak) / (1 << 23)
+		found = true
+	}
+	for _, x := range []uint16{binary.BigEndian.Uint16(b[15:]), binary.BigEndian.Uint16(b[17:])} {
+		// name code (3 bits), originator (3 bits), sign (1 bit), gain * 10 (9 bits)
+		gain := float64(x&0x1ff) / 10
+		if x&0x200 != 0 {
+			gain = -gain
+		}
+		switch x >> 13 {
+		case 1:
+			g.TrackGain = gain
+			found = true
+		case 2:
+			g.AlbumGain = gain
+			found = true
+		}
+	}
+
+	if !found {
+		return nil
+	}
+	return &g
+}
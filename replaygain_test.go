@@ -0,0 +1,72 @@
+package tag
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestReplayGainFields(t *testing.T) {
+	m := newMetadataVorbis()
+	m.c["replaygain_track_gain"] = "-6.48 dB"
+	m.c["replaygain_track_peak"] = "0.988129"
+	m.c["r128_album_gain"] = "-512"
+
+	want := &ReplayGain{TrackGain: -6.48, TrackPeak: 0.988129, R128AlbumGain: -2}
+	if got := m.ReplayGain(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ReplayGain() = %+v, expected %+v", got, want)
+	}
+
+	if got := newMetadataVorbis().ReplayGain(); got != nil {
+		t.Errorf("ReplayGain() = %+v, expected nil", got)
+	}
+}
+
+func TestID3v2ReplayGain(t *testing.T) {
+	b := id3v2FramesTag(3, id3v23Frame("TXXX", []byte("\x00REPLAYGAIN_ALBUM_GAIN\x00+1.50 dB")))
+	m, err := ReadID3v2Tags(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("ReadID3v2Tags() = %v", err)
+	}
+	if got, want := m.ReplayGain(), (&ReplayGain{AlbumGain: 1.5}); !reflect.DeepEqual(got, want) {
+		t.Errorf("ReplayGain() = %+v, expected %+v", got, want)
+	}
+
+	// RVA2: master volume -3 dB, 16-bit peak of half scale.
+	b = id3v2FramesTag(4, id3v23Frame("RVA2", []byte("track\x00\x01\xfa\x00\x10\x40\x00")))
+	m, err = ReadID3v2Tags(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("ReadID3v2Tags() = %v", err)
+	}
+	if got, want := m.ReplayGain(), (&ReplayGain{TrackGain: -3, TrackPeak: 0.5}); !reflect.DeepEqual(got, want) {
+		t.Errorf("ReplayGain() = %+v, expected %+v", got, want)
+	}
+}
+
+func TestLAMEReplayGain(t *testing.T) {
+	// MPEG-1 Layer III stereo frame with an Info header (frame count flag) and LAME extension.
+	b := make([]byte, 417)
+	copy(b, []byte{0xff, 0xfb, 0x90, 0x00})
+	i := 4 + 32
+	copy(b[i:], "Info\x00\x00\x00\x01")
+	i += 8 + 4
+	copy(b[i:], "LAME3.100")
+	copy(b[i+11:], []byte{0x00, 0x40, 0x00, 0x00}) // peak 0.5
+	copy(b[i+15:], []byte{0x2e, 0x41})             // radio (track) gain -6.5 dB
+	copy(b[i+17:], []byte{0x4c, 0x0a})             // audiophile (album) gain +1.0 dB
+
+	want := &ReplayGain{TrackGain: -6.5, TrackPeak: 0.5, AlbumGain: 1}
+	if got := readLAMEReplayGain(bytes.NewReader(b), 0); !reflect.DeepEqual(got, want) {
+		t.Errorf("readLAMEReplayGain() = %+v, expected %+v", got, want)
+	}
+}
+
+func TestOpusOutputGain(t *testing.T) {
+	m := &metadataOGG{metadataVorbis: newMetadataVorbis()}
+	if err := m.readOpusHead([]byte{1, 2, 0x38, 0x01, 0x80, 0xbb, 0, 0, 0x00, 0x03, 0}); err != nil {
+		t.Fatalf("readOpusHead() = %v", err)
+	}
+	if got, want := m.ReplayGain(), (&ReplayGain{OutputGain: 3}); !reflect.DeepEqual(got, want) {
+		t.Errorf("ReplayGain() = %+v, expected %+v", got, want)
+	}
+}
@@ -2,7 +2,6 @@ package tag
 
 import (
 	"crypto/sha1"
-	"encoding/binary"
 	"errors"
 	"fmt"
 	"hash"
@@ -57,8 +56,7 @@ func SumAll(r io.ReadSeeker) (string, error) {
 // metadata invariant.
 func SumAtoms(r io.ReadSeeker) (string, error) {
 	for {
-		var size uint32
-		err := binary.Read(r, binary.BigEndian, &size)
+		name, size, n, err := readAtomHeader(r)
 		if err != nil {
 			if err == io.EOF {
 				return "", fmt.Errorf("reached EOF before audio data")
@@ -66,11 +64,6 @@ func SumAtoms(r io.ReadSeeker) (string, error) {
 			return "", err
 		}
 
-		name, err := readString(r, 4)
-		if err != nil {
-			return "", err
-		}
-
 		switch name {
 		case "meta":
 			// next_item_id (int32)
@@ -85,14 +78,23 @@ func SumAtoms(r io.ReadSeeker) (string, error) {
 
 		case "mdat": // stop when we get to the data
 			h := sha1.New()
-			_, err := io.CopyN(h, r, int64(size-8))
+			var err error
+			if size == 0 {
+				// mdat extends to the end of the file.
+				_, err = io.Copy(h, r)
+			} else {
+				_, err = io.CopyN(h, r, size-n)
+			}
 			if err != nil {
 				return "", fmt.Errorf("error reading audio data: %v", err)
 			}
 			return hashSum(h), nil
 		}
 
-		_, err = r.Seek(int64(size-8), io.SeekCurrent)
+		if size < n {
+			return "", fmt.Errorf("invalid size %d for '%v' atom", size, name)
+		}
+		_, err = r.Seek(size-n, io.SeekCurrent)
 		if err != nil {
 			return "", fmt.Errorf("error reading '%v' tag: %v", name, err)
 		}
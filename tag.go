@@ -28,8 +28,8 @@ var ErrNoTagsFound = errors.New("no tags found")
 
 // ReadFrom detects and parses audio file metadata tags (currently supports ID3v1,2.{2,3,4}, MP4, FLAC/OGG).
 // Returns non-nil error if the format of the given data could not be determined, or if there was a problem
-// parsing the data.
-func ReadFrom(r io.ReadSeeker) (Metadata, error) {
+// parsing the data.  Parsing can be configured with ReadOptions (see Lenient).
+func ReadFrom(r io.ReadSeeker, opts ...ReadOption) (Metadata, error) {
 	b, err := readBytes(r, 11)
 	if err != nil {
 		return nil, err
@@ -42,33 +42,43 @@ func ReadFrom(r io.ReadSeeker) (Metadata, error) {
 
 	switch {
 	case string(b[0:4]) == "fLaC":
-		return ReadFLACMeta(r)
+		return ReadFLACMeta(r, opts...)
 
 	case string(b[0:4]) == "OggS":
-		return ReadOGGMeta(r)
+		return ReadOGGMeta(r, opts...)
 
 	case string(b[4:8]) == "ftyp":
-		return ReadAtoms(r)
+		return ReadAtoms(r, opts...)
 
 	case string(b[0:3]) == "ID3":
 		size, err := getFileSize(r)
 		if err != nil {
 			return nil, fmt.Errorf("could not get file size: %w", err)
 		}
-		return ReadV2MP3Meta(r, size)
+		return ReadV2MP3Meta(r, size, opts...)
 
 	case b[0] == 0xff && (b[1] == 0xfb || b[2] == 0xf3 || b[3] == 0xf2):
 		size, err := getFileSize(r)
 		if err != nil {
 			return nil, fmt.Errorf("could not get file size: %w", err)
 		}
-		return ReadV1MP3Meta(r, size)
+		m, err := readAppendedV2MP3Meta(r, size, opts)
+		if err == nil {
+			return m, nil
+		}
+		if err != errNoID3v2Footer {
+			// When lenient, a damaged appended tag doesn't hide an ID3v1 tag after it.
+			if err := newReadOptions(opts).warn(err); err != nil {
+				return nil, err
+			}
+		}
+		return ReadV1MP3Meta(r, size, opts...)
 
 	case string(b[0:4]) == "DSD ":
-		return ReadDSFMeta(r)
+		return ReadDSFMeta(r, opts...)
 
 	case string(b[0:4]) == "RIFF":
-		return ReadWAVMeta(r)
+		return ReadWAVMeta(r, opts...)
 	}
 
 	return nil, errors.ErrUnsupported
@@ -106,6 +116,7 @@ const (
 	ID3v2_4       Format = "ID3v2.4" // ID3v2.4 tag format.
 	MP4           Format = "MP4"     // MP4 tag (atom) format (see http://www.ftyps.com/ for a full file type list)
 	VORBIS        Format = "VORBIS"  // Vorbis Comment tag format.
+	APEv2         Format = "APEv2"   // APEv2 tag format.
 )
 
 // FileType is an enumeration of the audio file types supported by this package, in particular
@@ -120,7 +131,7 @@ const (
 	M4A             FileType = "M4A"  // M4A file Apple iTunes (ACC) Audio
 	M4B             FileType = "M4B"  // M4A file Apple iTunes (ACC) Audio Book
 	M4P             FileType = "M4P"  // M4A file Apple iTunes (ACC) AES Protected Audio
-	ALAC            FileType = "ALAC" // Apple Lossless file FIXME: actually detect this
+	ALAC            FileType = "ALAC" // Apple Lossless file (M4A file with ALAC audio)
 	FLAC            FileType = "FLAC" // FLAC file
 	OGG             FileType = "OGG"  // OGG file
 	DSF             FileType = "DSF"  // DSF file DSD Sony format see https://dsd-guide.com/sites/default/files/white-papers/DSFFileFormatSpec_E.pdf
@@ -153,6 +164,13 @@ type Metadata interface {
 	// Year returns the year of the track.
 	Year() int
 
+	// Date returns the (possibly partial) release or recording date of the track.
+	Date() Date
+
+	// OriginalDate returns the (possibly partial) original release date of the track, e.g.
+	// for a reissue.
+	OriginalDate() Date
+
 	// Genre returns the genre of the track.
 	Genre() string
 
@@ -162,18 +180,88 @@ type Metadata interface {
 	// Disc returns the disc number and total discs, or zero values if unavailable.
 	Disc() (int, int)
 
+	// TitleSort, AlbumSort, ArtistSort and AlbumArtistSort return the names used for sorting,
+	// or an empty string if unavailable.
+	TitleSort() string
+	AlbumSort() string
+	ArtistSort() string
+	AlbumArtistSort() string
+
+	// BPM returns the beats per minute of the track, or zero if unavailable.
+	BPM() int
+
+	// Key returns the initial musical key of the track, e.g. "Am".
+	Key() string
+
+	// ISRC returns the International Standard Recording Code of the track.
+	ISRC() string
+
+	// Label returns the record label (or publisher).
+	Label() string
+
+	// CatalogNumber returns the catalog number of the release.
+	CatalogNumber() string
+
+	// Copyright returns the copyright message.
+	Copyright() string
+
+	// Encoder returns the software (and settings) used to encode the track.
+	Encoder() string
+
+	// EncodedBy returns the person or organisation that encoded the track.
+	EncodedBy() string
+
+	// Grouping returns the content group (or work) of the track.
+	Grouping() string
+
+	// Conductor returns the conductor of the track.
+	Conductor() string
+
+	// Lyricist returns the lyricist (text writer) of the track.
+	Lyricist() string
+
+	// Mood returns the mood of the track.
+	Mood() string
+
+	// Compilation reports whether the track is part of a compilation.
+	Compilation() bool
+
 	// Picture returns a picture, or nil if not available.
 	Picture() *Picture
 
 	// Lyrics returns the lyrics, or an empty string if unavailable.
 	Lyrics() string
 
+	// SyncedLyrics returns the timed lines of synchronised lyrics, or nil if unavailable.
+	SyncedLyrics() []LyricLine
+
 	// Comment returns the comment, or an empty string if unavailable.
 	Comment() string
 
+	// Chapters returns the chapters of the file, or nil if there are none.
+	Chapters() []Chapter
+
+	// ReplayGain returns the loudness normalisation data, or nil if unavailable.
+	ReplayGain() *ReplayGain
+
+	// Ratings returns the ratings of the track (normalised to 0–100) along with their
+	// sources, or nil if unavailable.
+	Ratings() []Rating
+
+	// PlayCount returns the play count of the track, or zero if unavailable.
+	PlayCount() int
+
+	// Get returns the text of the canonical field, or the empty string if it is not set or
+	// is not text (e.g. FieldPicture).  Values are as stored in the tag, so FieldTrack may be
+	// "3" or "3/12" depending on the format.
+	Get(f Field) string
+
 	// Raw returns the raw mapping of retrieved tag names and associated values.
-	// NB: tag/atom names are not standardised between formats.
+	// NB: tag/atom names are not standardised between formats, see NativeKeys and FieldFor.
 	Raw() map[string]interface{}
 
+	// Audio returns the codec and format of the audio, or nil if unavailable.
+	Audio() *Audio
+
 	Duration() time.Duration
 }
@@ -5,8 +5,10 @@
 package tag
 
 import (
+	"math"
 	"os"
 	"testing"
+	"time"
 )
 
 type testMetadata struct {
@@ -124,3 +126,67 @@ func testValue(t *testing.T, expected interface{}, found interface{}) {
 		t.Errorf("expected '%v', found '%v'", expected, found)
 	}
 }
+
+func TestAudio(t *testing.T) {
+	tests := map[string]Audio{
+		"with_tags/sample.dsf":        {Codec: CodecDSD, SampleRate: 2822400, Channels: 2, BitDepth: 1, Samples: 4144753},
+		"with_tags/sample.flac":       {Codec: CodecFLAC, SampleRate: 11025, Channels: 1, BitDepth: 16, Samples: 37478},
+		"with_tags/sample.id3v24.mp3": {Codec: CodecMP3, SampleRate: 44100, Channels: 2},
+		"with_tags/sample.m4a":        {Codec: CodecAACLC, SampleRate: 44100, Channels: 2, Samples: 150528},
+		"with_tags/sample.ogg":        {Codec: CodecVorbis, SampleRate: 44100, Channels: 2, Samples: 149880},
+		"without_tags/sample.mp4":     {Codec: CodecAACLC, SampleRate: 48000, Channels: 1, Samples: 267264},
+	}
+	for path, want := range tests {
+		f, err := os.Open("testdata/" + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, err := ReadFrom(f)
+		f.Close()
+		if err != nil {
+			t.Errorf("%v: ReadFrom() = %v", path, err)
+			continue
+		}
+		if got := m.Audio(); got == nil || *got != want {
+			t.Errorf("%v: Audio() = %+v, expected %+v", path, got, want)
+		}
+		if want.Samples > 0 {
+			if got, want := m.Duration(), samplesDuration(uint64(want.Samples), uint32(want.SampleRate)); got != want {
+				t.Errorf("%v: Duration() = %v, expected %v", path, got, want)
+			}
+		}
+	}
+}
+
+func TestSamplesDuration(t *testing.T) {
+	tests := []struct {
+		n    uint64
+		rate uint32
+		want time.Duration
+	}{
+		{0, 44100, 0},
+		{44100, 0, 0},
+		{37478, 11025, 3399365079},
+		{150000, 48000, 3125 * time.Millisecond},
+		{math.MaxUint64, 1, math.MaxInt64},
+	}
+	for _, tt := range tests {
+		if got := samplesDuration(tt.n, tt.rate); got != tt.want {
+			t.Errorf("samplesDuration(%v, %v) = %v, expected %v", tt.n, tt.rate, got, tt.want)
+		}
+	}
+}
+
+func TestMP3ReservedSampleRate(t *testing.T) {
+	// MPEG1 layer 3 with the reserved sample rate index.
+	header := []byte{0xff, 0xfb, 0x9c, 0x00}
+	if d, err := getMP3Duration(header, 1000); err != nil || d != 0 {
+		t.Errorf("getMP3Duration() = %v, %v, expected 0, nil", d, err)
+	}
+	if d := getMP3FrameDuration(header); d != 0 {
+		t.Errorf("getMP3FrameDuration() = %v, expected 0", d)
+	}
+	if a := getMP3Audio(header); a != nil {
+		t.Errorf("getMP3Audio() = %v, expected nil", a)
+	}
+}
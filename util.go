@@ -9,6 +9,10 @@ import (
 	"encoding/binary"
 	"errors"
 	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
 )
 
 func getBit(b byte, n uint) bool {
@@ -151,3 +155,31 @@ func readUint16LittleEndian(r io.Reader) (uint16, error) {
 	}
 	return uint16(b[0]) | uint16(b[1])<<8, nil
 }
+
+// parseBPM parses a BPM value, which may have a fractional part.
+func parseBPM(s string) int {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || f < 0 {
+		return 0
+	}
+	return int(math.Round(f))
+}
+
+// parseBool parses a flag such as the compilation flag ("1", "true" or "yes").
+func parseBool(s string) bool {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "1", "true", "yes":
+		return true
+	}
+	return false
+}
+
+// sortedKeys returns the keys of m in sorted order.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -7,9 +7,9 @@ package tag
 import (
 	"bytes"
 	"encoding/base64"
-	"errors"
 	"fmt"
 	"io"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -22,11 +22,14 @@ func newMetadataVorbis() *metadataVorbis {
 }
 
 type metadataVorbis struct {
-	c map[string]string // the vorbis comments
-	p *Picture
+	c     map[string]string   // the vorbis comments
+	multi map[string][]string // all values of comments given more than once
+	p     *Picture
 }
 
-func (m *metadataVorbis) readVorbisComment(r io.Reader) error {
+// readVorbisComment reads a Vorbis comment header.  Comments which cannot be parsed are
+// passed to warn, and skipped if it returns nil.
+func (m *metadataVorbis) readVorbisComment(r io.Reader, warn func(error) error) error {
 	vendorLen, err := readUint32LittleEndian(r)
 	if err != nil {
 		return err
@@ -54,9 +57,22 @@ func (m *metadataVorbis) readVorbisComment(r io.Reader) error {
 		}
 		k, v, err := parseComment(s)
 		if err != nil {
-			return err
+			if err := warn(err); err != nil {
+				return err
+			}
+			continue
+		}
+		k = strings.ToLower(k)
+		if prev, ok := m.c[k]; ok {
+			if m.multi == nil {
+				m.multi = make(map[string][]string)
+			}
+			if len(m.multi[k]) == 0 {
+				m.multi[k] = []string{prev}
+			}
+			m.multi[k] = append(m.multi[k], v)
 		}
-		m.c[strings.ToLower(k)] = v
+		m.c[k] = v
 	}
 
 	if b64data, ok := m.c["metadata_block_picture"]; ok {
@@ -77,7 +93,7 @@ func (m *metadataVorbis) readPictureBlock(r io.Reader) error {
 	}
 	pictureType, ok := pictureTypes[byte(b)]
 	if !ok {
-		return fmt.Errorf("invalid picture type: %v", b)
+		return fmt.Errorf("%w: invalid picture type: %v", ErrMalformed, b)
 	}
 	mimeLen, err := readUint(r, 4)
 	if err != nil {
@@ -148,7 +164,7 @@ func (m *metadataVorbis) readPictureBlock(r io.Reader) error {
 func parseComment(c string) (k, v string, err error) {
 	kv := strings.SplitN(c, "=", 2)
 	if len(kv) != 2 {
-		err = errors.New("vorbis comment must contain '='")
+		err = fmt.Errorf("%w: vorbis comment must contain '='", ErrMalformed)
 		return
 	}
 	k = kv[0]
@@ -168,6 +184,9 @@ func (m *metadataVorbis) Raw() map[string]interface{} {
 	return raw
 }
 
+// rawValues returns each value of the comment k, if it was given more than once.
+func (m *metadataVorbis) rawValues(k string) []string { return m.multi[k] }
+
 func (m *metadataVorbis) Title() string {
 	return m.c["title"]
 }
@@ -236,6 +255,50 @@ func (m *metadataVorbis) Year() int {
 	return t.Year()
 }
 
+// Date returns the date given by the DATE (or YEAR) comment.
+func (m *metadataVorbis) Date() Date {
+	if d := parseDate(m.c["date"]); !d.IsZero() {
+		return d
+	}
+	return parseDate(m.c["year"])
+}
+
+// OriginalDate returns the date given by the ORIGINALDATE (or ORIGINALYEAR) comment.
+func (m *metadataVorbis) OriginalDate() Date {
+	if d := parseDate(m.c["originaldate"]); !d.IsZero() {
+		return d
+	}
+	return parseDate(m.c["originalyear"])
+}
+
+// Get returns the first non-empty comment given for the field.
+func (m *metadataVorbis) Get(f Field) string {
+	for _, n := range fieldKeys[f][VORBIS] {
+		if s := m.c[n]; s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+func (m *metadataVorbis) TitleSort() string       { return m.Get(FieldTitleSort) }
+func (m *metadataVorbis) AlbumSort() string       { return m.Get(FieldAlbumSort) }
+func (m *metadataVorbis) ArtistSort() string      { return m.Get(FieldArtistSort) }
+func (m *metadataVorbis) AlbumArtistSort() string { return m.Get(FieldAlbumArtistSort) }
+func (m *metadataVorbis) BPM() int                { return parseBPM(m.Get(FieldBPM)) }
+func (m *metadataVorbis) Key() string             { return m.Get(FieldKey) }
+func (m *metadataVorbis) ISRC() string            { return m.Get(FieldISRC) }
+func (m *metadataVorbis) Label() string           { return m.Get(FieldLabel) }
+func (m *metadataVorbis) CatalogNumber() string   { return m.Get(FieldCatalogNumber) }
+func (m *metadataVorbis) Copyright() string       { return m.Get(FieldCopyright) }
+func (m *metadataVorbis) Encoder() string         { return m.Get(FieldEncoder) }
+func (m *metadataVorbis) EncodedBy() string       { return m.Get(FieldEncodedBy) }
+func (m *metadataVorbis) Grouping() string        { return m.Get(FieldGrouping) }
+func (m *metadataVorbis) Conductor() string       { return m.Get(FieldConductor) }
+func (m *metadataVorbis) Lyricist() string        { return m.Get(FieldLyricist) }
+func (m *metadataVorbis) Mood() string            { return m.Get(FieldMood) }
+func (m *metadataVorbis) Compilation() bool       { return parseBool(m.Get(FieldCompilation)) }
+
 func (m *metadataVorbis) Track() (int, int) {
 	x, _ := strconv.Atoi(m.c["tracknumber"])
 	// https://wiki.xiph.org/Field_names
@@ -264,3 +327,67 @@ func (m *metadataVorbis) Comment() string {
 func (m *metadataVorbis) Picture() *Picture {
 	return m.p
 }
+
+// Chapters returns the chapters given by CHAPTERxxx, CHAPTERxxxNAME and CHAPTERxxxURL
+// comments (see https://wiki.xiph.org/Chapter_Extension).
+func (m *metadataVorbis) Chapters() []Chapter {
+	var chapters []Chapter
+	for k, v := range m.c {
+		n := strings.TrimPrefix(k, "chapter")
+		if n == k || n == "" || strings.Trim(n, "0123456789") != "" {
+			continue
+		}
+		start, ok := parseClockTime(v)
+		if !ok {
+			continue
+		}
+		chapters = append(chapters, Chapter{
+			Start: start,
+			Title: m.c[k+"name"],
+			URL:   m.c[k+"url"],
+		})
+	}
+	fillChapterEnds(chapters, 0)
+	return chapters
+}
+
+// SyncedLyrics returns the lyrics in the SYNCEDLYRICS comment, or the LYRICS comment if it
+// is in the LRC format.
+func (m *metadataVorbis) SyncedLyrics() []LyricLine {
+	if l := parseLRC(m.c["syncedlyrics"]); l != nil {
+		return l
+	}
+	return parseLRC(m.c["lyrics"])
+}
+
+// ReplayGain returns the gain given by REPLAYGAIN_* and R128_* comments.
+func (m *metadataVorbis) ReplayGain() *ReplayGain {
+	return replayGainFromFields(func(name string) string { return m.c[name] })
+}
+
+// Ratings returns the ratings given by the RATING and FMPS_RATING comments, and per-user
+// RATING:<email> comments.
+func (m *metadataVorbis) Ratings() []Rating {
+	ratings := ratingsFromFields(func(name string) string { return m.c[name] })
+	var users []Rating
+	for k, v := range m.c {
+		if user, ok := strings.CutPrefix(k, "rating:"); ok {
+			if r, ok := parseRating(v); ok {
+				users = append(users, Rating{Value: r, Source: "RATING:" + user})
+			}
+		}
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Source < users[j].Source
+	})
+	return append(ratings, users...)
+}
+
+// PlayCount returns the play count given by the PLAYCOUNT or FMPS_PLAYCOUNT comments.
+func (m *metadataVorbis) PlayCount() int {
+	return playCountFromFields(func(name string) string { return m.c[name] })
+}
+
+func (m *metadataVorbis) Audio() *Audio {
+	return nil
+}
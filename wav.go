@@ -1,6 +1,7 @@
 package tag
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"time"
@@ -8,74 +9,90 @@ import (
 
 // ReadWAVMeta reads WAV metadata from the io.ReadSeeker, returning the resulting
 // metadata in a Metadata implementation, or non-nil error if there was a problem.
-func ReadWAVMeta(r io.ReadSeeker) (Metadata, error) {
+func ReadWAVMeta(r io.ReadSeeker, opts ...ReadOption) (Metadata, error) {
+	o := newReadOptions(opts)
+	offset := tell(r)
+	var chunkID string
+	chunkError := func(err error) error {
+		return newParseError(string(WAV), offset, chunkID, err)
+	}
+
 	// verify RIFF chunk
 	str, err := readString(r, 4)
 	if err != nil {
-		return nil, err
+		return nil, chunkError(err)
 	}
 	if str != "RIFF" {
-		return nil, fmt.Errorf("chunk header %v does not match expected 'RIFF'", str)
+		return nil, chunkError(fmt.Errorf("%w: chunk header %v does not match expected 'RIFF'", ErrMalformed, str))
 	}
 
 	// skip file size (4 bytes)
 	_, err = r.Seek(4, io.SeekCurrent)
 	if err != nil {
-		return nil, err
+		return nil, chunkError(err)
 	}
 
 	// verify WAVE filetype
 	str, err = readString(r, 4)
 	if err != nil {
-		return nil, err
+		return nil, chunkError(err)
 	}
 	if str != "WAVE" {
-		return nil, fmt.Errorf("filetype %v does not match expected 'WAVE'", str)
+		return nil, chunkError(fmt.Errorf("%w: filetype %v does not match expected 'WAVE'", ErrMalformed, str))
 	}
 
 	m := &metadataWAV{}
 
-	// Parse chunks to find fmt and data chunks
+	// Parse chunks to find fmt and data chunks.  When lenient, a chunk which cannot be read
+	// ends the read.
+	fail := func(err error) (Metadata, error) {
+		if err := o.warn(chunkError(err)); err != nil {
+			return nil, err
+		}
+		return m, nil
+	}
 	for {
-		chunkID, err := readString(r, 4)
+		offset = tell(r)
+		chunkID, err = readString(r, 4)
 		if err != nil {
 			if err == io.EOF {
 				break
 			}
-			return nil, err
+			return fail(err)
 		}
 
 		chunkSize, err := readUint32LittleEndian(r)
 		if err != nil {
-			return nil, err
+			return fail(err)
 		}
 
 		switch chunkID {
 		case "fmt ":
 			err = m.readFmtChunk(r, chunkSize)
 			if err != nil {
-				return nil, err
+				// An unsupported audio format need not end the read.
+				if err := o.warn(chunkError(err)); err != nil {
+					return nil, err
+				}
 			}
 		case "data":
 			m.dataSize = chunkSize
 			// Calculate duration now that we have both fmt and data info
 			if m.sampleRate > 0 && m.bitsPerSample > 0 && m.channels > 0 {
 				bytesPerSample := (m.bitsPerSample + 7) / 8 // Round up to nearest byte
-				bytesPerSecond := m.sampleRate * uint32(m.channels) * uint32(bytesPerSample)
-				if bytesPerSecond > 0 {
-					m.duration = time.Duration(m.dataSize) * time.Second / time.Duration(bytesPerSecond)
-				}
+				m.samples = m.dataSize / (uint32(m.channels) * uint32(bytesPerSample))
+				m.duration = samplesDuration(uint64(m.samples), m.sampleRate)
 			}
 			// Skip the data chunk content
 			_, err = r.Seek(int64(chunkSize), io.SeekCurrent)
 			if err != nil {
-				return nil, err
+				return fail(err)
 			}
 		default:
 			// Skip unknown chunks
 			_, err = r.Seek(int64(chunkSize), io.SeekCurrent)
 			if err != nil {
-				return nil, err
+				return fail(err)
 			}
 		}
 
@@ -83,7 +100,7 @@ func ReadWAVMeta(r io.ReadSeeker) (Metadata, error) {
 		if chunkSize%2 == 1 {
 			_, err = r.Seek(1, io.SeekCurrent)
 			if err != nil {
-				return nil, err
+				return fail(err)
 			}
 		}
 	}
@@ -92,10 +109,12 @@ func ReadWAVMeta(r io.ReadSeeker) (Metadata, error) {
 }
 
 type metadataWAV struct {
+	audioFormat    uint16
 	sampleRate     uint32
 	bitsPerSample  uint16
 	channels       uint16
 	dataSize       uint32
+	samples        uint32 // Samples per channel in the data chunk
 	duration       time.Duration
 }
 
@@ -105,6 +124,7 @@ func (m *metadataWAV) readFmtChunk(r io.ReadSeeker, chunkSize uint32) error {
 	if err != nil {
 		return err
 	}
+	m.audioFormat = audioFormat
 	
 	// Read number of channels (2 bytes)
 	m.channels, err = readUint16LittleEndian(r)
@@ -141,7 +161,7 @@ func (m *metadataWAV) readFmtChunk(r io.ReadSeeker, chunkSize uint32) error {
 
 	// Basic validation
 	if audioFormat != 1 {
-		return fmt.Errorf("unsupported audio format: %d (only PCM format 1 is supported)", audioFormat)
+		return fmt.Errorf("%w: audio format %d (only PCM format 1 is supported)", errors.ErrUnsupported, audioFormat)
 	}
 
 	return nil
@@ -191,6 +211,32 @@ func (m *metadataWAV) Disc() (int, int) {
 	return 0, 0
 }
 
+func (m *metadataWAV) Date() Date                { return Date{} }
+func (m *metadataWAV) OriginalDate() Date        { return Date{} }
+func (m *metadataWAV) TitleSort() string         { return "" }
+func (m *metadataWAV) AlbumSort() string         { return "" }
+func (m *metadataWAV) ArtistSort() string        { return "" }
+func (m *metadataWAV) AlbumArtistSort() string   { return "" }
+func (m *metadataWAV) BPM() int                  { return 0 }
+func (m *metadataWAV) Key() string               { return "" }
+func (m *metadataWAV) ISRC() string              { return "" }
+func (m *metadataWAV) Label() string             { return "" }
+func (m *metadataWAV) CatalogNumber() string     { return "" }
+func (m *metadataWAV) Copyright() string         { return "" }
+func (m *metadataWAV) Encoder() string           { return "" }
+func (m *metadataWAV) EncodedBy() string         { return "" }
+func (m *metadataWAV) Grouping() string          { return "" }
+func (m *metadataWAV) Conductor() string         { return "" }
+func (m *metadataWAV) Lyricist() string          { return "" }
+func (m *metadataWAV) Mood() string              { return "" }
+func (m *metadataWAV) Get(f Field) string        { return "" }
+func (m *metadataWAV) Compilation() bool         { return false }
+func (m *metadataWAV) Chapters() []Chapter       { return nil }
+func (m *metadataWAV) SyncedLyrics() []LyricLine { return nil }
+func (m *metadataWAV) ReplayGain() *ReplayGain   { return nil }
+func (m *metadataWAV) Ratings() []Rating         { return nil }
+func (m *metadataWAV) PlayCount() int            { return 0 }
+
 func (m *metadataWAV) Picture() *Picture {
 	return nil
 }
@@ -212,6 +258,20 @@ func (m *metadataWAV) Raw() map[string]interface{} {
 	}
 }
 
+// Audio returns the format given by the fmt chunk, if it is PCM.
+func (m *metadataWAV) Audio() *Audio {
+	if m.audioFormat != 1 {
+		return nil
+	}
+	return &Audio{
+		Codec:      CodecPCM,
+		SampleRate: int(m.sampleRate),
+		Channels:   int(m.channels),
+		BitDepth:   int(m.bitsPerSample),
+		Samples:    int64(m.samples),
+	}
+}
+
 func (m *metadataWAV) Duration() time.Duration {
 	return m.duration
 }